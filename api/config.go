@@ -0,0 +1,75 @@
+package api
+
+import "net/url"
+
+var DefaultConfig = NewConfig("")
+
+// Config is the global configuration for the database connection
+// and the migration pipeline.
+type Config struct {
+	ConnectionString string
+
+	// ReadReplicaConnectionString, when set, is a read-only replica that
+	// SELECT-heavy helpers route through (see context.Context.ReadDB).
+	ReadReplicaConnectionString string
+
+	Schema string
+
+	// Role is the database role migration scripts grant to (available
+	// to scripts as {{.Role}}).
+	Role string
+
+	Postgrest  PostgrestConfig
+	LogName    string
+	LogLevel   string
+	Migrations MigrationMode
+}
+
+type MigrationMode struct {
+	// Skip disables migrations entirely.
+	Skip bool
+
+	// DryRun logs the scripts that would run without executing them;
+	// see also migrate.Plan for a structured view.
+	DryRun bool
+
+	// IgnoreFiles is a list of migration scripts to skip; entries are
+	// glob patterns matched against both the script path and its base
+	// name (e.g. "views_*.sql").
+	IgnoreFiles []string
+
+	// Profile names a predefined skip list (see migrate.Profiles), e.g.
+	// "agent" for slim deployments that don't need UI-only views.
+	Profile string
+
+	// Only restricts migrations to the named script groups (e.g.
+	// "views", "functions", "schema"), so lightweight services don't pay
+	// the full migration cost at startup. Empty means all groups. A
+	// script's groups are its directory and its base-name prefix.
+	Only []string
+}
+
+type PostgrestConfig struct {
+	URL     string
+	Port    int
+	JWTKey  string
+	Version string
+}
+
+func NewConfig(connection string) Config {
+	return Config{
+		ConnectionString: connection,
+		Schema:           "public",
+		LogName:          "db",
+	}
+}
+
+// String returns the connection string with the password redacted,
+// safe for logging.
+func (c Config) String() string {
+	parsed, err := url.Parse(c.ConnectionString)
+	if err != nil {
+		return "invalid connection string"
+	}
+	return parsed.Redacted()
+}
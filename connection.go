@@ -0,0 +1,120 @@
+package duty
+
+import (
+	gocontext "context"
+	"database/sql"
+	"fmt"
+	"sync"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/jackc/pgx/v5/stdlib"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+
+	"github.com/flanksource/commons-db/types"
+)
+
+// ConnectionSpec is a connection whose credentials are EnvVars instead
+// of inline strings, so the password can come from a Kubernetes secret
+// or an external resolver. Credentials are re-resolved every time a new
+// physical connection is opened, which picks up secret rotation without
+// a restart: rotated credentials take effect as pooled connections are
+// recycled.
+type ConnectionSpec struct {
+	// URL is the connection string without credentials,
+	// e.g. postgres://db:5432/duty?sslmode=require.
+	URL string `json:"url"`
+
+	Username types.EnvVar `json:"username,omitempty"`
+	Password types.EnvVar `json:"password,omitempty"`
+}
+
+// EnvVarResolver resolves an EnvVar's external reference (secret,
+// configmap, ...) to its value. The kubernetes package provides one;
+// the default resolver handles only static values.
+type EnvVarResolver func(ctx gocontext.Context, e types.EnvVar) (string, error)
+
+var (
+	envVarResolverMu sync.RWMutex
+	envVarResolver   EnvVarResolver = func(_ gocontext.Context, e types.EnvVar) (string, error) {
+		if e.ValueFrom.IsEmpty() {
+			return e.ValueStatic, nil
+		}
+		return "", fmt.Errorf("no EnvVar resolver registered to resolve %s", e.String())
+	}
+)
+
+// SetEnvVarResolver registers the resolver used for EnvVar-based
+// connection credentials.
+func SetEnvVarResolver(resolver EnvVarResolver) {
+	envVarResolverMu.Lock()
+	defer envVarResolverMu.Unlock()
+	envVarResolver = resolver
+}
+
+func resolveEnvVar(ctx gocontext.Context, e types.EnvVar) (string, error) {
+	envVarResolverMu.RLock()
+	resolver := envVarResolver
+	envVarResolverMu.RUnlock()
+	return resolver(ctx, e)
+}
+
+// InitDBFromSpec is InitDB for EnvVar-based connections: both the pool
+// and the gorm handle re-resolve credentials on every new physical
+// connection.
+func InitDBFromSpec(ctx gocontext.Context, spec ConnectionSpec) (*gorm.DB, *pgxpool.Pool, error) {
+	if err := spec.Username.Validate(); err != nil {
+		return nil, nil, err
+	}
+	if err := spec.Password.Validate(); err != nil {
+		return nil, nil, err
+	}
+
+	poolConfig, err := pgxpool.ParseConfig(spec.URL)
+	if err != nil {
+		return nil, nil, fmt.Errorf("invalid connection string: %w", err)
+	}
+	poolConfig.BeforeConnect = spec.injectCredentials
+
+	pool, err := pgxpool.NewWithConfig(ctx, poolConfig)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to create connection pool: %w", err)
+	}
+
+	sqlDB := stdlib.OpenDB(*poolConfig.ConnConfig, stdlib.OptionBeforeConnect(spec.injectCredentials))
+	db, err := openGormOver(sqlDB)
+	if err != nil {
+		pool.Close()
+		return nil, nil, err
+	}
+	return db, pool, nil
+}
+
+// injectCredentials resolves the spec's EnvVars into the connection
+// config; called by pgx before each new physical connection.
+func (spec ConnectionSpec) injectCredentials(ctx gocontext.Context, config *pgx.ConnConfig) error {
+	if !spec.Username.IsEmpty() {
+		username, err := resolveEnvVar(ctx, spec.Username)
+		if err != nil {
+			return fmt.Errorf("failed to resolve database username: %w", err)
+		}
+		config.User = username
+	}
+	if !spec.Password.IsEmpty() {
+		password, err := resolveEnvVar(ctx, spec.Password)
+		if err != nil {
+			return fmt.Errorf("failed to resolve database password: %w", err)
+		}
+		config.Password = password
+	}
+	return nil
+}
+
+func openGormOver(sqlDB *sql.DB) (*gorm.DB, error) {
+	db, err := gorm.Open(postgres.New(postgres.Config{Conn: sqlDB}), DefaultGormConfig())
+	if err != nil {
+		return nil, fmt.Errorf("failed to open gorm connection: %w", err)
+	}
+	return db, nil
+}
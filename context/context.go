@@ -0,0 +1,137 @@
+package context
+
+import (
+	gocontext "context"
+	"time"
+
+	"github.com/flanksource/commons/logger"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"go.opentelemetry.io/otel/trace"
+	"gorm.io/gorm"
+)
+
+// Context is a request-scoped context that carries the database handles,
+// logger and properties through every layer of this library.
+//
+// It satisfies context.Context so it can be passed anywhere a standard
+// context is expected. All With* methods return a shallow copy, the
+// receiver is never mutated.
+type Context struct {
+	gocontext.Context
+
+	db     *gorm.DB
+	readDB *gorm.DB
+	pool   *pgxpool.Pool
+	logger logger.Logger
+	tracer trace.Tracer
+}
+
+// New creates a fresh Context from a parent go context.
+func New(opts ...ContextOption) Context {
+	ctx := Context{
+		Context: gocontext.Background(),
+		logger:  logger.StandardLogger(),
+	}
+	for _, opt := range opts {
+		ctx = opt(ctx)
+	}
+	return ctx
+}
+
+type ContextOption func(Context) Context
+
+func WithLogger(log logger.Logger) ContextOption {
+	return func(ctx Context) Context {
+		ctx.logger = log
+		return ctx
+	}
+}
+
+// NewContext wraps an existing go context.
+func NewContext(baseCtx gocontext.Context, opts ...ContextOption) Context {
+	ctx := New(opts...)
+	ctx.Context = baseCtx
+	return ctx
+}
+
+func (k Context) DB() *gorm.DB {
+	if k.db == nil {
+		return nil
+	}
+	return k.db.WithContext(k)
+}
+
+func (k Context) Pool() *pgxpool.Pool {
+	return k.pool
+}
+
+func (k Context) Logger() logger.Logger {
+	return k.logger
+}
+
+// ReadDB returns the read-replica connection when one is configured,
+// falling back to the primary. SELECT-heavy helpers route through this
+// so replicas absorb query load while writes stay on the primary.
+func (k Context) ReadDB() *gorm.DB {
+	if k.readDB != nil {
+		return k.readDB.WithContext(k)
+	}
+	return k.DB()
+}
+
+func (k Context) WithDB(db *gorm.DB, pool *pgxpool.Pool) Context {
+	k.db = db
+	k.pool = pool
+	return k
+}
+
+// WithReadDB attaches a read-only replica connection used by ReadDB.
+func (k Context) WithReadDB(db *gorm.DB) Context {
+	k.readDB = db
+	return k
+}
+
+func (k Context) WithValue(key, val any) Context {
+	k.Context = gocontext.WithValue(k.Context, key, val)
+	return k
+}
+
+func (k Context) WithTimeout(timeout time.Duration) (Context, gocontext.CancelFunc) {
+	ctx, cancel := gocontext.WithTimeout(k.Context, timeout)
+	k.Context = ctx
+	return k, cancel
+}
+
+func (k Context) WithDeadline(deadline time.Time) (Context, gocontext.CancelFunc) {
+	ctx, cancel := gocontext.WithDeadline(k.Context, deadline)
+	k.Context = ctx
+	return k, cancel
+}
+
+// Wrap carries over the db, pool and logger from this context onto a new
+// base go context. It is used when a fresh context (e.g. detached from a
+// request) needs the same wiring.
+func (k Context) Wrap(ctx gocontext.Context) Context {
+	k.Context = ctx
+	return k
+}
+
+func (k Context) Debugf(format string, args ...any) {
+	k.logger.Debugf(format, args...)
+}
+
+func (k Context) Infof(format string, args ...any) {
+	k.logger.Infof(format, args...)
+}
+
+func (k Context) Warnf(format string, args ...any) {
+	k.logger.Warnf(format, args...)
+}
+
+func (k Context) Errorf(format string, args ...any) {
+	k.logger.Errorf(format, args...)
+}
+
+func (k Context) Tracef(format string, args ...any) {
+	k.logger.Tracef(format, args...)
+}
@@ -0,0 +1,20 @@
+package context
+
+type freshnessKey struct{}
+
+// RequireFreshness marks the context with a consistency token (captured
+// after a write, see db.CaptureConsistencyToken) so that subsequent
+// reads are routed to the primary — or wait for replica catch-up —
+// instead of reading stale replica data.
+func (k Context) RequireFreshness(token string) Context {
+	return k.WithValue(freshnessKey{}, token)
+}
+
+// FreshnessToken returns the consistency token set by RequireFreshness,
+// or "" when the context has no freshness requirement.
+func (k Context) FreshnessToken() string {
+	if token, ok := k.Value(freshnessKey{}).(string); ok {
+		return token
+	}
+	return ""
+}
@@ -0,0 +1,160 @@
+package context
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/clientcmd"
+)
+
+// KubernetesOptions selects which cluster/credentials LocalKubernetes
+// resolves.
+type KubernetesOptions struct {
+	// Kubeconfig is the path to a kubeconfig file; $KUBECONFIG or
+	// ~/.kube/config when empty.
+	Kubeconfig string
+
+	// Context selects a kubeconfig context by name; the current
+	// context when empty.
+	Context string
+
+	// InCluster forces in-cluster config (service account token)
+	// instead of a kubeconfig.
+	InCluster bool
+}
+
+// KubernetesFactory builds a clientset from options. Tests can swap it
+// via SetKubernetesFactory to inject a fake clientset.
+type KubernetesFactory func(KubernetesOptions) (kubernetes.Interface, *rest.Config, error)
+
+var (
+	kubernetesFactoryMu sync.RWMutex
+	kubernetesFactory   KubernetesFactory = defaultKubernetesFactory
+)
+
+// SetKubernetesFactory replaces the clientset factory (pass nil to
+// restore the default). Intended for tests.
+func SetKubernetesFactory(factory KubernetesFactory) {
+	kubernetesFactoryMu.Lock()
+	defer kubernetesFactoryMu.Unlock()
+	if factory == nil {
+		factory = defaultKubernetesFactory
+	}
+	kubernetesFactory = factory
+}
+
+// cached clients, keyed by kubeconfig path + context; invalidated when
+// the kubeconfig file changes on disk
+type kubernetesClientEntry struct {
+	client   kubernetes.Interface
+	config   *rest.Config
+	modTime  time.Time
+	resolved time.Time
+}
+
+var (
+	kubernetesClientsMu sync.Mutex
+	kubernetesClients   = map[string]*kubernetesClientEntry{}
+)
+
+// LocalKubernetes returns a clientset for the local environment:
+// in-cluster when requested (or when no kubeconfig exists inside a
+// pod), otherwise from the kubeconfig, lazily re-resolving when the
+// file changes so long-running processes pick up rotated credentials.
+func (k Context) LocalKubernetes(opts ...KubernetesOptions) (kubernetes.Interface, *rest.Config, error) {
+	var options KubernetesOptions
+	if len(opts) > 0 {
+		options = opts[0]
+	}
+
+	kubernetesFactoryMu.RLock()
+	factory := kubernetesFactory
+	kubernetesFactoryMu.RUnlock()
+
+	if options.InCluster {
+		return factory(options)
+	}
+
+	path := kubeconfigPath(options.Kubeconfig)
+	cacheKey := path + "::" + options.Context
+
+	kubernetesClientsMu.Lock()
+	defer kubernetesClientsMu.Unlock()
+
+	modTime := fileModTime(path)
+	if entry, ok := kubernetesClients[cacheKey]; ok && entry.modTime.Equal(modTime) {
+		return entry.client, entry.config, nil
+	}
+
+	client, config, err := factory(options)
+	if err != nil {
+		return nil, nil, err
+	}
+	kubernetesClients[cacheKey] = &kubernetesClientEntry{
+		client:   client,
+		config:   config,
+		modTime:  modTime,
+		resolved: time.Now(),
+	}
+	return client, config, nil
+}
+
+func defaultKubernetesFactory(options KubernetesOptions) (kubernetes.Interface, *rest.Config, error) {
+	var config *rest.Config
+	var err error
+
+	if options.InCluster {
+		if config, err = rest.InClusterConfig(); err != nil {
+			return nil, nil, fmt.Errorf("failed to load in-cluster config: %w", err)
+		}
+	} else {
+		loadingRules := clientcmd.NewDefaultClientConfigLoadingRules()
+		if options.Kubeconfig != "" {
+			loadingRules.ExplicitPath = options.Kubeconfig
+		}
+		overrides := &clientcmd.ConfigOverrides{CurrentContext: options.Context}
+
+		config, err = clientcmd.NewNonInteractiveDeferredLoadingClientConfig(loadingRules, overrides).ClientConfig()
+		if err != nil {
+			// fall back to in-cluster inside a pod without kubeconfig
+			if inCluster, inErr := rest.InClusterConfig(); inErr == nil {
+				config = inCluster
+			} else {
+				return nil, nil, fmt.Errorf("failed to load kubeconfig: %w", err)
+			}
+		}
+	}
+
+	client, err := kubernetes.NewForConfig(config)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to create clientset: %w", err)
+	}
+	return client, config, nil
+}
+
+func kubeconfigPath(explicit string) string {
+	if explicit != "" {
+		return explicit
+	}
+	if env := os.Getenv("KUBECONFIG"); env != "" {
+		return env
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(home, ".kube", "config")
+}
+
+func fileModTime(path string) time.Time {
+	info, err := os.Stat(path)
+	if err != nil {
+		return time.Time{}
+	}
+	return info.ModTime()
+}
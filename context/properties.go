@@ -0,0 +1,109 @@
+package context
+
+import (
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Properties are runtime tunables, typically loaded from a properties file
+// or the properties table, that control optional behavior without code
+// changes. Lookup falls back to the global property registry when a key is
+// not set on the context.
+type Properties map[string]string
+
+var (
+	globalPropertiesMu sync.RWMutex
+	globalProperties   = Properties{}
+)
+
+// UpdateProperties merges the given properties into the global registry.
+func UpdateProperties(props map[string]string) {
+	globalPropertiesMu.Lock()
+	defer globalPropertiesMu.Unlock()
+	for k, v := range props {
+		globalProperties[k] = v
+	}
+}
+
+// UpdateProperty sets a single global property.
+func UpdateProperty(key, value string) {
+	globalPropertiesMu.Lock()
+	defer globalPropertiesMu.Unlock()
+	globalProperties[key] = value
+}
+
+// GlobalProperties returns a snapshot of the global property registry,
+// for call sites that don't have a Context (e.g. gorm plugins).
+func GlobalProperties() Properties {
+	globalPropertiesMu.RLock()
+	defer globalPropertiesMu.RUnlock()
+
+	props := make(Properties, len(globalProperties))
+	for key, val := range globalProperties {
+		props[key] = val
+	}
+	return props
+}
+
+func (k Context) Properties() Properties {
+	return GlobalProperties()
+}
+
+func (p Properties) String(key, def string) string {
+	if val, ok := p[key]; ok {
+		return val
+	}
+	return def
+}
+
+// On returns true if any of the given keys is set to a truthy value,
+// or def when none of the keys are set.
+func (p Properties) On(def bool, keys ...string) bool {
+	for _, key := range keys {
+		if val, ok := p[key]; ok {
+			return val == "true" || val == "enabled" || val == "on"
+		}
+	}
+	return def
+}
+
+func (p Properties) Int(key string, def int) int {
+	if val, ok := p[key]; ok {
+		if i, err := strconv.Atoi(val); err == nil {
+			return i
+		}
+	}
+	return def
+}
+
+func (p Properties) Float(key string, def float64) float64 {
+	if val, ok := p[key]; ok {
+		if f, err := strconv.ParseFloat(val, 64); err == nil {
+			return f
+		}
+	}
+	return def
+}
+
+func (p Properties) Duration(key string, def time.Duration) time.Duration {
+	if val, ok := p[key]; ok {
+		if d, err := time.ParseDuration(val); err == nil {
+			return d
+		}
+	}
+	return def
+}
+
+// List parses a comma-separated property value.
+func (p Properties) List(key string, def []string) []string {
+	if val, ok := p[key]; ok {
+		items := strings.Split(val, ",")
+		for i := range items {
+			items[i] = strings.TrimSpace(items[i])
+		}
+		return items
+	}
+	return def
+}
@@ -0,0 +1,46 @@
+package context
+
+import (
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+	"go.opentelemetry.io/otel/trace/noop"
+)
+
+const tracerName = "commons-db"
+
+// WithTracer sets the tracer used by StartSpan. Without it, spans are
+// created from the global otel tracer provider (a no-op unless the host
+// application configured one).
+func WithTracer(tracer trace.Tracer) ContextOption {
+	return func(ctx Context) Context {
+		ctx.tracer = tracer
+		return ctx
+	}
+}
+
+func (k Context) tracerOrDefault() trace.Tracer {
+	if k.tracer != nil {
+		return k.tracer
+	}
+	if provider := otel.GetTracerProvider(); provider != nil {
+		return provider.Tracer(tracerName)
+	}
+	return noop.NewTracerProvider().Tracer(tracerName)
+}
+
+// StartSpan starts a child span and returns a context carrying it.
+// Intended for the internal seams of this library (secret resolution,
+// selector SQL, Kubernetes API calls) so a single trace shows the whole
+// request path:
+//
+//	ctx, span := ctx.StartSpan("query.resource-selector")
+//	defer span.End()
+func (k Context) StartSpan(name string, attrs ...attribute.KeyValue) (Context, trace.Span) {
+	traceCtx, span := k.tracerOrDefault().Start(k.Context, name)
+	if len(attrs) > 0 {
+		span.SetAttributes(attrs...)
+	}
+	k.Context = traceCtx
+	return k, span
+}
@@ -0,0 +1,29 @@
+package context
+
+import (
+	"gorm.io/gorm"
+
+	"github.com/flanksource/commons-db/api"
+)
+
+// Transaction runs fn inside a database transaction, passing a derived
+// context whose DB() is the transaction handle so everything fn calls
+// participates automatically. The transaction commits when fn returns
+// nil and rolls back on error or panic.
+//
+// Nesting is supported: calling Transaction on a context that is already
+// transactional opens a savepoint, so an inner failure rolls back only
+// the inner work.
+func (k Context) Transaction(fn func(txCtx Context) error) error {
+	if k.db == nil {
+		return api.Errorf(api.EINTERNAL, "context has no database connection")
+	}
+
+	return k.DB().Transaction(func(tx *gorm.DB) error {
+		txCtx := k.WithDB(tx, k.pool)
+		// reads must see the transaction's own writes, so don't route
+		// them to a replica inside the transaction
+		txCtx.readDB = nil
+		return fn(txCtx)
+	})
+}
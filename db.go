@@ -0,0 +1,173 @@
+// Package duty wires together the database, migrations and context for
+// services built on this library.
+package duty
+
+import (
+	gocontext "context"
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/glebarez/sqlite"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/jackc/pgx/v5/stdlib"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+
+	"github.com/flanksource/commons-db/api"
+	"github.com/flanksource/commons-db/context"
+	"github.com/flanksource/commons-db/migrate"
+)
+
+// DefaultQueryTimeout bounds individual queries unless overridden per
+// query class.
+const DefaultQueryTimeout = 30 * time.Second
+
+// PoolOption customizes the pgx pool configuration before the pool is
+// created.
+type PoolOption func(*pgxpool.Config)
+
+// WithQueryExecMode sets pgx's query execution mode. Deployments behind
+// PgBouncer in transaction pooling mode need
+// pgx.QueryExecModeSimpleProtocol, since prepared statements don't
+// survive across pooled transactions.
+func WithQueryExecMode(mode pgx.QueryExecMode) PoolOption {
+	return func(config *pgxpool.Config) {
+		config.ConnConfig.DefaultQueryExecMode = mode
+	}
+}
+
+// WithStatementCacheCapacity sizes the per-connection prepared statement
+// and description caches; 0 disables them (also required for PgBouncer
+// transaction pooling).
+func WithStatementCacheCapacity(capacity int) PoolOption {
+	return func(config *pgxpool.Config) {
+		config.ConnConfig.StatementCacheCapacity = capacity
+		config.ConnConfig.DescriptionCacheCapacity = capacity
+	}
+}
+
+// WithMaxConns caps the pool size.
+func WithMaxConns(n int32) PoolOption {
+	return func(config *pgxpool.Config) {
+		config.MaxConns = n
+	}
+}
+
+// NewPgxPool creates a pgx connection pool from the connection string.
+func NewPgxPool(connection string, opts ...PoolOption) (*pgxpool.Pool, error) {
+	config, err := pgxpool.ParseConfig(connection)
+	if err != nil {
+		return nil, fmt.Errorf("invalid connection string: %w", err)
+	}
+	for _, opt := range opts {
+		opt(config)
+	}
+
+	pool, err := pgxpool.NewWithConfig(gocontext.Background(), config)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create connection pool: %w", err)
+	}
+	return pool, nil
+}
+
+// IsSQLite reports whether the connection string selects the SQLite
+// dialect (sqlite:// path, or a bare *.db file path).
+func IsSQLite(connection string) bool {
+	return strings.HasPrefix(connection, "sqlite://") || strings.HasSuffix(connection, ".db")
+}
+
+// NewGorm opens a gorm DB for the connection string. Postgres is the
+// default; sqlite:// connections return a SQLite-backed handle (the
+// types package maps its JSON column types onto both dialects), letting
+// small CLIs and tests run without a Postgres server.
+func NewGorm(connection string) (*gorm.DB, error) {
+	var dialector gorm.Dialector
+	if IsSQLite(connection) {
+		dialector = sqlite.Open(strings.TrimPrefix(connection, "sqlite://"))
+	} else {
+		dialector = postgres.Open(connection)
+	}
+
+	db, err := gorm.Open(dialector, DefaultGormConfig())
+	if err != nil {
+		return nil, fmt.Errorf("failed to open gorm connection: %w", err)
+	}
+	return db, nil
+}
+
+// DefaultGormConfig is the gorm configuration used by InitDB. Options
+// customize query logging (slow-query threshold, per-table suppression,
+// JSON output).
+func DefaultGormConfig(opts ...GormOption) *gorm.Config {
+	return &gorm.Config{
+		FullSaveAssociations: false,
+		Logger:               newGormLogger(opts...),
+	}
+}
+
+// InitDB connects to the database and returns the gorm handle and pgx
+// pool without running migrations. The pool is nil for SQLite
+// connections.
+func InitDB(connection string) (*gorm.DB, *pgxpool.Pool, error) {
+	if IsSQLite(connection) {
+		db, err := NewGorm(connection)
+		return db, nil, err
+	}
+
+	pool, err := NewPgxPool(connection)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	db, err := NewGorm(connection)
+	if err != nil {
+		pool.Close()
+		return nil, nil, err
+	}
+	return db, pool, nil
+}
+
+// SetupDB connects to the database, runs migrations, and returns a
+// fully wired context.
+func SetupDB(connection string, opts ...func(*api.Config)) (context.Context, error) {
+	config := api.NewConfig(connection)
+	for _, opt := range opts {
+		opt(&config)
+	}
+
+	db, pool, err := InitDB(connection)
+	if err != nil {
+		return context.Context{}, err
+	}
+
+	// migrations are Postgres SQL; SQLite callers create their schema
+	// with gorm AutoMigrate on the models they use
+	if IsSQLite(connection) {
+		return context.New().WithDB(db, nil), nil
+	}
+
+	sqlDB, err := sql.Open("pgx", stdlib.RegisterConnConfig(pool.Config().ConnConfig))
+	if err != nil {
+		return context.Context{}, fmt.Errorf("failed to open migration connection: %w", err)
+	}
+	defer sqlDB.Close() //nolint:errcheck
+
+	if err := migrate.RunMigrations(sqlDB, config); err != nil {
+		return context.Context{}, fmt.Errorf("failed to run migrations: %w", err)
+	}
+
+	ctx := context.New().WithDB(db, pool)
+
+	if config.ReadReplicaConnectionString != "" {
+		readDB, err := NewGorm(config.ReadReplicaConnectionString)
+		if err != nil {
+			return context.Context{}, fmt.Errorf("failed to connect to read replica: %w", err)
+		}
+		ctx = ctx.WithReadDB(readDB)
+	}
+
+	return ctx, nil
+}
@@ -0,0 +1,92 @@
+package db
+
+import (
+	"bytes"
+	gocontext "context"
+	"encoding/json"
+	"fmt"
+	"reflect"
+
+	"github.com/klauspost/compress/zstd"
+	"gorm.io/gorm/schema"
+
+	"github.com/flanksource/commons-db/context"
+)
+
+// defaultCompressThreshold is the serialized size above which values
+// are stored zstd-compressed; override with the db.compress.threshold
+// property. Small values stay plain JSON, so compression only kicks in
+// for the full config specs and manifests that dominate storage.
+const defaultCompressThreshold = 16 * 1024
+
+// zstdMagic is the zstd frame header; its presence distinguishes
+// compressed values from plain JSON on read, so existing rows written
+// before compression (or under a different threshold) keep scanning.
+var zstdMagic = []byte{0x28, 0xb5, 0x2f, 0xfd}
+
+// EncodeAll/DecodeAll on shared coders are safe for concurrent use.
+var (
+	zstdEncoder, _ = zstd.NewWriter(nil)
+	zstdDecoder, _ = zstd.NewReader(nil)
+)
+
+func init() {
+	schema.RegisterSerializer("zstdjson", ZstdJSONSerializer{})
+}
+
+// ZstdJSONSerializer is a gorm serializer for very large JSON columns:
+// values serialize to JSON as usual but are transparently
+// zstd-compressed past a size threshold. Models opt in per field:
+//
+//	Config types.JSON `gorm:"type:bytea;serializer:zstdjson"`
+//
+// The column must be bytea (or text); compressed bytes are not valid
+// JSONB. The field's Go type and the rest of the types API are
+// unchanged.
+type ZstdJSONSerializer struct{}
+
+func (ZstdJSONSerializer) Scan(ctx gocontext.Context, field *schema.Field, dst reflect.Value, dbValue any) error {
+	fieldValue := reflect.New(field.FieldType)
+
+	if dbValue != nil {
+		var raw []byte
+		switch v := dbValue.(type) {
+		case []byte:
+			raw = v
+		case string:
+			raw = []byte(v)
+		default:
+			return fmt.Errorf("failed to scan %s: unexpected type %T", field.Name, dbValue)
+		}
+
+		if bytes.HasPrefix(raw, zstdMagic) {
+			decompressed, err := zstdDecoder.DecodeAll(raw, nil)
+			if err != nil {
+				return fmt.Errorf("failed to decompress %s: %w", field.Name, err)
+			}
+			raw = decompressed
+		}
+
+		if len(raw) > 0 {
+			if err := json.Unmarshal(raw, fieldValue.Interface()); err != nil {
+				return err
+			}
+		}
+	}
+
+	field.ReflectValueOf(ctx, dst).Set(fieldValue.Elem())
+	return nil
+}
+
+func (ZstdJSONSerializer) Value(_ gocontext.Context, field *schema.Field, _ reflect.Value, fieldValue any) (any, error) {
+	raw, err := json.Marshal(fieldValue)
+	if err != nil {
+		return nil, fmt.Errorf("failed to serialize %s: %w", field.Name, err)
+	}
+
+	threshold := context.GlobalProperties().Int("db.compress.threshold", defaultCompressThreshold)
+	if len(raw) < threshold {
+		return raw, nil
+	}
+	return zstdEncoder.EncodeAll(raw, nil), nil
+}
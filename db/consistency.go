@@ -0,0 +1,74 @@
+package db
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/flanksource/commons-db/context"
+	"gorm.io/gorm"
+)
+
+// Read-your-writes session consistency for read-replica setups.
+//
+// After a write, capture a token (the primary's WAL insert LSN) and
+// attach it to the context with ctx.RequireFreshness(token). Read paths
+// that route to a replica should consult ReplicaCaughtUp / WaitForReplica
+// and fall back to the primary while the replica is behind.
+
+// CaptureConsistencyToken returns the primary's current WAL insert LSN.
+// Call it on the connection that performed the write.
+func CaptureConsistencyToken(ctx context.Context) (string, error) {
+	var lsn string
+	if err := ctx.DB().Raw("SELECT pg_current_wal_insert_lsn()::text").Scan(&lsn).Error; err != nil {
+		return "", fmt.Errorf("failed to capture consistency token: %w", err)
+	}
+	return lsn, nil
+}
+
+// ReplicaCaughtUp reports whether the replica has replayed WAL up to the
+// given token.
+func ReplicaCaughtUp(ctx context.Context, replica *gorm.DB, token string) (bool, error) {
+	if token == "" {
+		return true, nil
+	}
+
+	var caughtUp bool
+	if err := replica.WithContext(ctx).
+		Raw("SELECT pg_last_wal_replay_lsn() >= ?::pg_lsn", token).
+		Scan(&caughtUp).Error; err != nil {
+		return false, fmt.Errorf("failed to check replica lag: %w", err)
+	}
+	return caughtUp, nil
+}
+
+// WaitForReplica polls until the replica has replayed up to the token or
+// the timeout elapses. It returns nil as soon as the replica is fresh
+// enough for the session, and an error when reads should be routed to
+// the primary instead.
+func WaitForReplica(ctx context.Context, replica *gorm.DB, token string, timeout time.Duration) error {
+	if token == "" {
+		return nil
+	}
+
+	deadline := time.Now().Add(timeout)
+	interval := ctx.Properties().Duration("db.replica.poll_interval", 50*time.Millisecond)
+
+	for {
+		caughtUp, err := ReplicaCaughtUp(ctx, replica, token)
+		if err != nil {
+			return err
+		}
+		if caughtUp {
+			return nil
+		}
+		if time.Now().After(deadline) {
+			return fmt.Errorf("replica did not catch up to %s within %s", token, timeout)
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(interval):
+		}
+	}
+}
@@ -0,0 +1,248 @@
+// Package db holds database-level helpers (maintenance jobs, retry
+// wrappers, session management) that sit below the query layer.
+package db
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/flanksource/commons-db/context"
+)
+
+// IndexAdvisorOptions tunes the EXPLAIN-based index advisor.
+type IndexAdvisorOptions struct {
+	// Interval between advisor runs when started as a job.
+	Interval time.Duration
+
+	// MinMeanDuration filters pg_stat_statements to queries slower than
+	// this on average.
+	MinMeanDuration time.Duration
+
+	// MinCalls filters out one-off queries.
+	MinCalls int
+
+	// Tables restricts analysis to these tables; all reconciled tables
+	// when empty.
+	Tables []string
+}
+
+func (t IndexAdvisorOptions) withDefaults(ctx context.Context) IndexAdvisorOptions {
+	props := ctx.Properties()
+	if t.Interval <= 0 {
+		t.Interval = props.Duration("db.index_advisor.interval", time.Hour)
+	}
+	if t.MinMeanDuration <= 0 {
+		t.MinMeanDuration = props.Duration("db.index_advisor.min_duration", 100*time.Millisecond)
+	}
+	if t.MinCalls <= 0 {
+		t.MinCalls = props.Int("db.index_advisor.min_calls", 10)
+	}
+	if len(t.Tables) == 0 {
+		t.Tables = []string{"config_items", "config_changes", "config_analysis", "check_statuses"}
+	}
+	return t
+}
+
+// IndexSuggestion is one recommended index with the evidence behind it.
+type IndexSuggestion struct {
+	Table     string  `json:"table"`
+	Column    string  `json:"column"`
+	CreateSQL string  `json:"create_sql"`
+	Query     string  `json:"query"`
+	MeanMs    float64 `json:"mean_ms"`
+	Calls     int64   `json:"calls"`
+
+	// CostReduction is the fraction of planner cost removed by a
+	// hypothetical index (hypopg only); 0 when hypopg is unavailable.
+	CostReduction float64 `json:"cost_reduction,omitempty"`
+}
+
+// StartIndexAdvisor runs the advisor periodically until ctx is done,
+// logging suggestions. It is a no-op unless the db.index_advisor
+// property is enabled.
+func StartIndexAdvisor(ctx context.Context, opts IndexAdvisorOptions) {
+	if !ctx.Properties().On(false, "db.index_advisor") {
+		return
+	}
+	opts = opts.withDefaults(ctx)
+
+	go func() {
+		ticker := time.NewTicker(opts.Interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				suggestions, err := RunIndexAdvisor(ctx, opts)
+				if err != nil {
+					ctx.Warnf("index advisor: %v", err)
+					continue
+				}
+				for _, s := range suggestions {
+					detail, _ := json.Marshal(s)
+					ctx.Infof("index advisor suggestion: %s", detail)
+				}
+			}
+		}
+	}()
+}
+
+// RunIndexAdvisor samples slow queries from pg_stat_statements and
+// suggests indexes for frequently filtered columns, validating each
+// suggestion with hypopg when the extension is installed.
+func RunIndexAdvisor(ctx context.Context, opts IndexAdvisorOptions) ([]IndexSuggestion, error) {
+	opts = opts.withDefaults(ctx)
+
+	if ok, err := extensionExists(ctx, "pg_stat_statements"); err != nil {
+		return nil, err
+	} else if !ok {
+		return nil, fmt.Errorf("pg_stat_statements extension is not installed")
+	}
+	hasHypopg, err := extensionExists(ctx, "hypopg")
+	if err != nil {
+		return nil, err
+	}
+
+	type statRow struct {
+		Query  string
+		MeanMs float64
+		Calls  int64
+	}
+	var rows []statRow
+	if err := ctx.DB().Raw(`
+		SELECT query, mean_exec_time AS mean_ms, calls
+		FROM pg_stat_statements
+		WHERE mean_exec_time > ? AND calls >= ? AND query ILIKE 'select%'
+		ORDER BY mean_exec_time * calls DESC
+		LIMIT 50`,
+		float64(opts.MinMeanDuration.Milliseconds()), opts.MinCalls).
+		Scan(&rows).Error; err != nil {
+		return nil, fmt.Errorf("failed to sample pg_stat_statements: %w", err)
+	}
+
+	var suggestions []IndexSuggestion
+	for _, row := range rows {
+		for _, candidate := range candidateColumns(row.Query, opts.Tables) {
+			if indexed, err := columnIsIndexed(ctx, candidate.table, candidate.column); err != nil || indexed {
+				continue
+			}
+
+			suggestion := IndexSuggestion{
+				Table:     candidate.table,
+				Column:    candidate.column,
+				CreateSQL: fmt.Sprintf("CREATE INDEX CONCURRENTLY idx_%s_%s ON %s (%s)", candidate.table, candidate.column, candidate.table, candidate.column),
+				Query:     row.Query,
+				MeanMs:    row.MeanMs,
+				Calls:     row.Calls,
+			}
+
+			if hasHypopg {
+				reduction, err := hypotheticalCostReduction(ctx, candidate.table, candidate.column, row.Query)
+				if err != nil {
+					ctx.Debugf("index advisor: hypopg analysis failed for %s.%s: %v", candidate.table, candidate.column, err)
+				} else if reduction < 0.1 {
+					continue // the planner wouldn't meaningfully use it
+				} else {
+					suggestion.CostReduction = reduction
+				}
+			}
+
+			suggestions = append(suggestions, suggestion)
+		}
+	}
+	return suggestions, nil
+}
+
+type tableColumn struct {
+	table, column string
+}
+
+// whereColumnRegexp pulls `column <op>` references out of WHERE clauses.
+var whereColumnRegexp = regexp.MustCompile(`(?i)(?:where|and|or)\s+"?(\w+)"?\s*(?:=|!=|<|>|like|ilike|%)`)
+
+// candidateColumns extracts filtered columns from queries touching the
+// given tables.
+func candidateColumns(query string, tables []string) []tableColumn {
+	var table string
+	for _, t := range tables {
+		if strings.Contains(query, t) {
+			table = t
+			break
+		}
+	}
+	if table == "" {
+		return nil
+	}
+
+	seen := map[string]bool{}
+	var out []tableColumn
+	for _, match := range whereColumnRegexp.FindAllStringSubmatch(query, -1) {
+		column := strings.ToLower(match[1])
+		if column == "id" || seen[column] {
+			continue
+		}
+		seen[column] = true
+		out = append(out, tableColumn{table: table, column: column})
+	}
+	return out
+}
+
+func extensionExists(ctx context.Context, name string) (bool, error) {
+	var count int64
+	err := ctx.DB().Raw("SELECT COUNT(*) FROM pg_extension WHERE extname = ?", name).Scan(&count).Error
+	return count > 0, err
+}
+
+func columnIsIndexed(ctx context.Context, table, column string) (bool, error) {
+	var count int64
+	err := ctx.DB().Raw(`
+		SELECT COUNT(*) FROM pg_indexes
+		WHERE tablename = ? AND indexdef LIKE '%' || ? || '%'`, table, column).
+		Scan(&count).Error
+	return count > 0, err
+}
+
+// hypotheticalCostReduction creates a hypothetical index via hypopg and
+// compares planner cost for the query with and without it.
+func hypotheticalCostReduction(ctx context.Context, table, column, query string) (float64, error) {
+	before, err := planCost(ctx, query)
+	if err != nil {
+		return 0, err
+	}
+
+	if err := ctx.DB().Exec("SELECT hypopg_create_index(?)",
+		fmt.Sprintf("CREATE INDEX ON %s (%s)", table, column)).Error; err != nil {
+		return 0, err
+	}
+	defer ctx.DB().Exec("SELECT hypopg_reset()") //nolint:errcheck
+
+	after, err := planCost(ctx, query)
+	if err != nil {
+		return 0, err
+	}
+	if before <= 0 {
+		return 0, nil
+	}
+	return (before - after) / before, nil
+}
+
+func planCost(ctx context.Context, query string) (float64, error) {
+	var plan string
+	if err := ctx.DB().Raw("EXPLAIN (FORMAT JSON) " + query).Scan(&plan).Error; err != nil {
+		return 0, err
+	}
+
+	var parsed []struct {
+		Plan struct {
+			TotalCost float64 `json:"Total Cost"`
+		} `json:"Plan"`
+	}
+	if err := json.Unmarshal([]byte(plan), &parsed); err != nil || len(parsed) == 0 {
+		return 0, fmt.Errorf("failed to parse explain output: %v", err)
+	}
+	return parsed[0].Plan.TotalCost, nil
+}
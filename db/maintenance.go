@@ -0,0 +1,153 @@
+package db
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/flanksource/commons-db/context"
+)
+
+// MaintenanceOptions tunes the vacuum/analyze maintenance job.
+type MaintenanceOptions struct {
+	// Interval between maintenance passes.
+	Interval time.Duration
+
+	// DeadRatio is the dead-tuple fraction above which a table gets a
+	// targeted VACUUM (ANALYZE).
+	DeadRatio float64
+
+	// Window is the low-traffic window ("01:00-05:00", UTC) outside of
+	// which no vacuum is issued; empty means any time.
+	Window string
+
+	// Tables restricts maintenance to these tables; the high-churn
+	// defaults when empty.
+	Tables []string
+}
+
+func (t MaintenanceOptions) withDefaults(ctx context.Context) MaintenanceOptions {
+	props := ctx.Properties()
+	if t.Interval <= 0 {
+		t.Interval = props.Duration("db.maintenance.interval", 15*time.Minute)
+	}
+	if t.DeadRatio <= 0 {
+		t.DeadRatio = props.Float("db.maintenance.dead_ratio", 0.2)
+	}
+	if t.Window == "" {
+		t.Window = props.String("db.maintenance.window", "")
+	}
+	if len(t.Tables) == 0 {
+		t.Tables = []string{"config_items", "config_changes", "config_analysis", "check_statuses", "job_history"}
+	}
+	return t
+}
+
+// VacuumResult records one targeted vacuum for metrics/logging.
+type VacuumResult struct {
+	Table      string        `json:"table"`
+	DeadTuples int64         `json:"dead_tuples"`
+	DeadRatio  float64       `json:"dead_ratio"`
+	Duration   time.Duration `json:"duration"`
+}
+
+// StartMaintenance monitors dead tuple ratios on hot tables and issues
+// targeted VACUUM (ANALYZE) during the configured window, keeping
+// selector query plans healthy between autovacuum runs. It is a no-op
+// unless the db.maintenance property is enabled.
+func StartMaintenance(ctx context.Context, opts MaintenanceOptions) {
+	if !ctx.Properties().On(false, "db.maintenance") {
+		return
+	}
+	opts = opts.withDefaults(ctx)
+
+	go func() {
+		ticker := time.NewTicker(opts.Interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				results, err := RunMaintenance(ctx, opts)
+				if err != nil {
+					ctx.Errorf("maintenance pass failed: %v", err)
+					continue
+				}
+				for _, result := range results {
+					ctx.Infof("vacuumed %s: %d dead tuples (%.0f%%), took %s",
+						result.Table, result.DeadTuples, result.DeadRatio*100, result.Duration)
+				}
+			}
+		}
+	}()
+}
+
+// RunMaintenance performs one pass: tables over the dead-ratio
+// threshold are vacuumed, provided the current time is inside the
+// window.
+func RunMaintenance(ctx context.Context, opts MaintenanceOptions) ([]VacuumResult, error) {
+	opts = opts.withDefaults(ctx)
+	if !inWindow(time.Now().UTC(), opts.Window) {
+		return nil, nil
+	}
+
+	type tableStat struct {
+		Table      string
+		DeadTuples int64
+		DeadRatio  float64
+	}
+	var stats []tableStat
+	if err := ctx.DB().Raw(`
+		SELECT
+			relname AS table,
+			n_dead_tup AS dead_tuples,
+			CASE WHEN n_live_tup + n_dead_tup > 0
+				THEN n_dead_tup::float / (n_live_tup + n_dead_tup)
+				ELSE 0 END AS dead_ratio
+		FROM pg_stat_user_tables
+		WHERE relname IN ?`, opts.Tables).Scan(&stats).Error; err != nil {
+		return nil, fmt.Errorf("failed to read table statistics: %w", err)
+	}
+
+	var results []VacuumResult
+	for _, stat := range stats {
+		if stat.DeadRatio < opts.DeadRatio {
+			continue
+		}
+
+		started := time.Now()
+		// VACUUM cannot run inside a transaction block, so use the pool
+		// directly rather than gorm's session
+		if _, err := ctx.Pool().Exec(ctx, "VACUUM (ANALYZE) "+stat.Table); err != nil {
+			return results, fmt.Errorf("vacuum of %s failed: %w", stat.Table, err)
+		}
+		results = append(results, VacuumResult{
+			Table:      stat.Table,
+			DeadTuples: stat.DeadTuples,
+			DeadRatio:  stat.DeadRatio,
+			Duration:   time.Since(started),
+		})
+	}
+	return results, nil
+}
+
+// inWindow checks "HH:MM-HH:MM" windows, wrapping midnight; malformed
+// or empty windows allow any time.
+func inWindow(now time.Time, window string) bool {
+	if window == "" {
+		return true
+	}
+
+	var startH, startM, endH, endM int
+	if _, err := fmt.Sscanf(window, "%d:%d-%d:%d", &startH, &startM, &endH, &endM); err != nil {
+		return true
+	}
+
+	minutes := now.Hour()*60 + now.Minute()
+	start := startH*60 + startM
+	end := endH*60 + endM
+	if start <= end {
+		return minutes >= start && minutes < end
+	}
+	return minutes >= start || minutes < end
+}
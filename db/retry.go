@@ -0,0 +1,73 @@
+package db
+
+import (
+	"errors"
+	"math/rand"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgconn"
+
+	"github.com/flanksource/commons-db/context"
+)
+
+// Postgres error codes that indicate the transaction lost a race and can
+// be retried as-is.
+const (
+	pgSerializationFailure = "40001"
+	pgDeadlockDetected     = "40P01"
+	pgLockNotAvailable     = "55P03"
+)
+
+// IsSerializationError reports whether the error is a deadlock,
+// serialization failure or lock timeout.
+func IsSerializationError(err error) bool {
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) {
+		switch pgErr.Code {
+		case pgSerializationFailure, pgDeadlockDetected, pgLockNotAvailable:
+			return true
+		}
+	}
+
+	// gorm wraps some driver errors without preserving the pg error
+	msg := err.Error()
+	return strings.Contains(msg, "deadlock detected") ||
+		strings.Contains(msg, "could not serialize access") ||
+		strings.Contains(msg, "lock timeout")
+}
+
+// RetryOnSerialization runs fn, retrying when it fails with a deadlock,
+// serialization failure or lock timeout. Backoff is exponential with
+// jitter; attempts and base backoff are tunable via the
+// db.serialization_retry.max and db.serialization_retry.backoff
+// properties.
+//
+// fn must be safe to re-run from the start (i.e. it should open its own
+// transaction).
+func RetryOnSerialization(ctx context.Context, fn func() error) error {
+	props := ctx.Properties()
+	maxAttempts := props.Int("db.serialization_retry.max", 3)
+	baseBackoff := props.Duration("db.serialization_retry.backoff", 100*time.Millisecond)
+
+	var err error
+	for attempt := 0; ; attempt++ {
+		if err = fn(); err == nil || !IsSerializationError(err) {
+			return err
+		}
+		if attempt >= maxAttempts {
+			return err
+		}
+
+		backoff := baseBackoff << attempt
+		backoff += time.Duration(rand.Int63n(int64(backoff))) // full jitter
+		ctx.Debugf("retrying after serialization conflict (attempt %d/%d, waiting %s): %v",
+			attempt+1, maxAttempts, backoff, err)
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(backoff):
+		}
+	}
+}
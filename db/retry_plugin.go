@@ -0,0 +1,165 @@
+package db
+
+import (
+	gocontext "context"
+	"database/sql"
+	"errors"
+	"io"
+	"math/rand"
+	"net"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgconn"
+	"gorm.io/gorm"
+
+	"github.com/flanksource/commons-db/context"
+)
+
+// Postgres error classes for lost connections; these surface when the
+// server restarts or a pooler recycles the session mid-statement.
+const (
+	pgConnectionFailure  = "08006"
+	pgCannotConnect      = "08001"
+	pgAdminShutdown      = "57P01"
+	pgCrashShutdown      = "57P02"
+	pgCannotConnectRetry = "57P03"
+)
+
+// IsTransientError reports whether the error is worth retrying: a
+// serialization conflict (see IsSerializationError), a dropped or
+// refused connection, or an unexpected EOF from the server.
+func IsTransientError(err error) bool {
+	if err == nil {
+		return false
+	}
+	if IsSerializationError(err) {
+		return true
+	}
+
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) {
+		switch pgErr.Code {
+		case pgConnectionFailure, pgCannotConnect, pgAdminShutdown, pgCrashShutdown, pgCannotConnectRetry:
+			return true
+		}
+	}
+
+	var netErr net.Error
+	if errors.As(err, &netErr) && netErr.Timeout() {
+		return true
+	}
+	if errors.Is(err, syscall.ECONNRESET) || errors.Is(err, syscall.ECONNREFUSED) ||
+		errors.Is(err, io.ErrUnexpectedEOF) {
+		return true
+	}
+
+	msg := err.Error()
+	return strings.Contains(msg, "connection reset by peer") ||
+		strings.Contains(msg, "broken pipe") ||
+		strings.Contains(msg, "conn closed")
+}
+
+// WithRetry runs fn, retrying transient errors (serialization conflicts,
+// deadlocks, dropped connections) with exponential backoff and jitter.
+// Attempts and base backoff are tunable via the db.retry.max and
+// db.retry.backoff properties.
+//
+// fn must be safe to re-run from the start.
+func WithRetry(ctx context.Context, fn func(ctx context.Context) error) error {
+	props := ctx.Properties()
+	maxAttempts := props.Int("db.retry.max", 3)
+	baseBackoff := props.Duration("db.retry.backoff", 100*time.Millisecond)
+
+	var err error
+	for attempt := 0; ; attempt++ {
+		if err = fn(ctx); err == nil || !IsTransientError(err) {
+			return err
+		}
+		if attempt >= maxAttempts {
+			return err
+		}
+
+		backoff := baseBackoff << attempt
+		backoff += time.Duration(rand.Int63n(int64(backoff))) // full jitter
+		ctx.Debugf("retrying after transient db error (attempt %d/%d, waiting %s): %v",
+			attempt+1, maxAttempts, backoff, err)
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(backoff):
+		}
+	}
+}
+
+// RetryPlugin is a gorm plugin that retries individual statements that
+// fail with a transient error, by wrapping the connection pool rather
+// than the callback chain so every query path (Find, Raw, Exec, Row) is
+// covered uniformly. Statements inside an explicit transaction are not
+// retried — the transaction is already poisoned — use WithRetry around
+// the whole transaction for those.
+type RetryPlugin struct {
+	// MaxRetries defaults to 2; backoff is BaseBackoff doubled per
+	// attempt.
+	MaxRetries  int
+	BaseBackoff time.Duration
+}
+
+func (p RetryPlugin) Name() string {
+	return "commons-db:retry"
+}
+
+func (p RetryPlugin) Initialize(db *gorm.DB) error {
+	if p.MaxRetries <= 0 {
+		p.MaxRetries = 2
+	}
+	if p.BaseBackoff <= 0 {
+		p.BaseBackoff = 100 * time.Millisecond
+	}
+	db.ConnPool = &retryConnPool{ConnPool: db.ConnPool, plugin: p}
+	return nil
+}
+
+// retryConnPool wraps the root connection pool; gorm uses the bare
+// transaction ConnPool inside Begin/Commit, so transactional statements
+// bypass the wrapper automatically.
+type retryConnPool struct {
+	gorm.ConnPool
+	plugin RetryPlugin
+}
+
+func (p *retryConnPool) ExecContext(ctx gocontext.Context, query string, args ...any) (sql.Result, error) {
+	var result sql.Result
+	err := p.withRetry(ctx, func() (err error) {
+		result, err = p.ConnPool.ExecContext(ctx, query, args...)
+		return err
+	})
+	return result, err
+}
+
+func (p *retryConnPool) QueryContext(ctx gocontext.Context, query string, args ...any) (*sql.Rows, error) {
+	var rows *sql.Rows
+	err := p.withRetry(ctx, func() (err error) {
+		rows, err = p.ConnPool.QueryContext(ctx, query, args...)
+		return err
+	})
+	return rows, err
+}
+
+func (p *retryConnPool) withRetry(ctx gocontext.Context, fn func() error) error {
+	var err error
+	for attempt := 0; ; attempt++ {
+		if err = fn(); err == nil || !IsTransientError(err) || attempt >= p.plugin.MaxRetries {
+			return err
+		}
+
+		backoff := p.plugin.BaseBackoff << attempt
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(backoff):
+		}
+	}
+}
@@ -0,0 +1,206 @@
+package db
+
+import (
+	gocontext "context"
+	"database/sql"
+	"encoding/json"
+	"math/rand"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// SamplingPlugin is an opt-in gorm plugin that samples query shapes for
+// performance support bundles: normalized SQL with every literal
+// redacted, durations, and affected row counts. Nothing is collected
+// unless the plugin is installed, and nothing leaves the process —
+// callers export the aggregate locally via Report or WriteReport.
+//
+// Like RetryPlugin it wraps the connection pool, so every query path is
+// observed uniformly.
+type SamplingPlugin struct {
+	// SampleRate is the fraction of statements recorded, 0..1
+	// (default 0.1). Aggregation is per query shape, so a low rate still
+	// surfaces the hot statements.
+	SampleRate float64
+
+	mu      sync.Mutex
+	samples map[string]*StatementSample
+}
+
+// StatementSample is the aggregate for one query shape.
+type StatementSample struct {
+	// Query is the normalized SQL: literals are replaced with ? and
+	// whitespace is collapsed, so no data values ever appear.
+	Query string `json:"query"`
+
+	Count         int64         `json:"count"`
+	TotalDuration time.Duration `json:"total_duration"`
+	MaxDuration   time.Duration `json:"max_duration"`
+
+	// Rows counts affected rows; only exec statements report it.
+	Rows int64 `json:"rows,omitempty"`
+}
+
+func (p *SamplingPlugin) Name() string {
+	return "commons-db:sampling"
+}
+
+func (p *SamplingPlugin) Initialize(db *gorm.DB) error {
+	if p.SampleRate <= 0 || p.SampleRate > 1 {
+		p.SampleRate = 0.1
+	}
+	p.samples = map[string]*StatementSample{}
+	db.ConnPool = &samplingConnPool{ConnPool: db.ConnPool, plugin: p}
+	return nil
+}
+
+// Report returns the sampled shapes ordered by total time, heaviest
+// first.
+func (p *SamplingPlugin) Report() []StatementSample {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	report := make([]StatementSample, 0, len(p.samples))
+	for _, sample := range p.samples {
+		report = append(report, *sample)
+	}
+	sort.Slice(report, func(i, j int) bool { return report[i].TotalDuration > report[j].TotalDuration })
+	return report
+}
+
+// WriteReport writes the report as JSON to path, for inclusion in a
+// support bundle.
+func (p *SamplingPlugin) WriteReport(path string) error {
+	data, err := json.MarshalIndent(p.Report(), "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0o644)
+}
+
+func (p *SamplingPlugin) record(query string, duration time.Duration, rows int64) {
+	shape := NormalizeStatement(query)
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	sample, ok := p.samples[shape]
+	if !ok {
+		sample = &StatementSample{Query: shape}
+		p.samples[shape] = sample
+	}
+	sample.Count++
+	sample.TotalDuration += duration
+	sample.MaxDuration = max(sample.MaxDuration, duration)
+	sample.Rows += rows
+}
+
+type samplingConnPool struct {
+	gorm.ConnPool
+	plugin *SamplingPlugin
+}
+
+func (p *samplingConnPool) ExecContext(ctx gocontext.Context, query string, args ...any) (sql.Result, error) {
+	if rand.Float64() >= p.plugin.SampleRate {
+		return p.ConnPool.ExecContext(ctx, query, args...)
+	}
+
+	started := time.Now()
+	result, err := p.ConnPool.ExecContext(ctx, query, args...)
+	var rows int64
+	if err == nil && result != nil {
+		rows, _ = result.RowsAffected()
+	}
+	p.plugin.record(query, time.Since(started), rows)
+	return result, err
+}
+
+func (p *samplingConnPool) QueryContext(ctx gocontext.Context, query string, args ...any) (*sql.Rows, error) {
+	if rand.Float64() >= p.plugin.SampleRate {
+		return p.ConnPool.QueryContext(ctx, query, args...)
+	}
+
+	started := time.Now()
+	rows, err := p.ConnPool.QueryContext(ctx, query, args...)
+	p.plugin.record(query, time.Since(started), 0)
+	return rows, err
+}
+
+// NormalizeStatement reduces a statement to its shape: string literals
+// (including dollar-quoted ones), numbers and comments are replaced with
+// ?, and whitespace is collapsed. The redaction errs on the strict side —
+// an identifier starting with a digit is redacted too — since the output
+// is destined for telemetry.
+func NormalizeStatement(query string) string {
+	var b strings.Builder
+	b.Grow(len(query))
+
+	i := 0
+	lastSpace := true
+	for i < len(query) {
+		ch := query[i]
+		switch {
+		case ch == '\'':
+			i = skipQuoted(query, i)
+			b.WriteByte('?')
+			lastSpace = false
+		case ch == '$' && strings.HasPrefix(query[i:], "$$"):
+			if end := strings.Index(query[i+2:], "$$"); end >= 0 {
+				i += end + 4
+			} else {
+				i = len(query)
+			}
+			b.WriteByte('?')
+			lastSpace = false
+		case ch == '-' && strings.HasPrefix(query[i:], "--"):
+			if end := strings.IndexByte(query[i:], '\n'); end >= 0 {
+				i += end
+			} else {
+				i = len(query)
+			}
+		case ch >= '0' && ch <= '9' && lastSpace:
+			for i < len(query) && (query[i] >= '0' && query[i] <= '9' || query[i] == '.' || query[i] == 'e' || query[i] == '-') {
+				i++
+			}
+			b.WriteByte('?')
+			lastSpace = false
+		case ch == ' ' || ch == '\t' || ch == '\n' || ch == '\r':
+			if !lastSpace {
+				b.WriteByte(' ')
+			}
+			lastSpace = true
+			i++
+		default:
+			b.WriteByte(ch)
+			// numbers only start after a separator, never mid-identifier
+			lastSpace = !isIdentChar(ch)
+			i++
+		}
+	}
+	return strings.TrimSpace(b.String())
+}
+
+// skipQuoted returns the index past a single-quoted literal starting at
+// start, honoring ” escapes.
+func skipQuoted(query string, start int) int {
+	i := start + 1
+	for i < len(query) {
+		if query[i] == '\'' {
+			if i+1 < len(query) && query[i+1] == '\'' {
+				i += 2
+				continue
+			}
+			return i + 1
+		}
+		i++
+	}
+	return i
+}
+
+func isIdentChar(ch byte) bool {
+	return ch == '_' || ch >= 'a' && ch <= 'z' || ch >= 'A' && ch <= 'Z' || ch >= '0' && ch <= '9'
+}
@@ -0,0 +1,111 @@
+package db
+
+import (
+	gocontext "context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+
+	"github.com/flanksource/commons-db/context"
+)
+
+// defaultStatementTimeout bounds individual statements when the caller's
+// context has no deadline of its own; override with the
+// db.statement_timeout property.
+const defaultStatementTimeout = 30 * time.Second
+
+// StatementTimeoutPlugin is a gorm plugin that enforces a per-statement
+// timeout so a runaway selector cannot hold a connection indefinitely.
+//
+// Plain statements get a context deadline (pgx cancels the query
+// server-side when it expires). Transactions additionally get
+// `SET LOCAL statement_timeout` on begin, since a deadline on the whole
+// transaction context would bound the transaction, not each statement.
+type StatementTimeoutPlugin struct {
+	// Timeout is the fallback when the db.statement_timeout property is
+	// unset; defaults to defaultStatementTimeout.
+	Timeout time.Duration
+}
+
+func (p StatementTimeoutPlugin) Name() string {
+	return "commons-db:statement-timeout"
+}
+
+func (p StatementTimeoutPlugin) Initialize(db *gorm.DB) error {
+	if p.Timeout <= 0 {
+		p.Timeout = defaultStatementTimeout
+	}
+	db.ConnPool = &timeoutConnPool{ConnPool: db.ConnPool, plugin: p}
+	return nil
+}
+
+func (p StatementTimeoutPlugin) timeout() time.Duration {
+	return context.GlobalProperties().Duration("db.statement_timeout", p.Timeout)
+}
+
+// timeoutConnPool bounds every statement with a context deadline unless
+// the caller already set a sooner one.
+type timeoutConnPool struct {
+	gorm.ConnPool
+	plugin StatementTimeoutPlugin
+}
+
+// BeginTx starts a transaction and applies SET LOCAL statement_timeout
+// to it, covering every operation inside the transaction regardless of
+// type — the statement-level deadline wrapper is bypassed there, and a
+// deadline on the transaction's context would bound the transaction as
+// a whole rather than each statement.
+func (p *timeoutConnPool) BeginTx(ctx gocontext.Context, opt *sql.TxOptions) (gorm.ConnPool, error) {
+	var tx gorm.ConnPool
+	var err error
+	switch beginner := p.ConnPool.(type) {
+	case gorm.ConnPoolBeginner:
+		tx, err = beginner.BeginTx(ctx, opt)
+	case gorm.TxBeginner:
+		tx, err = beginner.BeginTx(ctx, opt)
+	default:
+		return nil, gorm.ErrInvalidTransaction
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	timeout := p.plugin.timeout()
+	if _, err := tx.ExecContext(ctx, fmt.Sprintf("SET LOCAL statement_timeout = %d", timeout.Milliseconds())); err != nil {
+		if committer, ok := tx.(gorm.TxCommitter); ok {
+			_ = committer.Rollback()
+		}
+		return nil, fmt.Errorf("failed to set statement_timeout: %w", err)
+	}
+	return tx, nil
+}
+
+func (p *timeoutConnPool) ExecContext(ctx gocontext.Context, query string, args ...any) (sql.Result, error) {
+	ctx, cancel := p.bound(ctx)
+	defer cancel()
+	return p.ConnPool.ExecContext(ctx, query, args...)
+}
+
+func (p *timeoutConnPool) QueryContext(ctx gocontext.Context, query string, args ...any) (*sql.Rows, error) {
+	// the returned rows are read after this call returns, so the
+	// deadline context must outlive it: cleanup is deferred to the
+	// deadline itself rather than this function's return
+	ctx, cancel := p.bound(ctx)
+	rows, err := p.ConnPool.QueryContext(ctx, query, args...)
+	if err != nil {
+		cancel()
+		return nil, err
+	}
+	time.AfterFunc(p.plugin.timeout(), cancel)
+	return rows, nil
+}
+
+func (p *timeoutConnPool) bound(ctx gocontext.Context) (gocontext.Context, gocontext.CancelFunc) {
+	timeout := p.plugin.timeout()
+	if deadline, ok := ctx.Deadline(); ok && time.Until(deadline) <= timeout {
+		return ctx, func() {}
+	}
+	return gocontext.WithTimeout(ctx, timeout)
+}
@@ -0,0 +1,80 @@
+package duty
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/flanksource/commons-db/context"
+)
+
+// TableStats is the size and health snapshot of one table.
+type TableStats struct {
+	Table string `json:"table"`
+
+	// RowCount is the planner's live-tuple estimate, not an exact COUNT.
+	RowCount int64 `json:"rowCount"`
+
+	TotalBytes int64 `json:"totalBytes"`
+	IndexBytes int64 `json:"indexBytes"`
+
+	DeadTuples int64 `json:"deadTuples"`
+
+	// DeadRatio is dead tuples over live+dead, a proxy for vacuum debt.
+	DeadRatio float64 `json:"deadRatio"`
+
+	// IndexBloatRatio is index size over table size; a value well above
+	// what the schema justifies usually means bloated or redundant
+	// indexes.
+	IndexBloatRatio float64 `json:"indexBloatRatio"`
+}
+
+// DBStats returns per-table statistics for the public schema, ordered
+// by total size descending, so operators can diagnose growth from the
+// CLI.
+func DBStats(ctx context.Context) ([]TableStats, error) {
+	var stats []TableStats
+	err := ctx.ReadDB().Raw(`
+		SELECT
+			relname AS table,
+			n_live_tup AS row_count,
+			pg_total_relation_size(relid) AS total_bytes,
+			pg_indexes_size(relid) AS index_bytes,
+			n_dead_tup AS dead_tuples,
+			CASE WHEN n_live_tup + n_dead_tup > 0
+				THEN n_dead_tup::float / (n_live_tup + n_dead_tup)
+				ELSE 0 END AS dead_ratio,
+			CASE WHEN pg_relation_size(relid) > 0
+				THEN pg_indexes_size(relid)::float / pg_relation_size(relid)
+				ELSE 0 END AS index_bloat_ratio
+		FROM pg_stat_user_tables
+		ORDER BY pg_total_relation_size(relid) DESC`).Scan(&stats).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to collect table stats: %w", err)
+	}
+	return stats, nil
+}
+
+// Pretty renders the stats as an aligned text table.
+func Pretty(stats []TableStats) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "%-40s %12s %10s %10s %8s %8s\n", "TABLE", "ROWS", "SIZE", "INDEXES", "DEAD%", "IDX/TBL")
+	for _, s := range stats {
+		fmt.Fprintf(&b, "%-40s %12d %10s %10s %7.1f%% %8.2f\n",
+			s.Table, s.RowCount, prettyBytes(s.TotalBytes), prettyBytes(s.IndexBytes),
+			s.DeadRatio*100, s.IndexBloatRatio)
+	}
+	return b.String()
+}
+
+func prettyBytes(n int64) string {
+	const unit = 1024
+	if n < unit {
+		return fmt.Sprintf("%dB", n)
+	}
+	div, exp := int64(unit), 0
+	for v := n / unit; v >= unit; v /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f%ciB", float64(n)/float64(div), "KMGTPE"[exp])
+}
@@ -0,0 +1,128 @@
+package duty
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"time"
+
+	embeddedpostgres "github.com/fergusstrange/embedded-postgres"
+
+	"github.com/flanksource/commons-db/context"
+)
+
+// EmbeddedOptions configures StartEmbedded.
+type EmbeddedOptions struct {
+	// DataDir is where the embedded Postgres keeps its data;
+	// a temporary directory when empty.
+	DataDir string
+
+	// Port for the embedded Postgres (default 6432).
+	Port int
+
+	Database string // default "duty"
+	Username string // default "postgres"
+	Password string // default "postgres"
+
+	// Postgrest also starts a postgrest process against the database
+	// (requires the postgrest binary on PATH).
+	Postgrest bool
+
+	// PostgrestPort (default 3000).
+	PostgrestPort int
+}
+
+func (t EmbeddedOptions) withDefaults() EmbeddedOptions {
+	if t.Port == 0 {
+		t.Port = 6432
+	}
+	if t.Database == "" {
+		t.Database = "duty"
+	}
+	if t.Username == "" {
+		t.Username = "postgres"
+	}
+	if t.Password == "" {
+		t.Password = "postgres"
+	}
+	if t.PostgrestPort == 0 {
+		t.PostgrestPort = 3000
+	}
+	return t
+}
+
+func (t EmbeddedOptions) connectionString() string {
+	return fmt.Sprintf("postgres://%s:%s@localhost:%d/%s?sslmode=disable",
+		t.Username, t.Password, t.Port, t.Database)
+}
+
+// StartEmbedded launches an embedded Postgres, runs migrations, and
+// returns a fully wired context plus a stop function — enabling
+// single-binary demo/dev startup without an external database.
+func StartEmbedded(baseCtx context.Context, opts EmbeddedOptions) (context.Context, func() error, error) {
+	opts = opts.withDefaults()
+
+	dataDir := opts.DataDir
+	if dataDir == "" {
+		var err error
+		if dataDir, err = os.MkdirTemp("", "duty-embedded-"); err != nil {
+			return baseCtx, nil, err
+		}
+	}
+	if err := os.MkdirAll(dataDir, 0o755); err != nil {
+		return baseCtx, nil, err
+	}
+
+	embedded := embeddedpostgres.NewDatabase(embeddedpostgres.DefaultConfig().
+		Port(uint32(opts.Port)).
+		Database(opts.Database).
+		Username(opts.Username).
+		Password(opts.Password).
+		DataPath(filepath.Join(dataDir, "data")).
+		RuntimePath(filepath.Join(dataDir, "runtime")).
+		StartTimeout(60 * time.Second))
+
+	if err := embedded.Start(); err != nil {
+		return baseCtx, nil, fmt.Errorf("failed to start embedded postgres: %w", err)
+	}
+
+	ctx, err := SetupDB(opts.connectionString())
+	if err != nil {
+		_ = embedded.Stop()
+		return baseCtx, nil, err
+	}
+	ctx = ctx.Wrap(baseCtx)
+
+	var postgrest *exec.Cmd
+	if opts.Postgrest {
+		if postgrest, err = startPostgrest(ctx, opts); err != nil {
+			_ = embedded.Stop()
+			return baseCtx, nil, err
+		}
+	}
+
+	stop := func() error {
+		if postgrest != nil && postgrest.Process != nil {
+			_ = postgrest.Process.Kill()
+		}
+		ctx.Pool().Close()
+		return embedded.Stop()
+	}
+	return ctx, stop, nil
+}
+
+func startPostgrest(ctx context.Context, opts EmbeddedOptions) (*exec.Cmd, error) {
+	cmd := exec.Command("postgrest")
+	cmd.Env = append(os.Environ(),
+		"PGRST_DB_URI="+opts.connectionString(),
+		"PGRST_DB_SCHEMA=public",
+		"PGRST_DB_ANON_ROLE="+opts.Username,
+		fmt.Sprintf("PGRST_SERVER_PORT=%d", opts.PostgrestPort),
+	)
+	if err := cmd.Start(); err != nil {
+		return nil, fmt.Errorf("failed to start postgrest (is it on PATH?): %w", err)
+	}
+	ctx.Infof("postgrest listening on :%d", opts.PostgrestPort)
+	return cmd, nil
+}
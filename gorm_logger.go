@@ -0,0 +1,154 @@
+package duty
+
+import (
+	gocontext "context"
+	"encoding/json"
+	"strings"
+	"time"
+
+	"github.com/flanksource/commons/logger"
+	gormlogger "gorm.io/gorm/logger"
+)
+
+// GormOption customizes the gorm configuration built by
+// DefaultGormConfig.
+type GormOption func(*gormLogger)
+
+// WithSlowQueryThreshold logs queries slower than the threshold at warn
+// level (default 1s, 0 disables slow-query logging).
+func WithSlowQueryThreshold(threshold time.Duration) GormOption {
+	return func(l *gormLogger) {
+		l.slowThreshold = threshold
+	}
+}
+
+// WithoutTableLogs suppresses query logging for the given tables, for
+// hot paths (e.g. job_history heartbeats) that would drown the log.
+func WithoutTableLogs(tables ...string) GormOption {
+	return func(l *gormLogger) {
+		for _, table := range tables {
+			l.suppressedTables[table] = struct{}{}
+		}
+	}
+}
+
+// WithJSONLogs emits each query log line as a structured JSON object
+// instead of formatted text.
+func WithJSONLogs() GormOption {
+	return func(l *gormLogger) {
+		l.json = true
+	}
+}
+
+// WithGormLogLevel sets the minimum gorm log level (silent, error, warn
+// or info).
+func WithGormLogLevel(level gormlogger.LogLevel) GormOption {
+	return func(l *gormLogger) {
+		l.level = level
+	}
+}
+
+// gormLogger adapts the commons logger to gorm's logger interface with
+// slow-query detection and per-table suppression.
+type gormLogger struct {
+	log              logger.Logger
+	level            gormlogger.LogLevel
+	slowThreshold    time.Duration
+	suppressedTables map[string]struct{}
+	json             bool
+}
+
+func newGormLogger(opts ...GormOption) *gormLogger {
+	l := &gormLogger{
+		log:              logger.StandardLogger(),
+		level:            gormlogger.Warn,
+		slowThreshold:    time.Second,
+		suppressedTables: map[string]struct{}{},
+	}
+	for _, opt := range opts {
+		opt(l)
+	}
+	return l
+}
+
+func (l *gormLogger) LogMode(level gormlogger.LogLevel) gormlogger.Interface {
+	clone := *l
+	clone.level = level
+	return &clone
+}
+
+func (l *gormLogger) Info(ctx gocontext.Context, format string, args ...any) {
+	if l.level >= gormlogger.Info {
+		l.log.Infof(format, args...)
+	}
+}
+
+func (l *gormLogger) Warn(ctx gocontext.Context, format string, args ...any) {
+	if l.level >= gormlogger.Warn {
+		l.log.Warnf(format, args...)
+	}
+}
+
+func (l *gormLogger) Error(ctx gocontext.Context, format string, args ...any) {
+	if l.level >= gormlogger.Error {
+		l.log.Errorf(format, args...)
+	}
+}
+
+func (l *gormLogger) Trace(ctx gocontext.Context, begin time.Time, fc func() (string, int64), err error) {
+	if l.level <= gormlogger.Silent {
+		return
+	}
+
+	elapsed := time.Since(begin)
+	slow := l.slowThreshold > 0 && elapsed >= l.slowThreshold
+	if err == nil && !slow && l.level < gormlogger.Info {
+		return
+	}
+
+	sql, rows := fc()
+	if l.suppressed(sql) && err == nil {
+		return
+	}
+
+	message := l.format(sql, rows, elapsed, err)
+	switch {
+	case err != nil && l.level >= gormlogger.Error:
+		l.log.Errorf("%s", message)
+	case slow && l.level >= gormlogger.Warn:
+		l.log.Warnf("slow query: %s", message)
+	case l.level >= gormlogger.Info:
+		l.log.Infof("%s", message)
+	}
+}
+
+func (l *gormLogger) format(sql string, rows int64, elapsed time.Duration, err error) string {
+	if !l.json {
+		if err != nil {
+			return sql + " | " + elapsed.String() + " | " + err.Error()
+		}
+		return sql + " | " + elapsed.String()
+	}
+
+	entry := map[string]any{
+		"sql":         sql,
+		"rows":        rows,
+		"duration_ms": elapsed.Milliseconds(),
+	}
+	if err != nil {
+		entry["error"] = err.Error()
+	}
+	out, _ := json.Marshal(entry)
+	return string(out)
+}
+
+// suppressed matches the statement's target table against the
+// suppression list; gorm quotes table names in generated SQL.
+func (l *gormLogger) suppressed(sql string) bool {
+	for table := range l.suppressedTables {
+		if strings.Contains(sql, `"`+table+`"`) || strings.Contains(sql, " "+table+" ") {
+			return true
+		}
+	}
+	return false
+}
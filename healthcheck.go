@@ -0,0 +1,79 @@
+package duty
+
+import (
+	"time"
+
+	"github.com/flanksource/commons-db/context"
+)
+
+// HealthStatus is the structured result of Healthcheck, shaped for
+// /healthz handlers of services embedding this package.
+type HealthStatus struct {
+	Healthy     bool          `json:"healthy"`
+	PingLatency time.Duration `json:"pingLatency"`
+
+	// Migration is the path of the last applied migration script.
+	Migration string `json:"migration,omitempty"`
+
+	// ReplicationLag is the replica's delay behind the primary; zero
+	// when no replica is configured or this instance is the primary.
+	ReplicationLag time.Duration `json:"replicationLag,omitempty"`
+
+	Pool  PoolStatus `json:"pool"`
+	Error string     `json:"error,omitempty"`
+}
+
+// PoolStatus is a snapshot of pgx pool utilization.
+type PoolStatus struct {
+	Total    int32 `json:"total"`
+	Idle     int32 `json:"idle"`
+	Acquired int32 `json:"acquired"`
+	Max      int32 `json:"max"`
+}
+
+// Healthcheck pings the database and collects operational signals:
+// round-trip latency, the last applied migration, replication lag (when
+// queried on a replica) and connection pool utilization. A failed ping
+// returns Healthy=false with the error inline rather than an error
+// return, so handlers can always serialize the result.
+func Healthcheck(ctx context.Context) HealthStatus {
+	var status HealthStatus
+
+	pool := ctx.Pool()
+	if pool == nil {
+		status.Error = "no connection pool configured"
+		return status
+	}
+
+	started := time.Now()
+	if err := pool.Ping(ctx); err != nil {
+		status.Error = err.Error()
+		return status
+	}
+	status.PingLatency = time.Since(started)
+	status.Healthy = true
+
+	stats := pool.Stat()
+	status.Pool = PoolStatus{
+		Total:    stats.TotalConns(),
+		Idle:     stats.IdleConns(),
+		Acquired: stats.AcquiredConns(),
+		Max:      stats.MaxConns(),
+	}
+
+	if err := pool.QueryRow(ctx,
+		"SELECT path FROM migration_logs ORDER BY path DESC LIMIT 1").Scan(&status.Migration); err != nil {
+		ctx.Debugf("healthcheck: failed to read migration version: %v", err)
+	}
+
+	// non-zero only on a replica; on the primary pg_last_wal_replay_lsn
+	// is NULL and the query returns no lag
+	var lagSeconds *float64
+	if err := pool.QueryRow(ctx, `
+		SELECT EXTRACT(EPOCH FROM now() - pg_last_xact_replay_timestamp())
+		WHERE pg_is_in_recovery()`).Scan(&lagSeconds); err == nil && lagSeconds != nil {
+		status.ReplicationLag = time.Duration(*lagSeconds * float64(time.Second))
+	}
+
+	return status
+}
@@ -0,0 +1,106 @@
+package kubernetes
+
+import (
+	"fmt"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic"
+
+	"github.com/flanksource/commons-db/context"
+)
+
+// fieldManager identifies this client in server-side apply managed
+// fields.
+const fieldManager = "commons-db"
+
+// applySetLabel marks objects as members of a named apply set, the
+// bookkeeping behind Prune.
+const applySetLabel = "commons-db/apply-set"
+
+// ApplyOption tunes a single Apply call.
+type ApplyOption func(*applyOptions)
+
+type applyOptions struct {
+	dryRun   bool
+	applySet string
+}
+
+// WithDryRun applies server-side without persisting; the returned
+// object is what the server would have stored.
+func WithDryRun() ApplyOption {
+	return func(o *applyOptions) { o.dryRun = true }
+}
+
+// WithApplySet labels the object as a member of a named apply set, so a
+// later Prune of the same set can delete members that are no longer
+// applied.
+func WithApplySet(name string) ApplyOption {
+	return func(o *applyOptions) { o.applySet = name }
+}
+
+// Apply server-side-applies the object and returns the live result.
+func (c *Client) Apply(ctx context.Context, obj *unstructured.Unstructured, opts ...ApplyOption) (*unstructured.Unstructured, error) {
+	var options applyOptions
+	for _, opt := range opts {
+		opt(&options)
+	}
+
+	if options.applySet != "" {
+		obj = obj.DeepCopy()
+		labels := obj.GetLabels()
+		if labels == nil {
+			labels = map[string]string{}
+		}
+		labels[applySetLabel] = options.applySet
+		obj.SetLabels(labels)
+	}
+
+	client, err := c.resourceInterface(obj)
+	if err != nil {
+		return nil, err
+	}
+
+	applyOptions := metav1.ApplyOptions{
+		FieldManager: fieldManager,
+		Force:        true,
+	}
+	if options.dryRun {
+		applyOptions.DryRun = []string{metav1.DryRunAll}
+	}
+
+	applied, err := client.Apply(ctx, obj.GetName(), obj, applyOptions)
+	if err != nil {
+		return nil, WrapError(err, obj.GroupVersionKind(), obj.GetNamespace(), obj.GetName(), "apply")
+	}
+	return applied, nil
+}
+
+// resourceInterface resolves the namespaced dynamic client interface for
+// an object.
+func (c *Client) resourceInterface(obj *unstructured.Unstructured) (dynamic.ResourceInterface, error) {
+	gvr, namespaced, err := c.gvrFor(obj)
+	if err != nil {
+		return nil, err
+	}
+	if namespaced {
+		return c.dynamicClient.Resource(gvr).Namespace(obj.GetNamespace()), nil
+	}
+	return c.dynamicClient.Resource(gvr), nil
+}
+
+// gvrFor resolves the GroupVersionResource for an object's GVK.
+func (c *Client) gvrFor(obj *unstructured.Unstructured) (schema.GroupVersionResource, bool, error) {
+	mapper, err := c.GetRestMapper()
+	if err != nil {
+		return schema.GroupVersionResource{}, false, err
+	}
+
+	gvk := obj.GroupVersionKind()
+	mapping, err := mapper.RESTMapping(gvk.GroupKind(), gvk.Version)
+	if err != nil {
+		return schema.GroupVersionResource{}, false, fmt.Errorf("failed to resolve %s: %w", gvk, err)
+	}
+	return mapping.Resource, mapping.Scope.Name() == "namespace", nil
+}
@@ -0,0 +1,167 @@
+package kubernetes
+
+import (
+	"fmt"
+	"time"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+
+	"github.com/flanksource/commons-db/context"
+)
+
+// ApplyAndWaitOptions controls ApplyAndWait.
+type ApplyAndWaitOptions struct {
+	// Timeout bounds the wait for all resources to become healthy
+	// (default 5m).
+	Timeout time.Duration
+
+	// RollbackOnFailure restores the pre-apply state (or deletes
+	// resources that didn't exist before) when any resource fails to
+	// become healthy.
+	RollbackOnFailure bool
+}
+
+// ApplyResult is the per-resource outcome of ApplyAndWait.
+type ApplyResult struct {
+	Kind       string `json:"kind"`
+	Namespace  string `json:"namespace,omitempty"`
+	Name       string `json:"name"`
+	Applied    bool   `json:"applied"`
+	Health     Health `json:"health"`
+	RolledBack bool   `json:"rolledBack,omitempty"`
+	Error      string `json:"error,omitempty"`
+}
+
+// ApplyAndWait applies the resources, waits for them to become healthy
+// (using CheckHealth), and — when RollbackOnFailure is set — reverts to
+// the previously captured state if they don't. It returns a per-resource
+// report alongside the overall error.
+func (c *Client) ApplyAndWait(ctx context.Context, resources []unstructured.Unstructured, opts ApplyAndWaitOptions) ([]ApplyResult, error) {
+	if opts.Timeout <= 0 {
+		opts.Timeout = 5 * time.Minute
+	}
+
+	report := make([]ApplyResult, len(resources))
+	// previous[i] is the live object before apply, nil when it did not
+	// exist
+	previous := make([]*unstructured.Unstructured, len(resources))
+
+	for i := range resources {
+		obj := &resources[i]
+		report[i] = ApplyResult{Kind: obj.GetKind(), Namespace: obj.GetNamespace(), Name: obj.GetName()}
+
+		client, err := c.resourceInterface(obj)
+		if err != nil {
+			report[i].Error = err.Error()
+			return report, err
+		}
+
+		if live, err := client.Get(ctx, obj.GetName(), metav1.GetOptions{}); err == nil {
+			live.SetManagedFields(nil)
+			previous[i] = live
+		} else if !apierrors.IsNotFound(err) {
+			report[i].Error = err.Error()
+			return report, err
+		}
+
+		if _, err := c.Apply(ctx, obj); err != nil {
+			report[i].Error = err.Error()
+			if opts.RollbackOnFailure {
+				c.rollback(ctx, resources[:i+1], previous, report)
+			}
+			return report, err
+		}
+		report[i].Applied = true
+	}
+
+	failed := c.waitForHealthy(ctx, resources, report, opts.Timeout)
+	if len(failed) == 0 {
+		return report, nil
+	}
+
+	err := fmt.Errorf("%d of %d resources did not become healthy within %s", len(failed), len(resources), opts.Timeout)
+	if opts.RollbackOnFailure {
+		c.rollback(ctx, resources, previous, report)
+	}
+	return report, err
+}
+
+// waitForHealthy polls all resources until healthy or timeout, updating
+// the report in place; it returns the indexes that never became healthy.
+func (c *Client) waitForHealthy(ctx context.Context, resources []unstructured.Unstructured, report []ApplyResult, timeout time.Duration) []int {
+	deadline := time.Now().Add(timeout)
+	pending := map[int]struct{}{}
+	for i := range resources {
+		pending[i] = struct{}{}
+	}
+
+	for len(pending) > 0 && time.Now().Before(deadline) {
+		for i := range pending {
+			obj := &resources[i]
+			client, err := c.resourceInterface(obj)
+			if err != nil {
+				continue
+			}
+			live, err := client.Get(ctx, obj.GetName(), metav1.GetOptions{})
+			if err != nil {
+				report[i].Health = Health{Status: "Error", Message: err.Error()}
+				continue
+			}
+
+			health := CheckHealth(live)
+			report[i].Health = health
+			if health.Healthy {
+				delete(pending, i)
+			}
+		}
+
+		if len(pending) == 0 {
+			break
+		}
+		select {
+		case <-ctx.Done():
+			return keys(pending)
+		case <-time.After(2 * time.Second):
+		}
+	}
+	return keys(pending)
+}
+
+// rollback restores every applied resource to its captured state:
+// resources that existed before are re-applied from the snapshot,
+// resources created by this call are deleted.
+func (c *Client) rollback(ctx context.Context, applied []unstructured.Unstructured, previous []*unstructured.Unstructured, report []ApplyResult) {
+	for i := range applied {
+		if !report[i].Applied {
+			continue
+		}
+		obj := &applied[i]
+
+		if previous[i] != nil {
+			previous[i].SetResourceVersion("")
+			if _, err := c.Apply(ctx, previous[i]); err != nil {
+				ctx.Errorf("rollback of %s/%s failed: %v", obj.GetKind(), obj.GetName(), err)
+				continue
+			}
+		} else {
+			// through the guarded Delete so a deletion policy can veto
+			// the rollback and the veto is audited
+			err := c.Delete(ctx, obj.GroupVersionKind(), obj.GetNamespace(), obj.GetName())
+			if err != nil && !apierrors.IsNotFound(err) {
+				ctx.Errorf("rollback delete of %s/%s failed: %v", obj.GetKind(), obj.GetName(), err)
+				continue
+			}
+		}
+		report[i].RolledBack = true
+	}
+}
+
+func keys(m map[int]struct{}) []int {
+	out := make([]int, 0, len(m))
+	for k := range m {
+		out = append(out, k)
+	}
+	return out
+}
@@ -0,0 +1,176 @@
+package kubernetes
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+
+	"github.com/flanksource/commons-db/context"
+)
+
+// FieldDiff is one field that would change if an object were applied.
+type FieldDiff struct {
+	// Path is the dot-separated field path, e.g. "spec.replicas".
+	Path string `json:"path"`
+
+	// Live is the current value (nil when the apply adds the field);
+	// Applied is the value after apply (nil when it removes it).
+	Live    any `json:"live,omitempty"`
+	Applied any `json:"applied,omitempty"`
+}
+
+func (d FieldDiff) String() string {
+	switch {
+	case d.Live == nil:
+		return fmt.Sprintf("+ %s: %v", d.Path, d.Applied)
+	case d.Applied == nil:
+		return fmt.Sprintf("- %s: %v", d.Path, d.Live)
+	default:
+		return fmt.Sprintf("~ %s: %v -> %v", d.Path, d.Live, d.Applied)
+	}
+}
+
+// volatileFields are server-managed metadata that changes on every
+// write and would drown a diff in noise.
+var volatileFields = map[string]bool{
+	"metadata.managedFields":     true,
+	"metadata.resourceVersion":   true,
+	"metadata.generation":        true,
+	"metadata.uid":               true,
+	"metadata.creationTimestamp": true,
+	"status":                     true,
+}
+
+// Diff server-side dry-run-applies the object and returns the fields
+// that would change relative to the live object. A missing live object
+// diffs every applied field against nil.
+func (c *Client) Diff(ctx context.Context, obj *unstructured.Unstructured) ([]FieldDiff, error) {
+	client, err := c.resourceInterface(obj)
+	if err != nil {
+		return nil, err
+	}
+
+	live, err := client.Get(ctx, obj.GetName(), metav1.GetOptions{})
+	if err != nil {
+		if !apierrors.IsNotFound(err) {
+			return nil, WrapError(err, obj.GroupVersionKind(), obj.GetNamespace(), obj.GetName(), "get")
+		}
+		live = &unstructured.Unstructured{Object: map[string]any{}}
+	}
+
+	applied, err := c.Apply(ctx, obj, WithDryRun())
+	if err != nil {
+		return nil, err
+	}
+
+	diffs := diffValues("", live.Object, applied.Object)
+	sort.Slice(diffs, func(i, j int) bool { return diffs[i].Path < diffs[j].Path })
+	return diffs, nil
+}
+
+// diffValues walks both values in parallel and records leaves that
+// differ. Maps recurse; everything else compares as a whole.
+func diffValues(path string, live, applied any) []FieldDiff {
+	if volatileFields[path] {
+		return nil
+	}
+
+	liveMap, liveIsMap := live.(map[string]any)
+	appliedMap, appliedIsMap := applied.(map[string]any)
+	if liveIsMap && appliedIsMap {
+		var diffs []FieldDiff
+		for _, key := range unionKeys(liveMap, appliedMap) {
+			childPath := key
+			if path != "" {
+				childPath = path + "." + key
+			}
+
+			liveValue, inLive := liveMap[key]
+			appliedValue, inApplied := appliedMap[key]
+			switch {
+			case !inLive:
+				diffs = append(diffs, diffValues(childPath, nil, appliedValue)...)
+			case !inApplied:
+				diffs = append(diffs, diffValues(childPath, liveValue, nil)...)
+			default:
+				diffs = append(diffs, diffValues(childPath, liveValue, appliedValue)...)
+			}
+		}
+		return diffs
+	}
+
+	if equalValues(live, applied) {
+		return nil
+	}
+	return []FieldDiff{{Path: path, Live: live, Applied: applied}}
+}
+
+func unionKeys(a, b map[string]any) []string {
+	keys := make(map[string]bool, len(a)+len(b))
+	for key := range a {
+		keys[key] = true
+	}
+	for key := range b {
+		keys[key] = true
+	}
+
+	sorted := make([]string, 0, len(keys))
+	for key := range keys {
+		sorted = append(sorted, key)
+	}
+	sort.Strings(sorted)
+	return sorted
+}
+
+func equalValues(a, b any) bool {
+	return reflect.DeepEqual(a, b)
+}
+
+// Prune deletes the members of an apply set that are no longer applied:
+// every resource of the given kinds labeled with the set name and not
+// present in keep. Deletions go through the deletion guard like any
+// other Delete.
+func (c *Client) Prune(ctx context.Context, set string, kinds []string, keep []unstructured.Unstructured) error {
+	if set == "" {
+		return fmt.Errorf("apply set name is required")
+	}
+
+	kept := make(map[string]bool, len(keep))
+	for _, obj := range keep {
+		kept[pruneKey(obj.GetKind(), obj.GetNamespace(), obj.GetName())] = true
+	}
+
+	for _, kind := range kinds {
+		gvr, _, err := c.ResourceForKind(kind)
+		if err != nil {
+			return err
+		}
+
+		list, err := c.dynamicClient.Resource(gvr).List(ctx, metav1.ListOptions{
+			LabelSelector: fmt.Sprintf("%s=%s", applySetLabel, set),
+		})
+		if err != nil {
+			return WrapError(err, gvr.GroupVersion().WithKind(kind), "", "", "list")
+		}
+
+		for _, item := range list.Items {
+			if kept[pruneKey(item.GetKind(), item.GetNamespace(), item.GetName())] {
+				continue
+			}
+			ctx.Infof("pruning %s %s/%s from apply set %s", item.GetKind(), item.GetNamespace(), item.GetName(), set)
+			if err := c.Delete(ctx, item.GroupVersionKind(), item.GetNamespace(), item.GetName()); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func pruneKey(kind, namespace, name string) string {
+	return strings.Join([]string{kind, namespace, name}, "/")
+}
@@ -0,0 +1,156 @@
+package kubernetes
+
+import (
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	authenticationv1 "k8s.io/api/authentication/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/flanksource/commons-db/context"
+)
+
+// AuthInfo describes the credentials the client is currently using and
+// when they expire.
+type AuthInfo struct {
+	// Identity is the username the API server sees for our requests.
+	Identity string `json:"identity,omitempty"`
+
+	// TokenExpiry is the exp claim of the bearer token, when one is in
+	// use and carries an expiry.
+	TokenExpiry *time.Time `json:"tokenExpiry,omitempty"`
+
+	// CertificateExpiry is the notAfter of the client certificate,
+	// when certificate auth is in use.
+	CertificateExpiry *time.Time `json:"certificateExpiry,omitempty"`
+}
+
+// ExpiresWithin reports whether any credential expires within the
+// window, returning the earliest expiry.
+func (t AuthInfo) ExpiresWithin(window time.Duration) (bool, *time.Time) {
+	var earliest *time.Time
+	for _, expiry := range []*time.Time{t.TokenExpiry, t.CertificateExpiry} {
+		if expiry == nil {
+			continue
+		}
+		if earliest == nil || expiry.Before(*earliest) {
+			earliest = expiry
+		}
+	}
+	if earliest == nil {
+		return false, nil
+	}
+	return time.Until(*earliest) < window, earliest
+}
+
+// AuthInfo introspects the rest config's credentials: the identity in
+// use (via SelfSubjectReview), bearer token expiry, and client
+// certificate notAfter.
+func (c *Client) AuthInfo(ctx context.Context) (*AuthInfo, error) {
+	var info AuthInfo
+
+	review, err := c.AuthenticationV1().SelfSubjectReviews().
+		Create(ctx, &authenticationv1.SelfSubjectReview{}, metav1.CreateOptions{})
+	if err == nil {
+		info.Identity = review.Status.UserInfo.Username
+	} else {
+		ctx.Debugf("failed to resolve identity via SelfSubjectReview: %v", err)
+	}
+
+	token := c.Config.BearerToken
+	if token == "" && c.Config.BearerTokenFile != "" {
+		if b, err := os.ReadFile(c.Config.BearerTokenFile); err == nil {
+			token = strings.TrimSpace(string(b))
+		}
+	}
+	if token != "" {
+		if expiry, err := tokenExpiry(token); err == nil {
+			info.TokenExpiry = expiry
+		}
+	}
+
+	certData := c.Config.TLSClientConfig.CertData
+	if len(certData) == 0 && c.Config.TLSClientConfig.CertFile != "" {
+		certData, _ = os.ReadFile(c.Config.TLSClientConfig.CertFile)
+	}
+	if len(certData) > 0 {
+		if notAfter, err := certificateExpiry(certData); err == nil {
+			info.CertificateExpiry = &notAfter
+		} else {
+			ctx.Debugf("failed to parse client certificate: %v", err)
+		}
+	}
+
+	return &info, nil
+}
+
+// WarnOnCredentialExpiry periodically checks the credentials and logs a
+// warning when they are within the window of expiring, so agents don't
+// fail silently when a token or certificate lapses. The check interval
+// is window/4, bounded to [1m, 1h].
+func (c *Client) WarnOnCredentialExpiry(ctx context.Context, window time.Duration) {
+	interval := min(max(window/4, time.Minute), time.Hour)
+
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				info, err := c.AuthInfo(ctx)
+				if err != nil {
+					continue
+				}
+				if expiring, at := info.ExpiresWithin(window); expiring {
+					ctx.Warnf("kubernetes credentials for %s expire at %s (in %s)",
+						info.Identity, at.Format(time.RFC3339), time.Until(*at).Round(time.Minute))
+				}
+			}
+		}
+	}()
+}
+
+// tokenExpiry extracts the exp claim from a JWT without verifying it.
+func tokenExpiry(token string) (*time.Time, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return nil, fmt.Errorf("not a JWT")
+	}
+
+	payload, err := base64.RawURLEncoding.DecodeString(parts[1])
+	if err != nil {
+		return nil, err
+	}
+
+	var claims struct {
+		Exp int64 `json:"exp"`
+	}
+	if err := json.Unmarshal(payload, &claims); err != nil {
+		return nil, err
+	}
+	if claims.Exp == 0 {
+		return nil, nil
+	}
+	expiry := time.Unix(claims.Exp, 0)
+	return &expiry, nil
+}
+
+func certificateExpiry(pemData []byte) (time.Time, error) {
+	block, _ := pem.Decode(pemData)
+	if block == nil {
+		return time.Time{}, fmt.Errorf("no PEM block found")
+	}
+	cert, err := x509.ParseCertificate(block.Bytes)
+	if err != nil {
+		return time.Time{}, err
+	}
+	return cert.NotAfter, nil
+}
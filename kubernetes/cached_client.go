@@ -0,0 +1,171 @@
+package kubernetes
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic/dynamicinformer"
+	"k8s.io/client-go/informers"
+	"k8s.io/client-go/tools/cache"
+
+	"github.com/flanksource/commons-db/context"
+	"github.com/flanksource/commons-db/types"
+)
+
+// defaultCacheResync is the informer resync period; override with the
+// kubernetes.cache.resync property.
+const defaultCacheResync = 10 * time.Minute
+
+// CachedClient serves Get/List/QueryResources from shared informers
+// instead of the API server. Informers are started lazily per resource
+// on first use and kept in sync by their watches, so repeated selector
+// queries cost the API server nothing after the initial list. Writes
+// and everything else pass through to the embedded Client.
+type CachedClient struct {
+	*Client
+
+	factory dynamicinformer.DynamicSharedInformerFactory
+	stop    chan struct{}
+
+	mu        sync.Mutex
+	informers map[schema.GroupVersionResource]informers.GenericInformer
+}
+
+// Cached wraps the client with an informer-backed cache. Call Stop when
+// done to release the watches.
+func (c *Client) Cached(ctx context.Context) *CachedClient {
+	resync := ctx.Properties().Duration("kubernetes.cache.resync", defaultCacheResync)
+	return &CachedClient{
+		Client:    c,
+		factory:   dynamicinformer.NewDynamicSharedInformerFactory(c.dynamicClient, resync),
+		stop:      make(chan struct{}),
+		informers: map[schema.GroupVersionResource]informers.GenericInformer{},
+	}
+}
+
+// Stop shuts down all informers and their watches.
+func (c *CachedClient) Stop() {
+	close(c.stop)
+	c.factory.Shutdown()
+}
+
+// listerFor returns a synced lister for the resource, starting its
+// informer on first use.
+func (c *CachedClient) listerFor(ctx context.Context, gvr schema.GroupVersionResource) (cache.GenericLister, error) {
+	c.mu.Lock()
+	informer, ok := c.informers[gvr]
+	if !ok {
+		informer = c.factory.ForResource(gvr)
+		c.informers[gvr] = informer
+		c.factory.Start(c.stop)
+	}
+	c.mu.Unlock()
+
+	if !cache.WaitForCacheSync(ctx.Done(), informer.Informer().HasSynced) {
+		return nil, fmt.Errorf("gave up waiting for %s informer to sync", gvr.Resource)
+	}
+	return informer.Lister(), nil
+}
+
+// Get returns a resource from the cache.
+func (c *CachedClient) Get(ctx context.Context, gvk schema.GroupVersionKind, namespace, name string) (*unstructured.Unstructured, error) {
+	gvr, namespaced, err := c.ResourceForKind(gvk.Kind)
+	if err != nil {
+		return nil, err
+	}
+
+	lister, err := c.listerFor(ctx, gvr)
+	if err != nil {
+		return nil, err
+	}
+
+	var obj any
+	if namespaced {
+		obj, err = lister.ByNamespace(namespace).Get(name)
+	} else {
+		obj, err = lister.Get(name)
+	}
+	if err != nil {
+		return nil, WrapError(err, gvk, namespace, name, "get")
+	}
+
+	// copy so callers can't mutate the shared cache
+	return obj.(*unstructured.Unstructured).DeepCopy(), nil
+}
+
+// List returns the cached resources of a kind, optionally restricted to
+// a namespace.
+func (c *CachedClient) List(ctx context.Context, gvk schema.GroupVersionKind, namespace string) ([]unstructured.Unstructured, error) {
+	return c.list(ctx, gvk.Kind, namespace, labels.Everything(), "")
+}
+
+// QueryResources answers a selector query from the cache. Field
+// selectors can't be evaluated against informers, so selectors using
+// them fall through to the live API server.
+func (c *CachedClient) QueryResources(ctx context.Context, selector types.ResourceSelector, opts ...QueryOption) ([]unstructured.Unstructured, error) {
+	if selector.FieldSelector != "" {
+		return c.Client.QueryResources(ctx, selector, opts...)
+	}
+
+	kinds := selectorKinds(selector)
+	if len(kinds) == 0 {
+		return nil, fmt.Errorf("resource selector has no types to query")
+	}
+
+	labelSelector := labels.Everything()
+	if selector.LabelSelector != "" {
+		var err error
+		if labelSelector, err = labels.Parse(selector.LabelSelector); err != nil {
+			return nil, fmt.Errorf("invalid label selector %q: %w", selector.LabelSelector, err)
+		}
+	}
+
+	var results []unstructured.Unstructured
+	for _, kind := range kinds {
+		for _, namespace := range strings.Split(selector.Namespace, ",") {
+			matched, err := c.list(ctx, kind, strings.TrimSpace(namespace), labelSelector, selector.Name)
+			if err != nil {
+				return nil, err
+			}
+			results = append(results, matched...)
+		}
+	}
+
+	sortResources(results)
+	return results, nil
+}
+
+func (c *CachedClient) list(ctx context.Context, kind, namespace string, labelSelector labels.Selector, namePattern string) ([]unstructured.Unstructured, error) {
+	gvr, namespaced, err := c.ResourceForKind(kind)
+	if err != nil {
+		return nil, err
+	}
+
+	lister, err := c.listerFor(ctx, gvr)
+	if err != nil {
+		return nil, err
+	}
+
+	scoped := lister.List
+	if namespaced && namespace != "" {
+		scoped = lister.ByNamespace(namespace).List
+	}
+	objects, err := scoped(labelSelector)
+	if err != nil {
+		return nil, WrapError(err, gvr.GroupVersion().WithKind(kind), namespace, "", "list")
+	}
+
+	results := make([]unstructured.Unstructured, 0, len(objects))
+	for _, obj := range objects {
+		item := obj.(*unstructured.Unstructured)
+		if matchesName(namePattern, item.GetName()) {
+			results = append(results, *item.DeepCopy())
+		}
+	}
+	return results, nil
+}
@@ -0,0 +1,94 @@
+// Package kubernetes wraps client-go with the conveniences this
+// ecosystem needs: selector-driven queries, health evaluation, and
+// resilient transport defaults.
+package kubernetes
+
+import (
+	"fmt"
+	"sync"
+
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/metadata"
+	"k8s.io/client-go/rest"
+)
+
+// Client bundles the typed and dynamic clients for a single cluster.
+type Client struct {
+	kubernetes.Interface
+	Config *rest.Config
+
+	dynamicClient  dynamic.Interface
+	metadataClient metadata.Interface
+
+	// discovery cache settings; see WithDiscoveryCacheDir and
+	// WithInMemoryDiscovery
+	discoveryCacheDir string
+	inMemoryDiscovery bool
+	contextName       string
+
+	// deletion policy hook and its audit log; see WithDeletionGuard
+	deletionGuard DeletionGuard
+	vetoMu        sync.Mutex
+	vetoed        []VetoedDeletion
+}
+
+// WithDiscoveryCacheDir overrides the on-disk discovery cache location
+// (default $HOME/.kube/cache/discovery).
+func (c *Client) WithDiscoveryCacheDir(dir string) *Client {
+	c.discoveryCacheDir = dir
+	return c
+}
+
+// WithInMemoryDiscovery switches discovery caching to memory only, for
+// containers with read-only filesystems.
+func (c *Client) WithInMemoryDiscovery() *Client {
+	c.inMemoryDiscovery = true
+	return c
+}
+
+// WithContextName tags the client with its kubeconfig context so
+// discovery caches of different contexts pointing at the same host
+// don't collide.
+func (c *Client) WithContextName(name string) *Client {
+	c.contextName = name
+	return c
+}
+
+// ClientOption mutates the rest config before the clients are built.
+type ClientOption func(*rest.Config)
+
+// NewClient creates a Client from a rest config.
+func NewClient(config *rest.Config, opts ...ClientOption) (*Client, error) {
+	config = rest.CopyConfig(config)
+	for _, opt := range opts {
+		opt(config)
+	}
+
+	clientset, err := kubernetes.NewForConfig(config)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create clientset: %w", err)
+	}
+
+	dynamicClient, err := dynamic.NewForConfig(config)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create dynamic client: %w", err)
+	}
+
+	metadataClient, err := metadata.NewForConfig(config)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create metadata client: %w", err)
+	}
+
+	return &Client{
+		Interface:      clientset,
+		Config:         config,
+		dynamicClient:  dynamicClient,
+		metadataClient: metadataClient,
+	}, nil
+}
+
+// Dynamic returns the dynamic client.
+func (c *Client) Dynamic() dynamic.Interface {
+	return c.dynamicClient
+}
@@ -0,0 +1,185 @@
+package kubernetes
+
+import (
+	"archive/tar"
+	"bytes"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/tools/remotecommand"
+
+	"github.com/flanksource/commons-db/context"
+)
+
+// CopyToPod copies a local file or directory into remoteDir inside the
+// pod's container (kubectl cp semantics: tar piped over exec, so the
+// container needs a tar binary). remoteDir must exist.
+func (c *Client) CopyToPod(ctx context.Context, namespace, pod, container, localPath, remoteDir string) error {
+	reader, writer := io.Pipe()
+	go func() {
+		writer.CloseWithError(tarLocal(writer, localPath))
+	}()
+
+	var stderr bytes.Buffer
+	err := c.execInPod(ctx, namespace, pod, container, []string{"tar", "-xmf", "-", "-C", remoteDir}, reader, nil, &stderr)
+	_ = reader.Close()
+	if err != nil {
+		return fmt.Errorf("failed to copy %s to %s/%s:%s: %w%s", localPath, namespace, pod, remoteDir, err, stderrSuffix(&stderr))
+	}
+	return nil
+}
+
+// CopyFromPod copies a file or directory out of the pod's container into
+// localDir, pulling a tar stream over exec like kubectl cp.
+func (c *Client) CopyFromPod(ctx context.Context, namespace, pod, container, remotePath, localDir string) error {
+	reader, writer := io.Pipe()
+
+	var stderr bytes.Buffer
+	done := make(chan error, 1)
+	go func() {
+		err := c.execInPod(ctx, namespace, pod, container,
+			[]string{"tar", "-cf", "-", "-C", filepath.Dir(remotePath), filepath.Base(remotePath)},
+			nil, writer, &stderr)
+		writer.CloseWithError(err)
+		done <- err
+	}()
+
+	untarErr := untar(reader, localDir)
+	_ = reader.Close()
+	execErr := <-done
+
+	if execErr != nil {
+		return fmt.Errorf("failed to copy %s/%s:%s: %w%s", namespace, pod, remotePath, execErr, stderrSuffix(&stderr))
+	}
+	if untarErr != nil {
+		return fmt.Errorf("failed to extract %s into %s: %w", remotePath, localDir, untarErr)
+	}
+	return nil
+}
+
+// execInPod runs a command in a pod container over SPDY with the given
+// streams; any of them may be nil.
+func (c *Client) execInPod(ctx context.Context, namespace, pod, container string, command []string, stdin io.Reader, stdout, stderr io.Writer) error {
+	req := c.CoreV1().RESTClient().Post().
+		Resource("pods").Namespace(namespace).Name(pod).
+		SubResource("exec").
+		VersionedParams(&corev1.PodExecOptions{
+			Container: container,
+			Command:   command,
+			Stdin:     stdin != nil,
+			Stdout:    stdout != nil,
+			Stderr:    stderr != nil,
+		}, scheme.ParameterCodec)
+
+	executor, err := remotecommand.NewSPDYExecutor(c.Config, http.MethodPost, req.URL())
+	if err != nil {
+		return fmt.Errorf("failed to create executor: %w", err)
+	}
+	return executor.StreamWithContext(ctx, remotecommand.StreamOptions{
+		Stdin:  stdin,
+		Stdout: stdout,
+		Stderr: stderr,
+	})
+}
+
+// tarLocal writes path (a file or directory) as a tar stream, with entry
+// names relative to the path's parent so the receiver unpacks a single
+// file or a single directory tree.
+func tarLocal(w io.Writer, path string) error {
+	tw := tar.NewWriter(w)
+	base := filepath.Dir(path)
+
+	err := filepath.Walk(path, func(file string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if !info.Mode().IsRegular() && !info.IsDir() {
+			return nil // sockets, devices, symlinks
+		}
+
+		name, err := filepath.Rel(base, file)
+		if err != nil {
+			return err
+		}
+		header, err := tar.FileInfoHeader(info, "")
+		if err != nil {
+			return err
+		}
+		header.Name = filepath.ToSlash(name)
+		if err := tw.WriteHeader(header); err != nil {
+			return err
+		}
+		if info.IsDir() {
+			return nil
+		}
+
+		f, err := os.Open(file)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		_, err = io.Copy(tw, f)
+		return err
+	})
+	if err != nil {
+		return err
+	}
+	return tw.Close()
+}
+
+// untar extracts a tar stream into dir, rejecting entries that would
+// escape it.
+func untar(r io.Reader, dir string) error {
+	tr := tar.NewReader(r)
+	for {
+		header, err := tr.Next()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		target := filepath.Join(dir, filepath.Clean(header.Name))
+		if !strings.HasPrefix(target, filepath.Clean(dir)+string(os.PathSeparator)) {
+			return fmt.Errorf("tar entry %q escapes destination directory", header.Name)
+		}
+
+		switch header.Typeflag {
+		case tar.TypeDir:
+			if err := os.MkdirAll(target, os.FileMode(header.Mode)); err != nil {
+				return err
+			}
+		case tar.TypeReg:
+			if err := os.MkdirAll(filepath.Dir(target), 0o755); err != nil {
+				return err
+			}
+			f, err := os.OpenFile(target, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, os.FileMode(header.Mode))
+			if err != nil {
+				return err
+			}
+			if _, err := io.Copy(f, tr); err != nil {
+				_ = f.Close()
+				return err
+			}
+			if err := f.Close(); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// stderrSuffix renders captured stderr for inclusion in an error, empty
+// when nothing was written.
+func stderrSuffix(buf *bytes.Buffer) string {
+	if out := strings.TrimSpace(buf.String()); out != "" {
+		return ": " + out
+	}
+	return ""
+}
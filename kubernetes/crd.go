@@ -0,0 +1,184 @@
+package kubernetes
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/flanksource/commons-db/context"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+var crdGVR = schema.GroupVersionResource{
+	Group:    "apiextensions.k8s.io",
+	Version:  "v1",
+	Resource: "customresourcedefinitions",
+}
+
+// InstallCRDOptions controls InstallCRDs behavior.
+type InstallCRDOptions struct {
+	// Timeout bounds the wait for each CRD to become established
+	// (default 2m).
+	Timeout time.Duration
+
+	// CheckConversionWebhook also verifies that the conversion webhook
+	// service (when configured) resolves and accepts connections.
+	CheckConversionWebhook bool
+}
+
+// InstallCRDs applies the given CRDs and waits until each reports
+// Established and NamesAccepted, validating that every declared version
+// is served or storage. Existing CRDs are updated in place.
+func (c *Client) InstallCRDs(ctx context.Context, crds []unstructured.Unstructured, opts InstallCRDOptions) error {
+	if opts.Timeout <= 0 {
+		opts.Timeout = 2 * time.Minute
+	}
+
+	client := c.dynamicClient.Resource(crdGVR)
+	for i := range crds {
+		crd := &crds[i]
+		existing, err := client.Get(ctx, crd.GetName(), metav1.GetOptions{})
+		switch {
+		case apierrors.IsNotFound(err):
+			if _, err := client.Create(ctx, crd, metav1.CreateOptions{}); err != nil {
+				return fmt.Errorf("failed to create CRD %s: %w", crd.GetName(), err)
+			}
+		case err != nil:
+			return fmt.Errorf("failed to get CRD %s: %w", crd.GetName(), err)
+		default:
+			crd.SetResourceVersion(existing.GetResourceVersion())
+			if _, err := client.Update(ctx, crd, metav1.UpdateOptions{}); err != nil {
+				return fmt.Errorf("failed to update CRD %s: %w", crd.GetName(), err)
+			}
+		}
+	}
+
+	for i := range crds {
+		if err := c.waitForCRD(ctx, crds[i].GetName(), opts); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// waitForCRD polls the CRD until Established and NamesAccepted are True.
+func (c *Client) waitForCRD(ctx context.Context, name string, opts InstallCRDOptions) error {
+	ctx, cancel := ctx.WithTimeout(opts.Timeout)
+	defer cancel()
+
+	client := c.dynamicClient.Resource(crdGVR)
+	for {
+		crd, err := client.Get(ctx, name, metav1.GetOptions{})
+		if err == nil {
+			established, namesAccepted, reason := crdConditions(crd)
+			if established && namesAccepted {
+				if err := validateCRDVersions(crd); err != nil {
+					return err
+				}
+				if opts.CheckConversionWebhook {
+					if err := c.checkConversionWebhook(ctx, crd); err != nil {
+						return err
+					}
+				}
+				return nil
+			}
+			if reason != "" {
+				ctx.Tracef("waiting for CRD %s: %s", name, reason)
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("timed out waiting for CRD %s to become established: %w", name, ctx.Err())
+		case <-time.After(time.Second):
+		}
+	}
+}
+
+func crdConditions(crd *unstructured.Unstructured) (established, namesAccepted bool, reason string) {
+	conditions, _, _ := unstructured.NestedSlice(crd.Object, "status", "conditions")
+	for _, c := range conditions {
+		condition, ok := c.(map[string]any)
+		if !ok {
+			continue
+		}
+		conditionType, _ := condition["type"].(string)
+		status, _ := condition["status"].(string)
+		switch conditionType {
+		case "Established":
+			established = status == "True"
+		case "NamesAccepted":
+			namesAccepted = status == "True"
+			if !namesAccepted {
+				reason, _ = condition["message"].(string)
+			}
+		}
+	}
+	return established, namesAccepted, reason
+}
+
+// validateCRDVersions checks that exactly one version is the storage
+// version and at least one is served.
+func validateCRDVersions(crd *unstructured.Unstructured) error {
+	versions, _, _ := unstructured.NestedSlice(crd.Object, "spec", "versions")
+
+	var storage, served int
+	for _, v := range versions {
+		version, ok := v.(map[string]any)
+		if !ok {
+			continue
+		}
+		if isStorage, _ := version["storage"].(bool); isStorage {
+			storage++
+		}
+		if isServed, _ := version["served"].(bool); isServed {
+			served++
+		}
+	}
+	if storage != 1 {
+		return fmt.Errorf("CRD %s has %d storage versions, expected exactly 1", crd.GetName(), storage)
+	}
+	if served == 0 {
+		return fmt.Errorf("CRD %s has no served versions", crd.GetName())
+	}
+	return nil
+}
+
+// checkConversionWebhook verifies the conversion webhook service (when
+// one is configured) exists and has ready endpoints.
+func (c *Client) checkConversionWebhook(ctx context.Context, crd *unstructured.Unstructured) error {
+	strategy, _, _ := unstructured.NestedString(crd.Object, "spec", "conversion", "strategy")
+	if strategy != "Webhook" {
+		return nil
+	}
+
+	namespace, _, _ := unstructured.NestedString(crd.Object, "spec", "conversion", "webhook", "clientConfig", "service", "namespace")
+	name, _, _ := unstructured.NestedString(crd.Object, "spec", "conversion", "webhook", "clientConfig", "service", "name")
+	url, _, _ := unstructured.NestedString(crd.Object, "spec", "conversion", "webhook", "clientConfig", "url")
+
+	if url != "" {
+		resp, err := http.Head(url) //nolint:gosec,noctx
+		if err != nil {
+			return fmt.Errorf("CRD %s conversion webhook %s is unreachable: %w", crd.GetName(), url, err)
+		}
+		_ = resp.Body.Close()
+		return nil
+	}
+	if name == "" {
+		return nil
+	}
+
+	endpoints, err := c.CoreV1().Endpoints(namespace).Get(ctx, name, metav1.GetOptions{})
+	if err != nil {
+		return fmt.Errorf("CRD %s conversion webhook service %s/%s: %w", crd.GetName(), namespace, name, err)
+	}
+	for _, subset := range endpoints.Subsets {
+		if len(subset.Addresses) > 0 {
+			return nil
+		}
+	}
+	return fmt.Errorf("CRD %s conversion webhook service %s/%s has no ready endpoints", crd.GetName(), namespace, name)
+}
@@ -0,0 +1,98 @@
+package kubernetes
+
+import (
+	"fmt"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+
+	"github.com/flanksource/commons-db/context"
+)
+
+// DeletionRequest describes a deletion about to happen, passed to the
+// guard for a verdict.
+type DeletionRequest struct {
+	GVK       schema.GroupVersionKind
+	Namespace string
+	Name      string
+
+	// Labels of the live object, when it could be fetched.
+	Labels map[string]string
+}
+
+// DeletionGuard decides whether a deletion may proceed. Returning an
+// error vetoes it; the error message is surfaced to the caller and
+// recorded in the audit log.
+type DeletionGuard func(ctx context.Context, req DeletionRequest) error
+
+// VetoedDeletion is an audit record of a deletion blocked by the guard.
+type VetoedDeletion struct {
+	Request DeletionRequest
+	Reason  string
+	At      time.Time
+}
+
+// WithDeletionGuard installs a policy hook consulted before every
+// deletion issued through this client. Typical guards protect
+// namespaces (kube-system, flux-system) or resources labeled
+// protection/do-not-delete.
+func (c *Client) WithDeletionGuard(guard DeletionGuard) *Client {
+	c.deletionGuard = guard
+	return c
+}
+
+// VetoedDeletions returns a copy of the audit log of deletions the
+// guard blocked on this client.
+func (c *Client) VetoedDeletions() []VetoedDeletion {
+	c.vetoMu.Lock()
+	defer c.vetoMu.Unlock()
+	return append([]VetoedDeletion(nil), c.vetoed...)
+}
+
+// guardDeletion consults the guard (when installed) and records vetoes.
+// The object's labels are fetched best-effort so label-based policies
+// work; a missing object passes through to the delete, which will
+// surface NotFound itself.
+func (c *Client) guardDeletion(ctx context.Context, gvk schema.GroupVersionKind, namespace, name string) error {
+	if c.deletionGuard == nil {
+		return nil
+	}
+
+	req := DeletionRequest{GVK: gvk, Namespace: namespace, Name: name}
+	if gvr, _, err := c.ResourceForKind(gvk.Kind); err == nil {
+		if live, err := c.dynamicClient.Resource(gvr).Namespace(namespace).Get(ctx, name, metav1.GetOptions{}); err == nil {
+			req.Labels = live.GetLabels()
+		}
+	}
+
+	if err := c.deletionGuard(ctx, req); err != nil {
+		c.vetoMu.Lock()
+		c.vetoed = append(c.vetoed, VetoedDeletion{Request: req, Reason: err.Error(), At: time.Now()})
+		c.vetoMu.Unlock()
+
+		ctx.Warnf("deletion of %s %s/%s vetoed: %v", gvk.Kind, namespace, name, err)
+		return fmt.Errorf("deletion of %s %s/%s vetoed by policy: %w", gvk.Kind, namespace, name, err)
+	}
+	return nil
+}
+
+// Delete removes a resource after consulting the deletion guard.
+func (c *Client) Delete(ctx context.Context, gvk schema.GroupVersionKind, namespace, name string) error {
+	if err := c.guardDeletion(ctx, gvk, namespace, name); err != nil {
+		return err
+	}
+
+	gvr, namespaced, err := c.ResourceForKind(gvk.Kind)
+	if err != nil {
+		return err
+	}
+	if !namespaced {
+		namespace = ""
+	}
+
+	if err := c.dynamicClient.Resource(gvr).Namespace(namespace).Delete(ctx, name, metav1.DeleteOptions{}); err != nil {
+		return WrapError(err, gvk, namespace, name, "delete")
+	}
+	return nil
+}
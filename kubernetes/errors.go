@@ -0,0 +1,109 @@
+package kubernetes
+
+import (
+	gocontext "context"
+	"errors"
+	"fmt"
+	"strings"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+// ErrorKind categorizes API errors so callers can branch on error kinds
+// instead of string matching.
+type ErrorKind string
+
+const (
+	ErrNotFound      ErrorKind = "NotFound"
+	ErrForbidden     ErrorKind = "Forbidden"
+	ErrConflict      ErrorKind = "Conflict"
+	ErrThrottled     ErrorKind = "Throttled"
+	ErrWebhookDenied ErrorKind = "WebhookDenied"
+	ErrTimeout       ErrorKind = "Timeout"
+	ErrUnknown       ErrorKind = "Unknown"
+)
+
+// Error wraps an API error with its category and the operation
+// metadata.
+type Error struct {
+	Kind ErrorKind
+
+	GVK       schema.GroupVersionKind
+	Namespace string
+	Name      string
+	Verb      string
+
+	Err error
+}
+
+func (e *Error) Error() string {
+	target := e.Name
+	if e.Namespace != "" {
+		target = e.Namespace + "/" + e.Name
+	}
+	return fmt.Sprintf("%s %s %s: %s: %v", e.Verb, e.GVK.Kind, target, e.Kind, e.Err)
+}
+
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+// KindOf returns the category of any error, unwrapping as needed.
+// Non-kubernetes errors return ErrUnknown.
+func KindOf(err error) ErrorKind {
+	var kerr *Error
+	if errors.As(err, &kerr) {
+		return kerr.Kind
+	}
+	return categorize(err)
+}
+
+// IsKind reports whether the error belongs to the given category.
+func IsKind(err error, kind ErrorKind) bool {
+	return KindOf(err) == kind
+}
+
+// WrapError categorizes an API error and attaches the operation
+// metadata. A nil error returns nil.
+func WrapError(err error, gvk schema.GroupVersionKind, namespace, name, verb string) error {
+	if err == nil {
+		return nil
+	}
+	return &Error{
+		Kind:      categorize(err),
+		GVK:       gvk,
+		Namespace: namespace,
+		Name:      name,
+		Verb:      verb,
+		Err:       err,
+	}
+}
+
+func categorize(err error) ErrorKind {
+	var throttled *ThrottledError
+	switch {
+	case apierrors.IsNotFound(err):
+		return ErrNotFound
+	case isWebhookDenied(err): // webhook denials surface as Forbidden
+		return ErrWebhookDenied
+	case apierrors.IsForbidden(err) || apierrors.IsUnauthorized(err):
+		return ErrForbidden
+	case apierrors.IsConflict(err) || apierrors.IsAlreadyExists(err):
+		return ErrConflict
+	case apierrors.IsTooManyRequests(err) || errors.As(err, &throttled):
+		return ErrThrottled
+	case apierrors.IsTimeout(err) || apierrors.IsServerTimeout(err) || errors.Is(err, gocontext.DeadlineExceeded):
+		return ErrTimeout
+	default:
+		return ErrUnknown
+	}
+}
+
+// isWebhookDenied detects admission webhook denials, which the API
+// server reports as a generic Forbidden/BadRequest with a
+// distinguishable message.
+func isWebhookDenied(err error) bool {
+	msg := err.Error()
+	return strings.Contains(msg, "admission webhook") && strings.Contains(msg, "denied")
+}
@@ -0,0 +1,71 @@
+package kubernetes
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+
+	"github.com/flanksource/commons-db/context"
+)
+
+// Event is a condensed corev1 Event correlated to a resource.
+type Event struct {
+	Type     string    `json:"type"`
+	Reason   string    `json:"reason"`
+	Message  string    `json:"message"`
+	Count    int32     `json:"count,omitempty"`
+	LastSeen time.Time `json:"lastSeen,omitempty"`
+}
+
+func (e Event) String() string {
+	return fmt.Sprintf("%s/%s: %s", e.Type, e.Reason, e.Message)
+}
+
+// GetEvents fetches the events recorded against the resource, most
+// recent last, so callers can see the actual scheduling or image-pull
+// errors behind a stuck resource.
+func (c *Client) GetEvents(ctx context.Context, obj *unstructured.Unstructured) ([]Event, error) {
+	list, err := c.CoreV1().Events(obj.GetNamespace()).List(ctx, metav1.ListOptions{
+		FieldSelector: fmt.Sprintf("involvedObject.name=%s,involvedObject.kind=%s", obj.GetName(), obj.GetKind()),
+	})
+	if err != nil {
+		return nil, WrapError(err, obj.GroupVersionKind(), obj.GetNamespace(), obj.GetName(), "list events")
+	}
+
+	events := make([]Event, 0, len(list.Items))
+	for _, item := range list.Items {
+		lastSeen := item.LastTimestamp.Time
+		if lastSeen.IsZero() {
+			lastSeen = item.EventTime.Time
+		}
+		events = append(events, Event{
+			Type:     item.Type,
+			Reason:   item.Reason,
+			Message:  item.Message,
+			Count:    item.Count,
+			LastSeen: lastSeen,
+		})
+	}
+	sort.Slice(events, func(i, j int) bool { return events[i].LastSeen.Before(events[j].LastSeen) })
+	return events, nil
+}
+
+// WithEvents returns the health enriched with the resource's warning
+// events, best-effort: event lookup failures leave the health unchanged.
+func (c *Client) WithEvents(ctx context.Context, obj *unstructured.Unstructured, health Health) Health {
+	events, err := c.GetEvents(ctx, obj)
+	if err != nil {
+		ctx.Debugf("failed to fetch events for %s/%s: %v", obj.GetKind(), obj.GetName(), err)
+		return health
+	}
+
+	for _, event := range events {
+		if event.Type == "Warning" {
+			health.Events = append(health.Events, event)
+		}
+	}
+	return health
+}
@@ -0,0 +1,103 @@
+package kubernetes
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"net/http"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/tools/remotecommand"
+
+	"github.com/flanksource/commons-db/context"
+)
+
+// ExecutePodf runs a command in a pod container and returns its stdout,
+// for one-shot commands where buffering the output is fine. Use
+// ExecStream for stdin, live output or interactive sessions.
+func (c *Client) ExecutePodf(ctx context.Context, namespace, pod, container string, command ...string) (string, error) {
+	var stdout, stderr bytes.Buffer
+	if err := c.execInPod(ctx, namespace, pod, container, command, nil, &stdout, &stderr); err != nil {
+		return stdout.String(), fmt.Errorf("failed to exec in %s/%s: %w%s", namespace, pod, err, stderrSuffix(&stderr))
+	}
+	return stdout.String(), nil
+}
+
+// TerminalSize is a terminal width/height, re-exported so callers of
+// ExecStream don't import remotecommand for the one type.
+type TerminalSize = remotecommand.TerminalSize
+
+// ExecStreamOptions wires the streams of an ExecStream call.
+type ExecStreamOptions struct {
+	Container string
+
+	// Stdin is streamed to the process; nil leaves stdin closed.
+	Stdin io.Reader
+
+	// Stdout and Stderr receive output as it is produced. With TTY set
+	// the process sees a terminal and everything arrives on Stdout.
+	Stdout io.Writer
+	Stderr io.Writer
+
+	TTY bool
+
+	// Resize delivers terminal size changes (initial size first) to the
+	// remote TTY; only used when TTY is set.
+	Resize <-chan TerminalSize
+}
+
+// ExecStream runs a command in a pod container with fully streaming
+// semantics — stdin from a reader, live stdout/stderr, and optional TTY
+// with resize — enabling interactive debugging sessions and long-running
+// commands with live output. It blocks until the command exits or the
+// context is cancelled.
+func (c *Client) ExecStream(ctx context.Context, namespace, pod string, command []string, opts ExecStreamOptions) error {
+	req := c.CoreV1().RESTClient().Post().
+		Resource("pods").Namespace(namespace).Name(pod).
+		SubResource("exec").
+		VersionedParams(&corev1.PodExecOptions{
+			Container: opts.Container,
+			Command:   command,
+			Stdin:     opts.Stdin != nil,
+			Stdout:    opts.Stdout != nil,
+			Stderr:    opts.Stderr != nil && !opts.TTY,
+			TTY:       opts.TTY,
+		}, scheme.ParameterCodec)
+
+	executor, err := remotecommand.NewSPDYExecutor(c.Config, http.MethodPost, req.URL())
+	if err != nil {
+		return fmt.Errorf("failed to create executor: %w", err)
+	}
+
+	streamOptions := remotecommand.StreamOptions{
+		Stdin:  opts.Stdin,
+		Stdout: opts.Stdout,
+		Tty:    opts.TTY,
+	}
+	if !opts.TTY {
+		streamOptions.Stderr = opts.Stderr
+	}
+	if opts.TTY && opts.Resize != nil {
+		streamOptions.TerminalSizeQueue = &channelSizeQueue{resize: opts.Resize}
+	}
+
+	if err := executor.StreamWithContext(ctx, streamOptions); err != nil {
+		return fmt.Errorf("exec in %s/%s failed: %w", namespace, pod, err)
+	}
+	return nil
+}
+
+// channelSizeQueue adapts a channel of terminal sizes to the
+// remotecommand queue interface; a closed channel ends resize handling.
+type channelSizeQueue struct {
+	resize <-chan TerminalSize
+}
+
+func (q *channelSizeQueue) Next() *TerminalSize {
+	size, ok := <-q.resize
+	if !ok {
+		return nil
+	}
+	return &size
+}
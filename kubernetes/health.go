@@ -0,0 +1,110 @@
+package kubernetes
+
+import (
+	"fmt"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+// Health is the evaluated health of a single resource.
+type Health struct {
+	Healthy bool   `json:"healthy"`
+	Status  string `json:"status,omitempty"`
+	Message string `json:"message,omitempty"`
+
+	// Events are the resource's warning events, attached by
+	// Client.WithEvents; CheckHealth itself never populates them.
+	Events []Event `json:"events,omitempty"`
+}
+
+// Equals compares the evaluated state, ignoring attached events.
+func (h Health) Equals(other Health) bool {
+	return h.Healthy == other.Healthy && h.Status == other.Status && h.Message == other.Message
+}
+
+// CheckHealth evaluates the health of an arbitrary resource from its
+// status using the conventions most controllers follow: Ready/Available
+// conditions, replica counts, and pod phases. Resources without any
+// recognizable status are considered healthy (there is nothing to wait
+// for).
+func CheckHealth(obj *unstructured.Unstructured) Health {
+	switch obj.GetKind() {
+	case "Pod":
+		return podHealth(obj)
+	case "Job":
+		return conditionHealth(obj, "Complete", "Failed")
+	}
+
+	// replica-based workloads
+	if specReplicas, found, _ := unstructured.NestedInt64(obj.Object, "spec", "replicas"); found {
+		readyReplicas, _, _ := unstructured.NestedInt64(obj.Object, "status", "readyReplicas")
+		observedGeneration, _, _ := unstructured.NestedInt64(obj.Object, "status", "observedGeneration")
+		if observedGeneration < obj.GetGeneration() {
+			return Health{Status: "Progressing", Message: "waiting for controller to observe the latest generation"}
+		}
+		if readyReplicas < specReplicas {
+			return Health{Status: "Progressing", Message: fmt.Sprintf("%d/%d replicas ready", readyReplicas, specReplicas)}
+		}
+		return Health{Healthy: true, Status: "Running"}
+	}
+
+	// generic Ready/Available conditions
+	conditions, found, _ := unstructured.NestedSlice(obj.Object, "status", "conditions")
+	if found {
+		for _, c := range conditions {
+			condition, ok := c.(map[string]any)
+			if !ok {
+				continue
+			}
+			conditionType, _ := condition["type"].(string)
+			status, _ := condition["status"].(string)
+			message, _ := condition["message"].(string)
+			if conditionType == "Ready" || conditionType == "Available" {
+				if status == "True" {
+					return Health{Healthy: true, Status: conditionType}
+				}
+				return Health{Status: "NotReady", Message: message}
+			}
+		}
+	}
+
+	return Health{Healthy: true, Status: "Unknown"}
+}
+
+func podHealth(obj *unstructured.Unstructured) Health {
+	phase, _, _ := unstructured.NestedString(obj.Object, "status", "phase")
+	switch phase {
+	case "Running", "Succeeded":
+		return Health{Healthy: true, Status: phase}
+	case "Failed":
+		message, _, _ := unstructured.NestedString(obj.Object, "status", "message")
+		return Health{Status: phase, Message: message}
+	default:
+		return Health{Status: phase}
+	}
+}
+
+// conditionHealth treats the resource as healthy when successType is
+// True and failed when failureType is True.
+func conditionHealth(obj *unstructured.Unstructured, successType, failureType string) Health {
+	conditions, _, _ := unstructured.NestedSlice(obj.Object, "status", "conditions")
+	for _, c := range conditions {
+		condition, ok := c.(map[string]any)
+		if !ok {
+			continue
+		}
+		conditionType, _ := condition["type"].(string)
+		status, _ := condition["status"].(string)
+		message, _ := condition["message"].(string)
+		if status != "True" {
+			continue
+		}
+		switch conditionType {
+		case successType:
+			return Health{Healthy: true, Status: successType}
+		case failureType:
+			return Health{Status: failureType, Message: message}
+		}
+	}
+	return Health{Status: "Progressing"}
+}
@@ -0,0 +1,75 @@
+package kubernetes
+
+import (
+	gocontext "context"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+
+	"github.com/flanksource/commons-db/context"
+)
+
+// ListMetadataOnly lists only the object metadata (name, labels,
+// annotations, owner references, ...) of the resources matching the
+// label selector. On huge collections like pods and events this is an
+// order of magnitude cheaper than a full list: the API server serves
+// PartialObjectMetadata and never serializes specs or statuses.
+//
+// QueryResources uses this automatically when the resource selector has
+// MetadataOnly set.
+func (c *Client) ListMetadataOnly(ctx context.Context, gvk schema.GroupVersionKind, namespace string, selector string) ([]metav1.PartialObjectMetadata, error) {
+	mapper, err := c.GetRestMapper()
+	if err != nil {
+		return nil, err
+	}
+
+	mapping, err := mapper.RESTMapping(gvk.GroupKind(), gvk.Version)
+	if err != nil {
+		return nil, WrapError(err, gvk, namespace, "", "list")
+	}
+
+	items, err := c.listMetadata(ctx, mapping.Resource, namespace, metav1.ListOptions{
+		LabelSelector: selector,
+		Limit:         int64(ctx.Properties().Int("kubernetes.query.page_size", defaultQueryPageSize)),
+	})
+	if err != nil {
+		return nil, WrapError(err, gvk, namespace, "", "list")
+	}
+	return items, nil
+}
+
+// listMetadata pages through a metadata-only list with Limit/Continue,
+// like QueryResources does for full lists. It takes a plain context so
+// errgroup-derived contexts can be passed straight in; errors come back
+// unwrapped so callers can attach their own GVK context.
+func (c *Client) listMetadata(ctx gocontext.Context, gvr schema.GroupVersionResource, namespace string, options metav1.ListOptions) ([]metav1.PartialObjectMetadata, error) {
+	var items []metav1.PartialObjectMetadata
+	for {
+		list, err := c.metadataClient.Resource(gvr).Namespace(namespace).List(ctx, options)
+		if err != nil {
+			return nil, err
+		}
+		items = append(items, list.Items...)
+
+		if list.GetContinue() == "" {
+			return items, nil
+		}
+		options.Continue = list.GetContinue()
+	}
+}
+
+// partialToUnstructured converts a metadata-only list item into an
+// unstructured object carrying just apiVersion, kind and metadata, so
+// metadata-only results flow through the same code paths as full ones.
+func partialToUnstructured(gvk schema.GroupVersionKind, item metav1.PartialObjectMetadata) (unstructured.Unstructured, error) {
+	content, err := runtime.DefaultUnstructuredConverter.ToUnstructured(&item)
+	if err != nil {
+		return unstructured.Unstructured{}, err
+	}
+
+	obj := unstructured.Unstructured{Object: content}
+	obj.SetGroupVersionKind(gvk)
+	return obj, nil
+}
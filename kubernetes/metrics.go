@@ -0,0 +1,133 @@
+package kubernetes
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+
+	"github.com/flanksource/commons-db/context"
+	"github.com/flanksource/commons-db/types"
+)
+
+// ErrMetricsUnavailable is returned when the cluster has no
+// metrics-server (or custom metrics adapter) installed. Callers making
+// health or scaling decisions should treat it as "no data", not as a
+// failure.
+var ErrMetricsUnavailable = errors.New("metrics API is not available on this cluster")
+
+var podMetricsGVR = schema.GroupVersionResource{Group: "metrics.k8s.io", Version: "v1beta1", Resource: "pods"}
+
+// PodMetrics is the current resource usage of one pod, summed over its
+// containers.
+type PodMetrics struct {
+	Namespace     string    `json:"namespace"`
+	Name          string    `json:"name"`
+	CPUMillicores int64     `json:"cpu_millicores"`
+	MemoryBytes   int64     `json:"memory_bytes"`
+	Timestamp     time.Time `json:"timestamp"`
+}
+
+// GetPodMetrics returns current usage for the pods matching the label
+// selector (empty selects all pods in the namespace), served by
+// metrics-server. Clusters without metrics-server return
+// ErrMetricsUnavailable.
+func (c *Client) GetPodMetrics(ctx context.Context, namespace, labelSelector string) ([]PodMetrics, error) {
+	list, err := c.dynamicClient.Resource(podMetricsGVR).Namespace(namespace).List(ctx, metav1.ListOptions{
+		LabelSelector: labelSelector,
+	})
+	if err != nil {
+		if apierrors.IsNotFound(err) {
+			return nil, ErrMetricsUnavailable
+		}
+		return nil, WrapError(err, podMetricsGVR.GroupVersion().WithKind("PodMetrics"), namespace, "", "list")
+	}
+
+	metrics := make([]PodMetrics, 0, len(list.Items))
+	for _, item := range list.Items {
+		pod := PodMetrics{Namespace: item.GetNamespace(), Name: item.GetName()}
+		if ts, ok, _ := unstructured.NestedString(item.Object, "timestamp"); ok {
+			pod.Timestamp, _ = time.Parse(time.RFC3339, ts)
+		}
+
+		containers, _, _ := unstructured.NestedSlice(item.Object, "containers")
+		for _, container := range containers {
+			usage, _, _ := unstructured.NestedStringMap(container.(map[string]any), "usage")
+			if cpu, err := resource.ParseQuantity(usage["cpu"]); err == nil {
+				pod.CPUMillicores += cpu.MilliValue()
+			}
+			if memory, err := resource.ParseQuantity(usage["memory"]); err == nil {
+				pod.MemoryBytes += memory.Value()
+			}
+		}
+		metrics = append(metrics, pod)
+	}
+	return metrics, nil
+}
+
+// MetricValue is one object's value of a custom metric.
+type MetricValue struct {
+	Kind      string    `json:"kind"`
+	Namespace string    `json:"namespace,omitempty"`
+	Name      string    `json:"name"`
+	Value     float64   `json:"value"`
+	Timestamp time.Time `json:"timestamp"`
+}
+
+// customMetricItem mirrors the custom.metrics.k8s.io v1beta2 item shape.
+type customMetricItem struct {
+	DescribedObject struct {
+		Kind      string `json:"kind"`
+		Namespace string `json:"namespace"`
+		Name      string `json:"name"`
+	} `json:"describedObject"`
+	Timestamp time.Time `json:"timestamp"`
+	Value     string    `json:"value"`
+}
+
+// CustomMetric queries a custom metric (custom.metrics.k8s.io) for the
+// pods matched by the selector, whose Namespace and LabelSelector scope
+// the query. Clusters without a custom metrics adapter return
+// ErrMetricsUnavailable.
+func (c *Client) CustomMetric(ctx context.Context, metric string, selector types.ResourceSelector) ([]MetricValue, error) {
+	path := fmt.Sprintf("/apis/custom.metrics.k8s.io/v1beta2/namespaces/%s/pods/*/%s", selector.Namespace, metric)
+	raw, err := c.Discovery().RESTClient().Get().
+		AbsPath(path).
+		Param("labelSelector", selector.LabelSelector).
+		DoRaw(ctx)
+	if err != nil {
+		if apierrors.IsNotFound(err) {
+			return nil, ErrMetricsUnavailable
+		}
+		return nil, fmt.Errorf("failed to query custom metric %s: %w", metric, err)
+	}
+
+	var response struct {
+		Items []customMetricItem `json:"items"`
+	}
+	if err := json.Unmarshal(raw, &response); err != nil {
+		return nil, fmt.Errorf("failed to parse custom metric %s response: %w", metric, err)
+	}
+
+	values := make([]MetricValue, 0, len(response.Items))
+	for _, item := range response.Items {
+		quantity, err := resource.ParseQuantity(item.Value)
+		if err != nil {
+			return nil, fmt.Errorf("custom metric %s has unparseable value %q: %w", metric, item.Value, err)
+		}
+		values = append(values, MetricValue{
+			Kind:      item.DescribedObject.Kind,
+			Namespace: item.DescribedObject.Namespace,
+			Name:      item.DescribedObject.Name,
+			Value:     quantity.AsApproximateFloat64(),
+			Timestamp: item.Timestamp,
+		})
+	}
+	return values, nil
+}
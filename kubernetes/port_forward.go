@@ -0,0 +1,130 @@
+package kubernetes
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/client-go/tools/portforward"
+	"k8s.io/client-go/transport/spdy"
+
+	"github.com/flanksource/commons-db/context"
+)
+
+// PortForward is an active port-forward; Close terminates it.
+type PortForward struct {
+	localPort uint16
+	stop      chan struct{}
+	done      chan error
+	once      sync.Once
+}
+
+// LocalPort returns the local listening port, useful when the forward
+// was requested with localPort 0 (pick any free port).
+func (p *PortForward) LocalPort() uint16 {
+	return p.localPort
+}
+
+// Close stops the forward and waits for its goroutine to exit.
+func (p *PortForward) Close() error {
+	p.once.Do(func() { close(p.stop) })
+	return <-p.done
+}
+
+// PortForward forwards localhost:localPort to remotePort on a pod over
+// SPDY, so e2e tests and CLIs can reach in-cluster services without
+// shelling out to kubectl. The target is a pod name, "pod/name" or
+// "svc/name" — a service resolves to one of its running pods, and
+// remotePort is always the pod's port. localPort 0 picks a free port;
+// read it back with LocalPort. It returns once the listener is ready;
+// the caller must Close the forward.
+func (c *Client) PortForward(ctx context.Context, namespace, podOrService string, localPort, remotePort int) (*PortForward, error) {
+	pod, err := c.resolvePortForwardPod(ctx, namespace, podOrService)
+	if err != nil {
+		return nil, err
+	}
+
+	transport, upgrader, err := spdy.RoundTripperFor(c.Config)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create spdy transport: %w", err)
+	}
+
+	url := c.CoreV1().RESTClient().Post().
+		Resource("pods").Namespace(namespace).Name(pod).
+		SubResource("portforward").URL()
+	dialer := spdy.NewDialer(upgrader, &http.Client{Transport: transport}, http.MethodPost, url)
+
+	stop := make(chan struct{})
+	ready := make(chan struct{})
+	forwarder, err := portforward.New(dialer, []string{fmt.Sprintf("%d:%d", localPort, remotePort)}, stop, ready, io.Discard, io.Discard)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create port forwarder: %w", err)
+	}
+
+	done := make(chan error, 1)
+	go func() { done <- forwarder.ForwardPorts() }()
+
+	select {
+	case <-ready:
+	case err := <-done:
+		return nil, fmt.Errorf("port-forward to pod %s/%s failed: %w", namespace, pod, err)
+	case <-ctx.Done():
+		close(stop)
+		<-done
+		return nil, ctx.Err()
+	}
+
+	ports, err := forwarder.GetPorts()
+	if err != nil || len(ports) == 0 {
+		close(stop)
+		<-done
+		return nil, fmt.Errorf("port-forward to pod %s/%s has no ports: %w", namespace, pod, err)
+	}
+
+	return &PortForward{localPort: ports[0].Local, stop: stop, done: done}, nil
+}
+
+// resolvePortForwardPod turns a port-forward target into a pod name: a
+// bare name or "pod/name" passes through, "svc/name" resolves to a
+// running pod matched by the service's selector.
+func (c *Client) resolvePortForwardPod(ctx context.Context, namespace, target string) (string, error) {
+	kind, name, found := strings.Cut(target, "/")
+	if !found {
+		return target, nil
+	}
+
+	switch strings.ToLower(kind) {
+	case "pod", "po":
+		return name, nil
+	case "svc", "service":
+	default:
+		return "", fmt.Errorf("cannot port-forward to %q: only pods and services are supported", target)
+	}
+
+	svc, err := c.CoreV1().Services(namespace).Get(ctx, name, metav1.GetOptions{})
+	if err != nil {
+		return "", fmt.Errorf("failed to get service %s/%s: %w", namespace, name, err)
+	}
+	if len(svc.Spec.Selector) == 0 {
+		return "", fmt.Errorf("service %s/%s has no selector to resolve pods from", namespace, name)
+	}
+
+	pods, err := c.CoreV1().Pods(namespace).List(ctx, metav1.ListOptions{
+		LabelSelector: labels.Set(svc.Spec.Selector).String(),
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to list pods for service %s/%s: %w", namespace, name, err)
+	}
+
+	for _, pod := range pods.Items {
+		if pod.Status.Phase == corev1.PodRunning && pod.DeletionTimestamp == nil {
+			return pod.Name, nil
+		}
+	}
+	return "", fmt.Errorf("service %s/%s has no running pods", namespace, name)
+}
@@ -0,0 +1,192 @@
+package kubernetes
+
+import (
+	"fmt"
+	"path"
+	"sort"
+	"strings"
+	"sync"
+
+	"golang.org/x/sync/errgroup"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+
+	"github.com/flanksource/commons-db/context"
+	"github.com/flanksource/commons-db/types"
+)
+
+// defaultQueryConcurrency bounds parallel list calls across kind ×
+// namespace combinations; overridable via the
+// kubernetes.query.concurrency property or WithQueryConcurrency.
+const defaultQueryConcurrency = 8
+
+// defaultQueryPageSize is the server-side page size (the Limit passed
+// to list calls); overridable via the kubernetes.query.page_size
+// property or WithPageSize.
+const defaultQueryPageSize = 500
+
+// QueryOption tunes a single QueryResources call.
+type QueryOption func(*queryOptions)
+
+type queryOptions struct {
+	concurrency int
+	pageSize    int
+}
+
+// WithQueryConcurrency bounds how many list calls run in parallel.
+func WithQueryConcurrency(n int) QueryOption {
+	return func(o *queryOptions) { o.concurrency = n }
+}
+
+// WithPageSize overrides the server-side page size.
+func WithPageSize(n int) QueryOption {
+	return func(o *queryOptions) { o.pageSize = n }
+}
+
+// QueryResources lists the resources matching the selector. Each
+// kind × namespace combination is listed in parallel (bounded), and the
+// combined result is sorted by kind, namespace, name so output ordering
+// is deterministic regardless of completion order.
+func (c *Client) QueryResources(ctx context.Context, selector types.ResourceSelector, opts ...QueryOption) ([]unstructured.Unstructured, error) {
+	options := queryOptions{
+		concurrency: ctx.Properties().Int("kubernetes.query.concurrency", defaultQueryConcurrency),
+		pageSize:    ctx.Properties().Int("kubernetes.query.page_size", defaultQueryPageSize),
+	}
+	for _, opt := range opts {
+		opt(&options)
+	}
+
+	kinds := selectorKinds(selector)
+	if len(kinds) == 0 {
+		return nil, fmt.Errorf("resource selector has no types to query")
+	}
+	namespaces := strings.Split(selector.Namespace, ",")
+
+	var mu sync.Mutex
+	var results []unstructured.Unstructured
+
+	group, groupCtx := errgroup.WithContext(ctx)
+	group.SetLimit(options.concurrency)
+
+	for _, kind := range kinds {
+		gvr, namespaced, err := c.ResourceForKind(kind)
+		if err != nil {
+			return nil, err
+		}
+
+		queryNamespaces := namespaces
+		if !namespaced {
+			queryNamespaces = []string{""}
+		}
+
+		for _, namespace := range queryNamespaces {
+			namespace := strings.TrimSpace(namespace)
+
+			if selector.IsMetadataOnly() {
+				gvk := gvr.GroupVersion().WithKind(kind)
+				group.Go(func() error {
+					items, err := c.listMetadata(groupCtx, gvr, namespace, metav1.ListOptions{
+						LabelSelector: selector.LabelSelector,
+						FieldSelector: selector.FieldSelector,
+						Limit:         int64(options.pageSize),
+					})
+					if err != nil {
+						return WrapError(err, gvk, namespace, "", "list")
+					}
+
+					var matched []unstructured.Unstructured
+					for _, item := range items {
+						if !matchesName(selector.Name, item.Name) {
+							continue
+						}
+						obj, err := partialToUnstructured(gvk, item)
+						if err != nil {
+							return err
+						}
+						matched = append(matched, obj)
+					}
+
+					mu.Lock()
+					results = append(results, matched...)
+					mu.Unlock()
+					return nil
+				})
+				continue
+			}
+
+			group.Go(func() error {
+				// pages through Limit/Continue so a single list never
+				// materializes the whole cluster on the API server
+				listOptions := metav1.ListOptions{
+					LabelSelector: selector.LabelSelector,
+					FieldSelector: selector.FieldSelector,
+					Limit:         int64(options.pageSize),
+				}
+				for {
+					list, err := c.dynamicClient.Resource(gvr).Namespace(namespace).List(groupCtx, listOptions)
+					if err != nil {
+						return WrapError(err, gvr.GroupVersion().WithKind(kind), namespace, "", "list")
+					}
+
+					matched := list.Items[:0]
+					for _, item := range list.Items {
+						if matchesName(selector.Name, item.GetName()) {
+							matched = append(matched, item)
+						}
+					}
+
+					mu.Lock()
+					results = append(results, matched...)
+					mu.Unlock()
+
+					if list.GetContinue() == "" {
+						return nil
+					}
+					listOptions.Continue = list.GetContinue()
+				}
+			})
+		}
+	}
+
+	if err := group.Wait(); err != nil {
+		return nil, err
+	}
+
+	sortResources(results)
+	return results, nil
+}
+
+// sortResources orders resources by kind, namespace, name for
+// deterministic output.
+func sortResources(results []unstructured.Unstructured) {
+	sort.Slice(results, func(i, j int) bool {
+		a, b := results[i], results[j]
+		if a.GetKind() != b.GetKind() {
+			return a.GetKind() < b.GetKind()
+		}
+		if a.GetNamespace() != b.GetNamespace() {
+			return a.GetNamespace() < b.GetNamespace()
+		}
+		return a.GetName() < b.GetName()
+	})
+}
+
+// selectorKinds extracts Kubernetes kinds from the selector's types,
+// accepting both bare kinds (Pod) and config types (Kubernetes::Pod).
+func selectorKinds(selector types.ResourceSelector) []string {
+	var kinds []string
+	for _, t := range selector.Types {
+		kinds = append(kinds, strings.TrimPrefix(t, "Kubernetes::"))
+	}
+	return kinds
+}
+
+// matchesName matches a resource name against a pattern that may use *
+// wildcards; an empty pattern matches everything.
+func matchesName(pattern, name string) bool {
+	if pattern == "" {
+		return true
+	}
+	matched, err := path.Match(pattern, name)
+	return err == nil && matched
+}
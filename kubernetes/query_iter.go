@@ -0,0 +1,147 @@
+package kubernetes
+
+import (
+	"fmt"
+	"strings"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+
+	"github.com/flanksource/commons-db/context"
+	"github.com/flanksource/commons-db/types"
+)
+
+// ResourceIterator streams selector-matched resources one page at a
+// time, so a selector over a large cluster holds at most one page in
+// memory. Usage follows bufio.Scanner:
+//
+//	iter, err := client.QueryResourcesIter(ctx, selector)
+//	for iter.Next() {
+//	    process(iter.Item())
+//	}
+//	if err := iter.Err(); err != nil { ... }
+//
+// Unlike QueryResources, results arrive in server order per kind ×
+// namespace, not globally sorted — sorting would require materializing
+// everything, which is what the iterator exists to avoid.
+type ResourceIterator struct {
+	ctx      context.Context
+	client   *Client
+	selector types.ResourceSelector
+	pageSize int
+
+	// remaining kind × namespace combinations, then the current page
+	targets  []iterTarget
+	page     []unstructured.Unstructured
+	position int
+	token    string // continue token of the current target
+
+	item *unstructured.Unstructured
+	err  error
+}
+
+type iterTarget struct {
+	gvr       schema.GroupVersionResource
+	kind      string
+	namespace string
+}
+
+// QueryResourcesIter returns an iterator over the resources matching
+// the selector, fetching pages of WithPageSize lazily as the caller
+// advances.
+func (c *Client) QueryResourcesIter(ctx context.Context, selector types.ResourceSelector, opts ...QueryOption) (*ResourceIterator, error) {
+	options := queryOptions{
+		pageSize: ctx.Properties().Int("kubernetes.query.page_size", defaultQueryPageSize),
+	}
+	for _, opt := range opts {
+		opt(&options)
+	}
+
+	kinds := selectorKinds(selector)
+	if len(kinds) == 0 {
+		return nil, fmt.Errorf("resource selector has no types to query")
+	}
+
+	var targets []iterTarget
+	for _, kind := range kinds {
+		gvr, namespaced, err := c.ResourceForKind(kind)
+		if err != nil {
+			return nil, err
+		}
+
+		namespaces := strings.Split(selector.Namespace, ",")
+		if !namespaced {
+			namespaces = []string{""}
+		}
+		for _, namespace := range namespaces {
+			targets = append(targets, iterTarget{gvr: gvr, kind: kind, namespace: strings.TrimSpace(namespace)})
+		}
+	}
+
+	return &ResourceIterator{
+		ctx:      ctx,
+		client:   c,
+		selector: selector,
+		pageSize: options.pageSize,
+		targets:  targets,
+	}, nil
+}
+
+// Next advances to the next matching resource, fetching the next page
+// when the current one is exhausted. It returns false at the end of the
+// results or on error; check Err to distinguish.
+func (it *ResourceIterator) Next() bool {
+	for {
+		if it.err != nil {
+			return false
+		}
+
+		for it.position < len(it.page) {
+			item := it.page[it.position]
+			it.position++
+			if matchesName(it.selector.Name, item.GetName()) {
+				it.item = &item
+				return true
+			}
+		}
+
+		if len(it.targets) == 0 {
+			return false
+		}
+		it.fetchPage()
+	}
+}
+
+// Item returns the resource Next advanced to.
+func (it *ResourceIterator) Item() *unstructured.Unstructured {
+	return it.item
+}
+
+// Err returns the first error the iterator hit, if any.
+func (it *ResourceIterator) Err() error {
+	return it.err
+}
+
+// fetchPage loads the next page of the current target, moving on to the
+// next target when its pages are exhausted.
+func (it *ResourceIterator) fetchPage() {
+	target := it.targets[0]
+	list, err := it.client.dynamicClient.Resource(target.gvr).Namespace(target.namespace).List(it.ctx, metav1.ListOptions{
+		LabelSelector: it.selector.LabelSelector,
+		FieldSelector: it.selector.FieldSelector,
+		Limit:         int64(it.pageSize),
+		Continue:      it.token,
+	})
+	if err != nil {
+		it.err = WrapError(err, target.gvr.GroupVersion().WithKind(target.kind), target.namespace, "", "list")
+		return
+	}
+
+	it.page = list.Items
+	it.position = 0
+	it.token = list.GetContinue()
+	if it.token == "" {
+		it.targets = it.targets[1:]
+	}
+}
@@ -0,0 +1,105 @@
+package kubernetes
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/discovery"
+	"k8s.io/client-go/discovery/cached/disk"
+	"k8s.io/client-go/discovery/cached/memory"
+	"k8s.io/client-go/restmapper"
+
+	"github.com/flanksource/commons-db/context"
+)
+
+// discoveryCacheTTL is how long cached discovery data is trusted; also
+// overridable via the kubernetes.discovery.cache_ttl property.
+const discoveryCacheTTL = 10 * time.Minute
+
+// GetRestMapper returns a deferred-discovery REST mapper. Discovery is
+// cached on disk (kubectl-style, default $HOME/.kube/cache) keyed by
+// host and kubeconfig context, or in memory when the client was built
+// with WithInMemoryDiscovery (for read-only filesystems).
+func (c *Client) GetRestMapper() (meta.RESTMapper, error) {
+	discoveryClient, err := c.discoveryClient()
+	if err != nil {
+		return nil, err
+	}
+	return restmapper.NewDeferredDiscoveryRESTMapper(discoveryClient), nil
+}
+
+func (c *Client) discoveryClient() (discovery.CachedDiscoveryInterface, error) {
+	ttl := context.GlobalProperties().Duration("kubernetes.discovery.cache_ttl", discoveryCacheTTL)
+
+	if c.inMemoryDiscovery {
+		underlying, err := discovery.NewDiscoveryClientForConfig(c.Config)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create discovery client: %w", err)
+		}
+		return memory.NewMemCacheClient(underlying), nil
+	}
+
+	baseDir := c.discoveryCacheDir
+	if baseDir == "" {
+		baseDir = context.GlobalProperties().String("kubernetes.discovery.cache_dir",
+			filepath.Join(homeDir(), ".kube", "cache", "discovery"))
+	}
+
+	// isolate per host and per kubeconfig context: two contexts can hit
+	// the same host with different credentials and see different APIs
+	key := sanitizeHost(c.Config.Host)
+	if c.contextName != "" {
+		key = filepath.Join(key, sanitizeHost(c.contextName))
+	}
+
+	discoveryClient, err := disk.NewCachedDiscoveryClientForConfig(c.Config, filepath.Join(baseDir, key), "", ttl)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create discovery client: %w", err)
+	}
+	return discoveryClient, nil
+}
+
+// ResourceForKind resolves a kind (e.g. Pod, Deployment) to its
+// preferred GroupVersionResource and scope.
+func (c *Client) ResourceForKind(kind string) (schema.GroupVersionResource, bool, error) {
+	mapper, err := c.GetRestMapper()
+	if err != nil {
+		return schema.GroupVersionResource{}, false, err
+	}
+
+	gvk, err := mapper.KindFor(schema.GroupVersionResource{Resource: kind})
+	if err != nil {
+		return schema.GroupVersionResource{}, false, fmt.Errorf("failed to resolve kind %s: %w", kind, err)
+	}
+
+	mapping, err := mapper.RESTMapping(gvk.GroupKind(), gvk.Version)
+	if err != nil {
+		return schema.GroupVersionResource{}, false, err
+	}
+	namespaced := mapping.Scope.Name() == meta.RESTScopeNameNamespace
+	return mapping.Resource, namespaced, nil
+}
+
+func homeDir() string {
+	if home, err := os.UserHomeDir(); err == nil {
+		return home
+	}
+	return os.TempDir()
+}
+
+func sanitizeHost(host string) string {
+	out := make([]rune, 0, len(host))
+	for _, r := range host {
+		switch r {
+		case ':', '/', '.':
+			out = append(out, '_')
+		default:
+			out = append(out, r)
+		}
+	}
+	return string(out)
+}
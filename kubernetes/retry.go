@@ -0,0 +1,158 @@
+package kubernetes
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+
+	"k8s.io/client-go/rest"
+)
+
+// RetryOptions configures the retrying transport installed by WithRetry.
+type RetryOptions struct {
+	// MaxRetries is the number of retries after the initial attempt.
+	MaxRetries int
+
+	// BaseBackoff is the first retry delay; it doubles on every attempt.
+	// A Retry-After response header always takes precedence.
+	BaseBackoff time.Duration
+
+	// MaxBackoff caps the per-attempt delay, including Retry-After hints.
+	MaxBackoff time.Duration
+}
+
+func (t RetryOptions) withDefaults() RetryOptions {
+	if t.MaxRetries <= 0 {
+		t.MaxRetries = 3
+	}
+	if t.BaseBackoff <= 0 {
+		t.BaseBackoff = 500 * time.Millisecond
+	}
+	if t.MaxBackoff <= 0 {
+		t.MaxBackoff = 30 * time.Second
+	}
+	return t
+}
+
+// ThrottledError is returned when the API server keeps responding 429
+// after all retries. RetryAfter carries the server's last wait hint so
+// callers can schedule their own retry.
+type ThrottledError struct {
+	RetryAfter time.Duration
+}
+
+func (t *ThrottledError) Error() string {
+	return fmt.Sprintf("API server is throttling requests (retry after %s)", t.RetryAfter)
+}
+
+// WithRetry installs a transport that retries requests on 429 responses
+// and transient network errors (EOF, connection reset), honoring the
+// Retry-After header with exponential backoff otherwise.
+func WithRetry(opts RetryOptions) ClientOption {
+	opts = opts.withDefaults()
+	return func(config *rest.Config) {
+		config.Wrap(func(rt http.RoundTripper) http.RoundTripper {
+			return &retryRoundTripper{next: rt, opts: opts}
+		})
+	}
+}
+
+type retryRoundTripper struct {
+	next http.RoundTripper
+	opts RetryOptions
+}
+
+func (t *retryRoundTripper) RoundTrip(req *http.Request) (*http.Response, error) {
+	var lastRetryAfter time.Duration
+
+	for attempt := 0; ; attempt++ {
+		resp, err := t.next.RoundTrip(req)
+
+		retryable, retryAfter := t.shouldRetry(resp, err)
+		if !retryable {
+			return resp, err
+		}
+		if retryAfter == 0 {
+			// no server hint: exponential backoff
+			retryAfter = min(t.opts.BaseBackoff<<attempt, t.opts.MaxBackoff)
+		}
+		lastRetryAfter = retryAfter
+
+		if attempt >= t.opts.MaxRetries || req.Body != nil && req.GetBody == nil {
+			if resp != nil && resp.StatusCode == http.StatusTooManyRequests {
+				drain(resp)
+				return nil, &ThrottledError{RetryAfter: lastRetryAfter}
+			}
+			return resp, err
+		}
+
+		if resp != nil {
+			drain(resp)
+		}
+		if req.GetBody != nil {
+			body, bodyErr := req.GetBody()
+			if bodyErr != nil {
+				return resp, err
+			}
+			req.Body = body
+		}
+
+		select {
+		case <-req.Context().Done():
+			return nil, req.Context().Err()
+		case <-time.After(retryAfter):
+		}
+	}
+}
+
+// shouldRetry decides whether the response or error is transient and how
+// long to wait before the next attempt.
+func (t *retryRoundTripper) shouldRetry(resp *http.Response, err error) (bool, time.Duration) {
+	if err != nil {
+		if errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) || errors.Is(err, syscall.ECONNRESET) {
+			return true, 0
+		}
+		var netErr net.Error
+		if errors.As(err, &netErr) && netErr.Timeout() {
+			return true, 0
+		}
+		if strings.Contains(err.Error(), "connection reset by peer") {
+			return true, 0
+		}
+		return false, 0
+	}
+
+	if resp.StatusCode == http.StatusTooManyRequests {
+		if wait := parseRetryAfter(resp.Header.Get("Retry-After")); wait > 0 {
+			return true, min(wait, t.opts.MaxBackoff)
+		}
+		return true, 0
+	}
+	return false, 0
+}
+
+func parseRetryAfter(header string) time.Duration {
+	if header == "" {
+		return 0
+	}
+	if seconds, err := strconv.Atoi(header); err == nil {
+		return time.Duration(seconds) * time.Second
+	}
+	if at, err := http.ParseTime(header); err == nil {
+		return time.Until(at)
+	}
+	return 0
+}
+
+func drain(resp *http.Response) {
+	if resp.Body != nil {
+		_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 4096))
+		_ = resp.Body.Close()
+	}
+}
@@ -0,0 +1,185 @@
+package kubernetes
+
+import (
+	"fmt"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+
+	"github.com/flanksource/commons-db/context"
+)
+
+// WaitForRollout waits for a workload's rollout to complete, mirroring
+// kubectl rollout status: it tracks observedGeneration and replica
+// counters rather than generic health, so it only returns once the
+// latest revision is fully rolled out. Deployments, StatefulSets,
+// DaemonSets and Argo Rollouts are supported. A rollout the controller
+// has given up on (e.g. progress deadline exceeded) fails immediately.
+func (c *Client) WaitForRollout(ctx context.Context, kind, namespace, name string, timeout time.Duration) error {
+	if timeout <= 0 {
+		timeout = 5 * time.Minute
+	}
+
+	gvr, namespaced, err := c.ResourceForKind(kind)
+	if err != nil {
+		return err
+	}
+	client := c.dynamicClient.Resource(gvr)
+	gvk := gvr.GroupVersion().WithKind(kind)
+
+	deadline := time.Now().Add(timeout)
+	lastStatus := "pending"
+	for {
+		var obj *unstructured.Unstructured
+		if namespaced {
+			obj, err = client.Namespace(namespace).Get(ctx, name, metav1.GetOptions{})
+		} else {
+			obj, err = client.Get(ctx, name, metav1.GetOptions{})
+		}
+		if err != nil {
+			return WrapError(err, gvk, namespace, name, "get")
+		}
+
+		done, status, err := rolloutStatus(obj)
+		if err != nil {
+			return fmt.Errorf("rollout of %s %s/%s failed: %w", kind, namespace, name, err)
+		}
+		if done {
+			return nil
+		}
+		lastStatus = status
+
+		if time.Now().After(deadline) {
+			return &Error{
+				Kind:      ErrTimeout,
+				GVK:       gvk,
+				Namespace: namespace,
+				Name:      name,
+				Verb:      "rollout",
+				Err:       fmt.Errorf("not rolled out within %s: %s", timeout, lastStatus),
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(2 * time.Second):
+		}
+	}
+}
+
+// rolloutStatus reports whether the workload's latest revision is fully
+// rolled out, with a progress description while it isn't. A non-nil
+// error means the rollout cannot complete without intervention.
+func rolloutStatus(obj *unstructured.Unstructured) (bool, string, error) {
+	if observed, found, _ := unstructured.NestedInt64(obj.Object, "status", "observedGeneration"); found && observed < obj.GetGeneration() {
+		return false, "waiting for controller to observe the latest generation", nil
+	}
+
+	switch obj.GetKind() {
+	case "Deployment":
+		return deploymentRolloutStatus(obj)
+	case "StatefulSet":
+		return statefulSetRolloutStatus(obj)
+	case "DaemonSet":
+		return daemonSetRolloutStatus(obj)
+	case "Rollout": // Argo
+		return argoRolloutStatus(obj)
+	}
+	return false, "", fmt.Errorf("%s does not support rollout status", obj.GetKind())
+}
+
+func deploymentRolloutStatus(obj *unstructured.Unstructured) (bool, string, error) {
+	if reason := deploymentCondition(obj, "Progressing"); reason == "ProgressDeadlineExceeded" {
+		return false, "", fmt.Errorf("progress deadline exceeded")
+	}
+
+	desired := specReplicas(obj)
+	updated := statusCount(obj, "updatedReplicas")
+	replicas := statusCount(obj, "replicas")
+	available := statusCount(obj, "availableReplicas")
+	switch {
+	case updated < desired:
+		return false, fmt.Sprintf("%d of %d replicas updated", updated, desired), nil
+	case replicas > updated:
+		return false, fmt.Sprintf("%d old replicas terminating", replicas-updated), nil
+	case available < updated:
+		return false, fmt.Sprintf("%d of %d updated replicas available", available, updated), nil
+	}
+	return true, "", nil
+}
+
+func statefulSetRolloutStatus(obj *unstructured.Unstructured) (bool, string, error) {
+	desired := specReplicas(obj)
+	updated := statusCount(obj, "updatedReplicas")
+	ready := statusCount(obj, "readyReplicas")
+
+	current, _, _ := unstructured.NestedString(obj.Object, "status", "currentRevision")
+	update, _, _ := unstructured.NestedString(obj.Object, "status", "updateRevision")
+	switch {
+	case ready < desired:
+		return false, fmt.Sprintf("%d of %d replicas ready", ready, desired), nil
+	case update != "" && current != update:
+		return false, fmt.Sprintf("%d of %d replicas on revision %s", updated, desired, update), nil
+	}
+	return true, "", nil
+}
+
+func daemonSetRolloutStatus(obj *unstructured.Unstructured) (bool, string, error) {
+	desired := statusCount(obj, "desiredNumberScheduled")
+	updated := statusCount(obj, "updatedNumberScheduled")
+	available := statusCount(obj, "numberAvailable")
+	switch {
+	case updated < desired:
+		return false, fmt.Sprintf("%d of %d pods updated", updated, desired), nil
+	case available < desired:
+		return false, fmt.Sprintf("%d of %d pods available", available, desired), nil
+	}
+	return true, "", nil
+}
+
+func argoRolloutStatus(obj *unstructured.Unstructured) (bool, string, error) {
+	phase, _, _ := unstructured.NestedString(obj.Object, "status", "phase")
+	message, _, _ := unstructured.NestedString(obj.Object, "status", "message")
+	switch phase {
+	case "Healthy":
+		return true, "", nil
+	case "Degraded":
+		return false, "", fmt.Errorf("rollout degraded: %s", message)
+	}
+	if message != "" {
+		return false, fmt.Sprintf("%s: %s", phase, message), nil
+	}
+	return false, phase, nil
+}
+
+// specReplicas reads spec.replicas, defaulting to 1 like the apiserver.
+func specReplicas(obj *unstructured.Unstructured) int64 {
+	replicas, found, _ := unstructured.NestedInt64(obj.Object, "spec", "replicas")
+	if !found {
+		return 1
+	}
+	return replicas
+}
+
+func statusCount(obj *unstructured.Unstructured, field string) int64 {
+	count, _, _ := unstructured.NestedInt64(obj.Object, "status", field)
+	return count
+}
+
+// deploymentCondition returns the reason of the named status condition.
+func deploymentCondition(obj *unstructured.Unstructured, conditionType string) string {
+	conditions, _, _ := unstructured.NestedSlice(obj.Object, "status", "conditions")
+	for _, c := range conditions {
+		condition, ok := c.(map[string]any)
+		if !ok {
+			continue
+		}
+		if condition["type"] == conditionType {
+			reason, _ := condition["reason"].(string)
+			return reason
+		}
+	}
+	return ""
+}
@@ -0,0 +1,108 @@
+package kubernetes
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+
+	"github.com/flanksource/commons-db/context"
+	"github.com/flanksource/commons-db/types"
+)
+
+// bundleVersion identifies the bundle format so a future layout change
+// can stay readable.
+const bundleVersion = "v1"
+
+// Bundle is a point-in-time capture of a set of resources, suitable for
+// re-applying with Restore. Cluster-managed fields (status,
+// managedFields, resourceVersion, uid) are stripped at capture time so
+// the bundle applies cleanly to the same or another cluster.
+type Bundle struct {
+	Version   string                      `json:"version"`
+	CreatedAt time.Time                   `json:"createdAt"`
+	Selector  types.ResourceSelector      `json:"selector"`
+	Resources []unstructured.Unstructured `json:"resources"`
+}
+
+// Snapshot captures all resources matching the selector into a bundle,
+// for backup-before-change workflows: snapshot, mutate, and Restore if
+// the change goes wrong.
+func (c *Client) Snapshot(ctx context.Context, selector types.ResourceSelector) (Bundle, error) {
+	resources, err := c.QueryResources(ctx, selector)
+	if err != nil {
+		return Bundle{}, err
+	}
+
+	bundle := Bundle{
+		Version:   bundleVersion,
+		CreatedAt: time.Now().UTC(),
+		Selector:  selector,
+		Resources: make([]unstructured.Unstructured, 0, len(resources)),
+	}
+	for _, resource := range resources {
+		bundle.Resources = append(bundle.Resources, sanitizeForBundle(resource))
+	}
+	return bundle, nil
+}
+
+// Restore re-applies every resource in the bundle via server-side apply.
+// It keeps going on individual failures and reports them together, so
+// one broken resource doesn't abort the rest of the restore.
+func (c *Client) Restore(ctx context.Context, bundle Bundle) error {
+	if bundle.Version != bundleVersion {
+		return fmt.Errorf("unsupported bundle version %q (want %s)", bundle.Version, bundleVersion)
+	}
+
+	var failures []error
+	for i := range bundle.Resources {
+		obj := &bundle.Resources[i]
+		if _, err := c.Apply(ctx, obj); err != nil {
+			failures = append(failures, fmt.Errorf("%s/%s: %w", obj.GetKind(), obj.GetName(), err))
+		}
+	}
+
+	if len(failures) > 0 {
+		return fmt.Errorf("restore failed for %d of %d resources: %w", len(failures), len(bundle.Resources), errors.Join(failures...))
+	}
+	return nil
+}
+
+// Save writes the bundle as JSON.
+func (b Bundle) Save(path string) error {
+	data, err := json.MarshalIndent(b, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0o600)
+}
+
+// LoadBundle reads a bundle written by Save.
+func LoadBundle(path string) (Bundle, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return Bundle{}, err
+	}
+	var bundle Bundle
+	if err := json.Unmarshal(data, &bundle); err != nil {
+		return Bundle{}, fmt.Errorf("malformed bundle %s: %w", path, err)
+	}
+	return bundle, nil
+}
+
+// sanitizeForBundle strips fields owned by the cluster so the object
+// can be re-applied.
+func sanitizeForBundle(obj unstructured.Unstructured) unstructured.Unstructured {
+	out := *obj.DeepCopy()
+	out.SetManagedFields(nil)
+	out.SetResourceVersion("")
+	out.SetUID("")
+	out.SetCreationTimestamp(metav1.Time{})
+	unstructured.RemoveNestedField(out.Object, "status")
+	unstructured.RemoveNestedField(out.Object, "metadata", "generation")
+	return out
+}
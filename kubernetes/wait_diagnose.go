@@ -0,0 +1,55 @@
+package kubernetes
+
+import (
+	"fmt"
+	"sort"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/flanksource/commons-db/context"
+)
+
+// diagnoseStuck collects why a resource in a namespace may be stuck, for
+// inclusion in wait-timeout errors: warning events against the object
+// (FailedScheduling reasons, quota and LimitRange admission rejections
+// surface here) and exhausted ResourceQuotas. Diagnosis is best-effort —
+// lookup failures yield no reasons, never an error.
+func (c *Client) diagnoseStuck(ctx context.Context, namespace, name string) []string {
+	var reasons []string
+
+	if events, err := c.CoreV1().Events(namespace).List(ctx, metav1.ListOptions{
+		FieldSelector: fmt.Sprintf("involvedObject.name=%s,type=Warning", name),
+	}); err == nil {
+		// keep the latest message per reason; repeated scheduler retries
+		// produce hundreds of identical events
+		latest := map[string]string{}
+		for _, event := range events.Items {
+			latest[event.Reason] = event.Message
+		}
+		for _, reason := range sortedKeys(latest) {
+			reasons = append(reasons, fmt.Sprintf("%s: %s", reason, latest[reason]))
+		}
+	}
+
+	if quotas, err := c.CoreV1().ResourceQuotas(namespace).List(ctx, metav1.ListOptions{}); err == nil {
+		for _, quota := range quotas.Items {
+			for resource, hard := range quota.Status.Hard {
+				used, ok := quota.Status.Used[resource]
+				if ok && used.Cmp(hard) >= 0 {
+					reasons = append(reasons, fmt.Sprintf("quota %s exhausted: %s %s/%s used", quota.Name, resource, used.String(), hard.String()))
+				}
+			}
+		}
+	}
+
+	return reasons
+}
+
+func sortedKeys(m map[string]string) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
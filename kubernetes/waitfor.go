@@ -0,0 +1,139 @@
+package kubernetes
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/cel-go/cel"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+
+	"github.com/flanksource/commons-db/context"
+)
+
+// Condition decides whether a live object satisfies a wait. Returning an
+// error aborts the wait immediately.
+type Condition func(obj *unstructured.Unstructured) (bool, error)
+
+// celEnv exposes the unstructured object's top-level sections to CEL
+// expressions; built once since environments are immutable.
+var celEnv = sync.OnceValues(func() (*cel.Env, error) {
+	return cel.NewEnv(
+		cel.Variable("metadata", cel.DynType),
+		cel.Variable("spec", cel.DynType),
+		cel.Variable("status", cel.DynType),
+	)
+})
+
+// CELCondition compiles a CEL expression over the object into a
+// Condition, e.g.
+//
+//	status.readyReplicas == spec.replicas &&
+//	  status.conditions.exists(c, c.type == 'Available' && c.status == 'True')
+//
+// The expression is compiled once; evaluation errors from missing fields
+// (common while a resource is still initializing) count as "not yet"
+// rather than failing the wait.
+func CELCondition(expression string) (Condition, error) {
+	env, err := celEnv()
+	if err != nil {
+		return nil, err
+	}
+
+	ast, issues := env.Compile(expression)
+	if issues != nil && issues.Err() != nil {
+		return nil, fmt.Errorf("invalid wait condition %q: %w", expression, issues.Err())
+	}
+	if ast.OutputType() != cel.BoolType {
+		return nil, fmt.Errorf("wait condition %q must evaluate to bool, got %s", expression, ast.OutputType())
+	}
+
+	program, err := env.Program(ast)
+	if err != nil {
+		return nil, fmt.Errorf("failed to compile wait condition %q: %w", expression, err)
+	}
+
+	return func(obj *unstructured.Unstructured) (bool, error) {
+		out, _, err := program.Eval(map[string]any{
+			"metadata": obj.Object["metadata"],
+			"spec":     obj.Object["spec"],
+			"status":   obj.Object["status"],
+		})
+		if err != nil {
+			// missing status fields resolve as errors until the
+			// controller populates them
+			return false, nil
+		}
+		result, ok := out.Value().(bool)
+		return ok && result, nil
+	}, nil
+}
+
+// HealthyCondition waits for CheckHealth to report the object healthy.
+func HealthyCondition() Condition {
+	return func(obj *unstructured.Unstructured) (bool, error) {
+		return CheckHealth(obj).Healthy, nil
+	}
+}
+
+// WaitFor polls the object until the condition holds, the context is
+// cancelled, or the timeout elapses. The expression form is a
+// convenience over WaitForCondition for callers with declarative waits.
+func (c *Client) WaitFor(ctx context.Context, obj *unstructured.Unstructured, expression string, timeout time.Duration) error {
+	condition, err := CELCondition(expression)
+	if err != nil {
+		return err
+	}
+	return c.WaitForCondition(ctx, obj, condition, timeout)
+}
+
+// WaitForCondition polls the object every 2s until the condition holds.
+func (c *Client) WaitForCondition(ctx context.Context, obj *unstructured.Unstructured, condition Condition, timeout time.Duration) error {
+	if timeout <= 0 {
+		timeout = 5 * time.Minute
+	}
+
+	client, err := c.resourceInterface(obj)
+	if err != nil {
+		return err
+	}
+
+	deadline := time.Now().Add(timeout)
+	for {
+		live, err := client.Get(ctx, obj.GetName(), metav1.GetOptions{})
+		if err != nil {
+			return WrapError(err, obj.GroupVersionKind(), obj.GetNamespace(), obj.GetName(), "get")
+		}
+
+		done, err := condition(live)
+		if err != nil {
+			return fmt.Errorf("wait condition for %s/%s failed: %w", obj.GetKind(), obj.GetName(), err)
+		}
+		if done {
+			return nil
+		}
+
+		if time.Now().After(deadline) {
+			cause := fmt.Errorf("condition not met within %s", timeout)
+			if reasons := c.diagnoseStuck(ctx, obj.GetNamespace(), obj.GetName()); len(reasons) > 0 {
+				cause = fmt.Errorf("condition not met within %s:\n  %s", timeout, strings.Join(reasons, "\n  "))
+			}
+			return &Error{
+				Kind:      ErrTimeout,
+				GVK:       obj.GroupVersionKind(),
+				Namespace: obj.GetNamespace(),
+				Name:      obj.GetName(),
+				Verb:      "wait",
+				Err:       cause,
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(2 * time.Second):
+		}
+	}
+}
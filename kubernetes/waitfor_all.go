@@ -0,0 +1,150 @@
+package kubernetes
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+
+	"github.com/flanksource/commons-db/context"
+)
+
+// HealthTransition is one observed change in a resource's health while
+// WaitForAll is polling it.
+type HealthTransition struct {
+	Kind      string    `json:"kind"`
+	Namespace string    `json:"namespace,omitempty"`
+	Name      string    `json:"name"`
+	From      Health    `json:"from"`
+	To        Health    `json:"to"`
+	At        time.Time `json:"at"`
+}
+
+func (t HealthTransition) String() string {
+	return fmt.Sprintf("%s/%s: %s -> %s", t.Kind, t.Name, t.From.Status, t.To.Status)
+}
+
+// WaitResult is the final state of one resource after WaitForAll.
+type WaitResult struct {
+	Kind      string `json:"kind"`
+	Namespace string `json:"namespace,omitempty"`
+	Name      string `json:"name"`
+	Health    Health `json:"health"`
+	Error     string `json:"error,omitempty"`
+}
+
+// WaitAllReport is the consolidated outcome of WaitForAll, with one
+// result per resource in input order.
+type WaitAllReport struct {
+	Results []WaitResult  `json:"results"`
+	Healthy int           `json:"healthy"`
+	Failed  int           `json:"failed"`
+	Elapsed time.Duration `json:"elapsed"`
+}
+
+// Ready reports whether every resource became healthy.
+func (r *WaitAllReport) Ready() bool {
+	return r.Failed == 0
+}
+
+// WaitForAll polls all resources concurrently until each is healthy
+// (per CheckHealth) or the timeout elapses, instead of callers looping
+// over WaitForCondition one resource at a time. Every health change is
+// streamed to onTransition as it is observed — including the first
+// observation, whose From is the zero Health — so callers can render
+// progress; the callback may be nil and is invoked from multiple
+// goroutines. The report always covers every resource, alongside an
+// error when any of them did not become healthy.
+func (c *Client) WaitForAll(ctx context.Context, resources []unstructured.Unstructured, timeout time.Duration, onTransition func(HealthTransition)) (*WaitAllReport, error) {
+	if timeout <= 0 {
+		timeout = 5 * time.Minute
+	}
+
+	started := time.Now()
+	deadline := started.Add(timeout)
+	report := &WaitAllReport{Results: make([]WaitResult, len(resources))}
+
+	var wg sync.WaitGroup
+	for i := range resources {
+		obj := &resources[i]
+		report.Results[i] = WaitResult{Kind: obj.GetKind(), Namespace: obj.GetNamespace(), Name: obj.GetName()}
+
+		wg.Add(1)
+		go func(result *WaitResult) {
+			defer wg.Done()
+			c.waitOne(ctx, obj, deadline, result, onTransition)
+		}(&report.Results[i])
+	}
+	wg.Wait()
+
+	report.Elapsed = time.Since(started)
+	for _, result := range report.Results {
+		if result.Health.Healthy && result.Error == "" {
+			report.Healthy++
+		} else {
+			report.Failed++
+		}
+	}
+
+	if report.Failed > 0 {
+		return report, fmt.Errorf("%d of %d resources not healthy after %s", report.Failed, len(resources), timeout)
+	}
+	return report, nil
+}
+
+// waitOne polls a single resource every 2s, recording health transitions
+// until it is healthy, the deadline passes, or the context is cancelled.
+func (c *Client) waitOne(ctx context.Context, obj *unstructured.Unstructured, deadline time.Time, result *WaitResult, onTransition func(HealthTransition)) {
+	client, err := c.resourceInterface(obj)
+	if err != nil {
+		result.Error = err.Error()
+		return
+	}
+
+	var last Health
+	for {
+		live, err := client.Get(ctx, obj.GetName(), metav1.GetOptions{})
+		if err != nil {
+			result.Error = WrapError(err, obj.GroupVersionKind(), obj.GetNamespace(), obj.GetName(), "get").Error()
+			return
+		}
+
+		health := CheckHealth(live)
+		if !health.Equals(last) {
+			if onTransition != nil {
+				onTransition(HealthTransition{
+					Kind:      obj.GetKind(),
+					Namespace: obj.GetNamespace(),
+					Name:      obj.GetName(),
+					From:      last,
+					To:        health,
+					At:        time.Now(),
+				})
+			}
+			last = health
+		}
+		result.Health = health
+		if health.Healthy {
+			return
+		}
+
+		if time.Now().After(deadline) {
+			result.Health = c.WithEvents(ctx, obj, health)
+			result.Error = fmt.Sprintf("not healthy within deadline: %s", health.Status)
+			if reasons := c.diagnoseStuck(ctx, obj.GetNamespace(), obj.GetName()); len(reasons) > 0 {
+				result.Error += "; " + strings.Join(reasons, "; ")
+			}
+			return
+		}
+
+		select {
+		case <-ctx.Done():
+			result.Error = ctx.Err().Error()
+			return
+		case <-time.After(2 * time.Second):
+		}
+	}
+}
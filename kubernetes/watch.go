@@ -0,0 +1,189 @@
+package kubernetes
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/watch"
+
+	"github.com/flanksource/commons-db/context"
+	"github.com/flanksource/commons-db/types"
+)
+
+// EventType classifies a ResourceEvent.
+type EventType string
+
+const (
+	EventAdded    EventType = "Added"
+	EventModified EventType = "Modified"
+	EventDeleted  EventType = "Deleted"
+)
+
+// ResourceEvent is one change to a resource matched by a watch, with
+// its health evaluated at event time (the same enrichment QueryResources
+// consumers get from CheckHealth).
+type ResourceEvent struct {
+	Type     EventType
+	Resource unstructured.Unstructured
+	Health   Health
+}
+
+// watchEventBuffer bounds the event channel; a consumer that stalls for
+// long blocks the watch rather than growing memory.
+const watchEventBuffer = 64
+
+// WatchResources converts the selector into list+watch calls and
+// streams add/update/delete events for the matched resources. The
+// initial list is emitted as Added events so consumers start from a
+// complete picture. Expired or dropped watches are re-established with
+// backoff; the channel closes when ctx is done.
+func (c *Client) WatchResources(ctx context.Context, selector types.ResourceSelector) (<-chan ResourceEvent, error) {
+	kinds := selectorKinds(selector)
+	if len(kinds) == 0 {
+		return nil, fmt.Errorf("resource selector has no types to watch")
+	}
+
+	events := make(chan ResourceEvent, watchEventBuffer)
+	var wg sync.WaitGroup
+
+	for _, kind := range kinds {
+		gvr, namespaced, err := c.ResourceForKind(kind)
+		if err != nil {
+			return nil, err
+		}
+
+		namespaces := strings.Split(selector.Namespace, ",")
+		if !namespaced {
+			namespaces = []string{""}
+		}
+
+		for _, namespace := range namespaces {
+			wg.Add(1)
+			go func(namespace string) {
+				defer wg.Done()
+				c.watchLoop(ctx, gvr, kind, strings.TrimSpace(namespace), selector, events)
+			}(namespace)
+		}
+	}
+
+	go func() {
+		wg.Wait()
+		close(events)
+	}()
+	return events, nil
+}
+
+// watchLoop runs list+watch for one kind × namespace until ctx is done,
+// re-listing whenever the watch drops or expires.
+func (c *Client) watchLoop(ctx context.Context, gvr schema.GroupVersionResource, kind, namespace string, selector types.ResourceSelector, events chan<- ResourceEvent) {
+	listOptions := metav1.ListOptions{
+		LabelSelector: selector.LabelSelector,
+		FieldSelector: selector.FieldSelector,
+	}
+
+	backoff := time.Second
+	for {
+		list, err := c.dynamicClient.Resource(gvr).Namespace(namespace).List(ctx, listOptions)
+		if err != nil {
+			if ctx.Err() != nil {
+				return
+			}
+			ctx.Warnf("watch of %s in %q: list failed, retrying in %s: %v", kind, namespace, backoff, err)
+			if !sleep(ctx, backoff) {
+				return
+			}
+			backoff = min(backoff*2, 30*time.Second)
+			continue
+		}
+		backoff = time.Second
+
+		for _, item := range list.Items {
+			if !emit(ctx, events, EventAdded, item, selector) {
+				return
+			}
+		}
+
+		watchOptions := listOptions
+		watchOptions.ResourceVersion = list.GetResourceVersion()
+		watcher, err := c.dynamicClient.Resource(gvr).Namespace(namespace).Watch(ctx, watchOptions)
+		if err != nil {
+			if ctx.Err() != nil {
+				return
+			}
+			ctx.Warnf("failed to start watch of %s in %q, re-listing: %v", kind, namespace, err)
+			continue
+		}
+
+		if !c.consumeWatch(ctx, watcher, selector, events) {
+			return
+		}
+		// watch expired or errored; fall through to re-list
+	}
+}
+
+// consumeWatch forwards watcher events until the watch drops (returns
+// true, caller re-lists) or ctx is done (returns false).
+func (c *Client) consumeWatch(ctx context.Context, watcher watch.Interface, selector types.ResourceSelector, events chan<- ResourceEvent) bool {
+	defer watcher.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return false
+		case event, ok := <-watcher.ResultChan():
+			if !ok || event.Type == watch.Error {
+				return true
+			}
+
+			var eventType EventType
+			switch event.Type {
+			case watch.Added:
+				eventType = EventAdded
+			case watch.Modified:
+				eventType = EventModified
+			case watch.Deleted:
+				eventType = EventDeleted
+			default: // bookmarks
+				continue
+			}
+
+			item, ok := event.Object.(*unstructured.Unstructured)
+			if !ok {
+				continue
+			}
+			if !emit(ctx, events, eventType, *item, selector) {
+				return false
+			}
+		}
+	}
+}
+
+// emit applies the selector's name filter, enriches with health, and
+// sends; returns false when ctx is done.
+func emit(ctx context.Context, events chan<- ResourceEvent, eventType EventType, item unstructured.Unstructured, selector types.ResourceSelector) bool {
+	if !matchesName(selector.Name, item.GetName()) {
+		return true
+	}
+
+	select {
+	case events <- ResourceEvent{Type: eventType, Resource: item, Health: CheckHealth(&item)}:
+		return true
+	case <-ctx.Done():
+		return false
+	}
+}
+
+// sleep waits for the duration, returning false when ctx is done first.
+func sleep(ctx context.Context, d time.Duration) bool {
+	select {
+	case <-time.After(d):
+		return true
+	case <-ctx.Done():
+		return false
+	}
+}
@@ -0,0 +1,242 @@
+// Package cache is a local response cache for LLM calls, backed by a
+// SQLite database (~/.cache/commons-llm.db by default) that may be
+// shared by multiple concurrent CLI processes.
+//
+// Cross-process safety:
+//   - the database runs in WAL mode and every operation is wrapped in a
+//     busy-retry loop so SQLITE_BUSY from a sibling process degrades
+//     into a short wait instead of an error
+//   - writes additionally take an advisory flock on a sidecar .lock
+//     file, which also serializes WAL checkpoints
+//   - a background checkpointer truncates the WAL periodically so it
+//     cannot grow without bound under constant write load
+package cache
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	_ "modernc.org/sqlite"
+)
+
+const (
+	busyRetries   = 10
+	busyBaseDelay = 50 * time.Millisecond
+
+	// DefaultCheckpointInterval is how often the WAL is truncated.
+	DefaultCheckpointInterval = 5 * time.Minute
+)
+
+// Cache is a SQLite-backed LLM response cache.
+type Cache struct {
+	db   *sql.DB
+	lock *fileLock
+	done chan struct{}
+}
+
+// DefaultPath returns the shared per-user cache location.
+func DefaultPath() string {
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		dir = os.TempDir()
+	}
+	return filepath.Join(dir, "commons-llm.db")
+}
+
+// Open opens (creating if needed) the cache at path, or at DefaultPath()
+// when path is empty.
+func Open(path string) (*Cache, error) {
+	if path == "" {
+		path = DefaultPath()
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return nil, err
+	}
+
+	db, err := sql.Open("sqlite", path+"?_pragma=journal_mode(WAL)&_pragma=busy_timeout(5000)")
+	if err != nil {
+		return nil, fmt.Errorf("failed to open llm cache %s: %w", path, err)
+	}
+
+	c := &Cache{
+		db:   db,
+		lock: newFileLock(path + ".lock"),
+		done: make(chan struct{}),
+	}
+	if err := c.withBusyRetry(c.createSchema); err != nil {
+		_ = db.Close()
+		return nil, err
+	}
+
+	go c.checkpointLoop(DefaultCheckpointInterval)
+	return c, nil
+}
+
+func (c *Cache) Close() error {
+	close(c.done)
+	// best-effort final checkpoint so the next process starts clean
+	_ = c.Checkpoint()
+	return c.db.Close()
+}
+
+func (c *Cache) createSchema() error {
+	_, err := c.db.Exec(`
+		CREATE TABLE IF NOT EXISTS responses (
+			key               TEXT PRIMARY KEY,
+			model             TEXT NOT NULL,
+			response          TEXT NOT NULL,
+			prompt_tokens     INTEGER NOT NULL DEFAULT 0,
+			completion_tokens INTEGER NOT NULL DEFAULT 0,
+			cost              REAL NOT NULL DEFAULT 0,
+			duration_ms       INTEGER NOT NULL DEFAULT 0,
+			hit               INTEGER NOT NULL DEFAULT 0,
+			created_at        TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+			expires_at        TIMESTAMP
+		)`)
+	if err != nil {
+		return err
+	}
+
+	_, err = c.db.Exec(`
+		CREATE TABLE IF NOT EXISTS namespaces (
+			name       TEXT PRIMARY KEY,
+			generation INTEGER NOT NULL DEFAULT 0
+		)`)
+	if err != nil {
+		return err
+	}
+
+	_, err = c.db.Exec(`
+		CREATE TABLE IF NOT EXISTS sessions (
+			id         TEXT PRIMARY KEY,
+			data       TEXT NOT NULL,
+			updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+			expires_at TIMESTAMP
+		)`)
+	return err
+}
+
+// Entry is a cached response with its accounting metadata.
+type Entry struct {
+	Key              string
+	Model            string
+	Response         json.RawMessage
+	PromptTokens     int
+	CompletionTokens int
+	Cost             float64
+	Duration         time.Duration
+}
+
+// Get returns the cached entry for key, if present and unexpired. Cache
+// hits increment the hit counter used by usage stats.
+func (c *Cache) Get(key string) (*Entry, bool, error) {
+	var entry Entry
+	var durationMs int64
+	var response string
+
+	err := c.withBusyRetry(func() error {
+		row := c.db.QueryRow(`
+			SELECT key, model, response, prompt_tokens, completion_tokens, cost, duration_ms
+			FROM responses
+			WHERE key = ? AND (expires_at IS NULL OR expires_at > CURRENT_TIMESTAMP)`, key)
+		return row.Scan(&entry.Key, &entry.Model, &response, &entry.PromptTokens, &entry.CompletionTokens, &entry.Cost, &durationMs)
+	})
+	if err == sql.ErrNoRows {
+		return nil, false, nil
+	}
+	if err != nil {
+		return nil, false, err
+	}
+
+	entry.Response = json.RawMessage(response)
+	entry.Duration = time.Duration(durationMs) * time.Millisecond
+
+	_ = c.withBusyRetry(func() error {
+		_, err := c.db.Exec("UPDATE responses SET hit = hit + 1 WHERE key = ?", key)
+		return err
+	})
+	return &entry, true, nil
+}
+
+// Set stores an entry, replacing any previous value for the key.
+func (c *Cache) Set(entry Entry, ttl time.Duration) error {
+	return c.withLock(func() error {
+		return c.withBusyRetry(func() error {
+			var expiresAt any
+			if ttl > 0 {
+				expiresAt = time.Now().Add(ttl).UTC()
+			}
+			_, err := c.db.Exec(`
+				INSERT INTO responses (key, model, response, prompt_tokens, completion_tokens, cost, duration_ms, expires_at)
+				VALUES (?, ?, ?, ?, ?, ?, ?, ?)
+				ON CONFLICT (key) DO UPDATE SET
+					model = excluded.model, response = excluded.response,
+					prompt_tokens = excluded.prompt_tokens, completion_tokens = excluded.completion_tokens,
+					cost = excluded.cost, duration_ms = excluded.duration_ms,
+					created_at = CURRENT_TIMESTAMP, expires_at = excluded.expires_at`,
+				entry.Key, entry.Model, string(entry.Response),
+				entry.PromptTokens, entry.CompletionTokens, entry.Cost,
+				entry.Duration.Milliseconds(), expiresAt)
+			return err
+		})
+	})
+}
+
+// Checkpoint truncates the WAL, under the advisory lock so only one
+// process checkpoints at a time.
+func (c *Cache) Checkpoint() error {
+	return c.withLock(func() error {
+		return c.withBusyRetry(func() error {
+			_, err := c.db.Exec("PRAGMA wal_checkpoint(TRUNCATE)")
+			return err
+		})
+	})
+}
+
+func (c *Cache) checkpointLoop(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-c.done:
+			return
+		case <-ticker.C:
+			_ = c.Checkpoint()
+		}
+	}
+}
+
+// withBusyRetry retries fn while SQLite reports the database as busy or
+// locked by a sibling process.
+func (c *Cache) withBusyRetry(fn func() error) error {
+	var err error
+	for attempt := 0; attempt < busyRetries; attempt++ {
+		if err = fn(); err == nil || !isBusy(err) {
+			return err
+		}
+		time.Sleep(busyBaseDelay * time.Duration(attempt+1))
+	}
+	return fmt.Errorf("llm cache is busy: %w", err)
+}
+
+func isBusy(err error) bool {
+	if err == nil {
+		return false
+	}
+	msg := err.Error()
+	return strings.Contains(msg, "database is locked") || strings.Contains(msg, "SQLITE_BUSY")
+}
+
+// withLock runs fn holding the advisory lock file.
+func (c *Cache) withLock(fn func() error) error {
+	if err := c.lock.Lock(); err != nil {
+		return fmt.Errorf("failed to acquire llm cache lock: %w", err)
+	}
+	defer c.lock.Unlock() //nolint:errcheck
+	return fn()
+}
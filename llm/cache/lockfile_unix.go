@@ -0,0 +1,56 @@
+//go:build unix
+
+package cache
+
+import (
+	"os"
+	"sync"
+	"syscall"
+)
+
+// fileLock is an advisory cross-process lock backed by flock(2). The
+// mutex serializes in-process holders (writers and the background
+// checkpointer share one fileLock), so l.file is only ever touched by
+// the goroutine currently holding the lock.
+type fileLock struct {
+	path string
+
+	mu   sync.Mutex
+	file *os.File
+}
+
+func newFileLock(path string) *fileLock {
+	return &fileLock{path: path}
+}
+
+func (l *fileLock) Lock() error {
+	l.mu.Lock()
+
+	file, err := os.OpenFile(l.path, os.O_CREATE|os.O_RDWR, 0o644)
+	if err != nil {
+		l.mu.Unlock()
+		return err
+	}
+	if err := syscall.Flock(int(file.Fd()), syscall.LOCK_EX); err != nil {
+		_ = file.Close()
+		l.mu.Unlock()
+		return err
+	}
+	l.file = file
+	return nil
+}
+
+func (l *fileLock) Unlock() error {
+	if l.file == nil {
+		return nil
+	}
+	defer l.mu.Unlock()
+
+	file := l.file
+	l.file = nil
+	if err := syscall.Flock(int(file.Fd()), syscall.LOCK_UN); err != nil {
+		_ = file.Close()
+		return err
+	}
+	return file.Close()
+}
@@ -0,0 +1,16 @@
+//go:build windows
+
+package cache
+
+// Windows relies on SQLite's own locking; the advisory lock file is a
+// no-op there.
+type fileLock struct {
+	path string
+}
+
+func newFileLock(path string) *fileLock {
+	return &fileLock{path: path}
+}
+
+func (l *fileLock) Lock() error   { return nil }
+func (l *fileLock) Unlock() error { return nil }
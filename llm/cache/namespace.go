@@ -0,0 +1,107 @@
+package cache
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+)
+
+// Namespaced keys: entries can be grouped under a namespace (e.g.
+// "cluster:prod") so switching kubeconfigs or agents can drop all
+// related entries without clearing unrelated ones.
+//
+// Eviction is O(1): each namespace has a generation counter and keys are
+// stored as "<namespace>@<generation>/<key>". Evicting bumps the
+// generation, instantly orphaning every key written under the old one;
+// orphaned rows are swept opportunistically by Purge.
+
+// Namespace is a view of the cache whose keys are scoped to a named
+// group. The zero value is not usable; obtain one from Cache.Namespace.
+type Namespace struct {
+	cache *Cache
+	name  string
+}
+
+// Namespace returns a scoped view of the cache. Entries written through
+// the view are invisible to other namespaces and to the root cache.
+func (c *Cache) Namespace(name string) *Namespace {
+	return &Namespace{cache: c, name: name}
+}
+
+// Get returns the cached entry for key within this namespace.
+func (n *Namespace) Get(key string) (*Entry, bool, error) {
+	scoped, err := n.scopedKey(key)
+	if err != nil {
+		return nil, false, err
+	}
+	return n.cache.Get(scoped)
+}
+
+// Set stores an entry under this namespace's current generation.
+func (n *Namespace) Set(entry Entry, ttl time.Duration) error {
+	scoped, err := n.scopedKey(entry.Key)
+	if err != nil {
+		return err
+	}
+	entry.Key = scoped
+	return n.cache.Set(entry, ttl)
+}
+
+// Evict invalidates every entry in the namespace by bumping its
+// generation. The orphaned rows stay on disk until the next Purge.
+func (n *Namespace) Evict() error {
+	return n.cache.withLock(func() error {
+		return n.cache.withBusyRetry(func() error {
+			_, err := n.cache.db.Exec(`
+				INSERT INTO namespaces (name, generation) VALUES (?, 1)
+				ON CONFLICT (name) DO UPDATE SET generation = generation + 1`, n.name)
+			return err
+		})
+	})
+}
+
+func (n *Namespace) scopedKey(key string) (string, error) {
+	generation, err := n.cache.generation(n.name)
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("%s@%d/%s", n.name, generation, key), nil
+}
+
+func (c *Cache) generation(namespace string) (int64, error) {
+	var generation int64
+	err := c.withBusyRetry(func() error {
+		row := c.db.QueryRow("SELECT generation FROM namespaces WHERE name = ?", namespace)
+		return row.Scan(&generation)
+	})
+	if err == sql.ErrNoRows {
+		return 0, nil
+	}
+	return generation, err
+}
+
+// Purge deletes expired entries and entries orphaned by namespace
+// eviction. It is safe to run concurrently with readers and writers.
+func (c *Cache) Purge() error {
+	return c.withLock(func() error {
+		return c.withBusyRetry(func() error {
+			if _, err := c.db.Exec("DELETE FROM responses WHERE expires_at IS NOT NULL AND expires_at <= CURRENT_TIMESTAMP"); err != nil {
+				return err
+			}
+
+			if _, err := c.db.Exec("DELETE FROM sessions WHERE expires_at IS NOT NULL AND expires_at <= CURRENT_TIMESTAMP"); err != nil {
+				return err
+			}
+
+			// a namespaced key whose embedded generation trails the
+			// namespace's current one is unreachable
+			_, err := c.db.Exec(`
+				DELETE FROM responses WHERE key IN (
+					SELECT r.key FROM responses r
+					JOIN namespaces n ON r.key LIKE n.name || '@%/%'
+					WHERE CAST(substr(r.key, length(n.name) + 2, instr(r.key, '/') - length(n.name) - 2) AS INTEGER) < n.generation
+				)`)
+			return err
+		})
+	})
+}
@@ -0,0 +1,59 @@
+package cache
+
+import (
+	"database/sql"
+	"encoding/json"
+	"time"
+)
+
+// Session persistence: conversation state keyed by session id, stored
+// alongside the response cache so resumable CLIs need no extra
+// database. The payload is opaque JSON owned by the llm package.
+
+// GetSession returns the stored payload for a session id, if present
+// and unexpired.
+func (c *Cache) GetSession(id string) (json.RawMessage, bool, error) {
+	var data string
+	err := c.withBusyRetry(func() error {
+		row := c.db.QueryRow(`
+			SELECT data FROM sessions
+			WHERE id = ? AND (expires_at IS NULL OR expires_at > CURRENT_TIMESTAMP)`, id)
+		return row.Scan(&data)
+	})
+	if err == sql.ErrNoRows {
+		return nil, false, nil
+	}
+	if err != nil {
+		return nil, false, err
+	}
+	return json.RawMessage(data), true, nil
+}
+
+// SetSession stores a session payload, resetting its TTL.
+func (c *Cache) SetSession(id string, data json.RawMessage, ttl time.Duration) error {
+	return c.withLock(func() error {
+		return c.withBusyRetry(func() error {
+			var expiresAt any
+			if ttl > 0 {
+				expiresAt = time.Now().Add(ttl).UTC()
+			}
+			_, err := c.db.Exec(`
+				INSERT INTO sessions (id, data, expires_at)
+				VALUES (?, ?, ?)
+				ON CONFLICT (id) DO UPDATE SET
+					data = excluded.data, updated_at = CURRENT_TIMESTAMP, expires_at = excluded.expires_at`,
+				id, string(data), expiresAt)
+			return err
+		})
+	})
+}
+
+// DeleteSession removes a session.
+func (c *Cache) DeleteSession(id string) error {
+	return c.withLock(func() error {
+		return c.withBusyRetry(func() error {
+			_, err := c.db.Exec("DELETE FROM sessions WHERE id = ?", id)
+			return err
+		})
+	})
+}
@@ -0,0 +1,138 @@
+package cache
+
+import (
+	"fmt"
+	"time"
+)
+
+// ModelStats is the performance profile of a single model computed from
+// cached responses.
+type ModelStats struct {
+	Model     string  `json:"model"`
+	Requests  int64   `json:"requests"`
+	CacheHits int64   `json:"cache_hits"`
+	HitRate   float64 `json:"hit_rate"`
+	Cost      float64 `json:"cost"`
+
+	// Duration percentiles of the underlying (non-cached) calls.
+	P50 time.Duration `json:"p50"`
+	P95 time.Duration `json:"p95"`
+	P99 time.Duration `json:"p99"`
+
+	// TokenBuckets is a histogram of total tokens per request.
+	TokenBuckets map[string]int64 `json:"token_buckets"`
+}
+
+// tokenBucketBounds define the histogram: label -> upper bound
+// (exclusive), in ascending order.
+var tokenBucketBounds = []struct {
+	label string
+	upper int64
+}{
+	{"<1k", 1_000},
+	{"1k-4k", 4_000},
+	{"4k-16k", 16_000},
+	{">16k", 1 << 62},
+}
+
+// GetStats computes per-model request counts, hit rates, duration
+// percentiles and token histograms using SQL window functions, so
+// prompt performance regressions are visible without external tooling.
+func (c *Cache) GetStats() ([]ModelStats, error) {
+	type statRow struct {
+		model               string
+		requests, cacheHits int64
+		cost                float64
+		p50, p95, p99       int64
+	}
+
+	var rows []statRow
+	err := c.withBusyRetry(func() error {
+		// percentiles via row_number over the per-model duration
+		// ordering: the p-th percentile is the value at ceil(p * count)
+		result, err := c.db.Query(`
+			WITH ranked AS (
+				SELECT model, duration_ms, cost, hit,
+					ROW_NUMBER() OVER (PARTITION BY model ORDER BY duration_ms) AS rn,
+					COUNT(*) OVER (PARTITION BY model) AS total
+				FROM responses
+			)
+			SELECT model,
+				MAX(total),
+				SUM(hit),
+				SUM(cost),
+				MAX(CASE WHEN rn = (total * 50 + 99) / 100 THEN duration_ms ELSE 0 END),
+				MAX(CASE WHEN rn = (total * 95 + 99) / 100 THEN duration_ms ELSE 0 END),
+				MAX(CASE WHEN rn = (total * 99 + 99) / 100 THEN duration_ms ELSE 0 END)
+			FROM ranked
+			GROUP BY model
+			ORDER BY model`)
+		if err != nil {
+			return err
+		}
+		defer result.Close() //nolint:errcheck
+
+		rows = rows[:0]
+		for result.Next() {
+			var row statRow
+			if err := result.Scan(&row.model, &row.requests, &row.cacheHits, &row.cost, &row.p50, &row.p95, &row.p99); err != nil {
+				return err
+			}
+			rows = append(rows, row)
+		}
+		return result.Err()
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to compute llm cache stats: %w", err)
+	}
+
+	stats := make([]ModelStats, 0, len(rows))
+	for _, row := range rows {
+		stat := ModelStats{
+			Model:        row.model,
+			Requests:     row.requests,
+			CacheHits:    row.cacheHits,
+			Cost:         row.cost,
+			P50:          time.Duration(row.p50) * time.Millisecond,
+			P95:          time.Duration(row.p95) * time.Millisecond,
+			P99:          time.Duration(row.p99) * time.Millisecond,
+			TokenBuckets: map[string]int64{},
+		}
+		if total := row.requests + row.cacheHits; total > 0 {
+			stat.HitRate = float64(row.cacheHits) / float64(total)
+		}
+
+		if err := c.tokenHistogram(row.model, stat.TokenBuckets); err != nil {
+			return nil, err
+		}
+		stats = append(stats, stat)
+	}
+	return stats, nil
+}
+
+func (c *Cache) tokenHistogram(model string, buckets map[string]int64) error {
+	return c.withBusyRetry(func() error {
+		result, err := c.db.Query(`
+			SELECT prompt_tokens + completion_tokens AS total_tokens, COUNT(*)
+			FROM responses WHERE model = ?
+			GROUP BY 1`, model)
+		if err != nil {
+			return err
+		}
+		defer result.Close() //nolint:errcheck
+
+		for result.Next() {
+			var tokens, count int64
+			if err := result.Scan(&tokens, &count); err != nil {
+				return err
+			}
+			for _, bucket := range tokenBucketBounds {
+				if tokens < bucket.upper {
+					buckets[bucket.label] += count
+					break
+				}
+			}
+		}
+		return result.Err()
+	})
+}
@@ -0,0 +1,50 @@
+package cache
+
+import (
+	"time"
+)
+
+// UsageRow is aggregated usage for one model on one day.
+type UsageRow struct {
+	Day              time.Time
+	Model            string
+	Requests         int64
+	CacheHits        int64
+	PromptTokens     int64
+	CompletionTokens int64
+	Cost             float64
+}
+
+// UsageSince returns per-day, per-model usage aggregates for entries
+// created at or after since.
+func (c *Cache) UsageSince(since time.Time) ([]UsageRow, error) {
+	var rows []UsageRow
+	err := c.withBusyRetry(func() error {
+		result, err := c.db.Query(`
+			SELECT date(created_at) AS day, model,
+				COUNT(*), SUM(hit),
+				SUM(prompt_tokens), SUM(completion_tokens), SUM(cost)
+			FROM responses
+			WHERE created_at >= ?
+			GROUP BY day, model
+			ORDER BY day, model`, since.UTC())
+		if err != nil {
+			return err
+		}
+		defer result.Close() //nolint:errcheck
+
+		rows = rows[:0]
+		for result.Next() {
+			var row UsageRow
+			var day string
+			if err := result.Scan(&day, &row.Model, &row.Requests, &row.CacheHits,
+				&row.PromptTokens, &row.CompletionTokens, &row.Cost); err != nil {
+				return err
+			}
+			row.Day, _ = time.Parse("2006-01-02", day)
+			rows = append(rows, row)
+		}
+		return result.Err()
+	})
+	return rows, err
+}
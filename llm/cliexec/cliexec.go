@@ -0,0 +1,162 @@
+// Package cliexec runs CLI-based LLM providers (and similar tools) as
+// subprocesses with the process-lifecycle hygiene that ad-hoc exec.Command
+// usage tends to get wrong:
+//
+//   - the child runs in its own process group, and the whole group is
+//     killed on context cancellation (SIGTERM, then SIGKILL after a grace
+//     period) so helper processes spawned by the CLI don't outlive us
+//   - stdout/stderr are captured into bounded buffers so a runaway child
+//     cannot exhaust memory
+//   - exit codes are mapped into a typed ExitError instead of being
+//     string-matched out of exec.ExitError
+package cliexec
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os/exec"
+	"time"
+)
+
+const (
+	// DefaultMaxOutputBytes bounds captured stdout and stderr (each).
+	DefaultMaxOutputBytes = 10 * 1024 * 1024
+
+	// DefaultGracePeriod is how long a child gets between SIGTERM and
+	// SIGKILL on cancellation.
+	DefaultGracePeriod = 5 * time.Second
+)
+
+// Command describes a subprocess invocation.
+type Command struct {
+	Name  string
+	Args  []string
+	Env   []string // appended to the parent environment
+	Dir   string
+	Stdin io.Reader
+
+	// MaxOutputBytes bounds each of stdout and stderr;
+	// DefaultMaxOutputBytes when zero.
+	MaxOutputBytes int64
+
+	// GracePeriod between SIGTERM and SIGKILL on cancellation;
+	// DefaultGracePeriod when zero.
+	GracePeriod time.Duration
+}
+
+// Result is the outcome of a completed subprocess.
+type Result struct {
+	Stdout    []byte
+	Stderr    []byte
+	ExitCode  int
+	Truncated bool // either stream hit MaxOutputBytes
+	Duration  time.Duration
+}
+
+// ExitError is returned when the child exits non-zero.
+type ExitError struct {
+	Code   int
+	Stderr string
+}
+
+func (e *ExitError) Error() string {
+	if e.Stderr == "" {
+		return fmt.Sprintf("exited with code %d", e.Code)
+	}
+	return fmt.Sprintf("exited with code %d: %s", e.Code, e.Stderr)
+}
+
+// Run executes the command and waits for it to finish. On context
+// cancellation the child's process group is terminated and Run returns
+// ctx.Err(); the goroutine reading output never leaks because the kill
+// closes the child's pipes.
+func Run(ctx context.Context, command Command) (*Result, error) {
+	if command.MaxOutputBytes <= 0 {
+		command.MaxOutputBytes = DefaultMaxOutputBytes
+	}
+	if command.GracePeriod <= 0 {
+		command.GracePeriod = DefaultGracePeriod
+	}
+
+	cmd := exec.CommandContext(ctx, command.Name, command.Args...)
+	cmd.Dir = command.Dir
+	cmd.Stdin = command.Stdin
+	if len(command.Env) > 0 {
+		cmd.Env = append(cmd.Environ(), command.Env...)
+	}
+
+	stdout := newBoundedBuffer(command.MaxOutputBytes)
+	stderr := newBoundedBuffer(command.MaxOutputBytes)
+	cmd.Stdout = stdout
+	cmd.Stderr = stderr
+
+	setProcessGroup(cmd)
+
+	// Cancel/WaitDelay give us TERM-then-KILL semantics without racing
+	// Wait against our own kill goroutine.
+	cmd.Cancel = func() error { return terminateProcessGroup(cmd) }
+	cmd.WaitDelay = command.GracePeriod
+
+	started := time.Now()
+	err := cmd.Run()
+
+	result := &Result{
+		Stdout:    stdout.Bytes(),
+		Stderr:    stderr.Bytes(),
+		Truncated: stdout.truncated || stderr.truncated,
+		Duration:  time.Since(started),
+	}
+
+	if ctx.Err() != nil {
+		return result, ctx.Err()
+	}
+
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) {
+		result.ExitCode = exitErr.ExitCode()
+		return result, &ExitError{Code: result.ExitCode, Stderr: tail(result.Stderr, 1024)}
+	}
+	if err != nil {
+		return result, fmt.Errorf("failed to run %s: %w", command.Name, err)
+	}
+	return result, nil
+}
+
+// boundedBuffer keeps at most max bytes and discards the rest.
+type boundedBuffer struct {
+	buf       bytes.Buffer
+	max       int64
+	truncated bool
+}
+
+func newBoundedBuffer(max int64) *boundedBuffer {
+	return &boundedBuffer{max: max}
+}
+
+func (b *boundedBuffer) Write(p []byte) (int, error) {
+	remaining := b.max - int64(b.buf.Len())
+	if remaining <= 0 {
+		b.truncated = true
+		return len(p), nil // keep draining so the child doesn't block
+	}
+	if int64(len(p)) > remaining {
+		b.truncated = true
+		b.buf.Write(p[:remaining])
+		return len(p), nil
+	}
+	return b.buf.Write(p)
+}
+
+func (b *boundedBuffer) Bytes() []byte {
+	return b.buf.Bytes()
+}
+
+func tail(b []byte, n int) string {
+	if len(b) <= n {
+		return string(bytes.TrimSpace(b))
+	}
+	return string(bytes.TrimSpace(b[len(b)-n:]))
+}
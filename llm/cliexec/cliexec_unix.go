@@ -0,0 +1,23 @@
+//go:build unix
+
+package cliexec
+
+import (
+	"os/exec"
+	"syscall"
+)
+
+// setProcessGroup puts the child into its own process group so that the
+// whole tree can be signalled at once.
+func setProcessGroup(cmd *exec.Cmd) {
+	cmd.SysProcAttr = &syscall.SysProcAttr{Setpgid: true}
+}
+
+// terminateProcessGroup sends SIGTERM to the child's process group.
+// exec.Cmd escalates to SIGKILL after WaitDelay.
+func terminateProcessGroup(cmd *exec.Cmd) error {
+	if cmd.Process == nil {
+		return nil
+	}
+	return syscall.Kill(-cmd.Process.Pid, syscall.SIGTERM)
+}
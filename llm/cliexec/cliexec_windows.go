@@ -0,0 +1,16 @@
+//go:build windows
+
+package cliexec
+
+import "os/exec"
+
+// Windows has no process groups in the unix sense; rely on exec.Cmd's
+// default kill behavior.
+func setProcessGroup(cmd *exec.Cmd) {}
+
+func terminateProcessGroup(cmd *exec.Cmd) error {
+	if cmd.Process == nil {
+		return nil
+	}
+	return cmd.Process.Kill()
+}
@@ -0,0 +1,105 @@
+// Package eval is a golden-prompt evaluation harness: it runs a suite
+// of prompt/expected-output cases against one or more model configs and
+// reports per-model accuracy and cost, so prompt changes can be
+// regression-tested like code.
+package eval
+
+import (
+	"fmt"
+
+	"github.com/flanksource/commons-db/context"
+	"github.com/flanksource/commons-db/llm"
+)
+
+// Case is a single golden prompt.
+type Case struct {
+	Name   string `json:"name"`
+	System string `json:"system,omitempty"`
+	Prompt string `json:"prompt"`
+
+	// Expected is the reference output, interpreted by the scorer:
+	// the exact expected text for ExactMatch, the reference answer for
+	// Judge, and unused by JSONSchema.
+	Expected string `json:"expected,omitempty"`
+}
+
+// Scorer grades a model's output for a case on a 0..1 scale.
+type Scorer interface {
+	Name() string
+	Score(ctx context.Context, c Case, output string) (float64, error)
+}
+
+// CaseResult is the outcome of one case against one model.
+type CaseResult struct {
+	Case   string    `json:"case"`
+	Output string    `json:"output"`
+	Score  float64   `json:"score"`
+	Error  string    `json:"error,omitempty"`
+	Usage  llm.Usage `json:"usage"`
+}
+
+// ModelReport aggregates a model's run over the suite.
+type ModelReport struct {
+	Backend string       `json:"backend"`
+	Model   string       `json:"model"`
+	Scorer  string       `json:"scorer"`
+	Cases   []CaseResult `json:"cases"`
+
+	// Accuracy is the mean case score; PassRate the fraction of cases
+	// scoring a full 1.0.
+	Accuracy float64 `json:"accuracy"`
+	PassRate float64 `json:"pass_rate"`
+	Cost     float64 `json:"cost"`
+}
+
+// Run executes every case against every model config and scores the
+// outputs. Execution failures score zero and are recorded on the case
+// result rather than aborting the suite, so a flaky provider doesn't
+// lose the rest of the report; scorer failures abort, since they make
+// the whole report meaningless.
+func Run(ctx context.Context, cases []Case, models []llm.Config, scorer Scorer) ([]ModelReport, error) {
+	if len(cases) == 0 {
+		return nil, fmt.Errorf("no cases to run")
+	}
+
+	var reports []ModelReport
+	for _, config := range models {
+		report := ModelReport{Backend: config.Backend, Model: config.Model, Scorer: scorer.Name()}
+
+		var passed int
+		for _, c := range cases {
+			result := CaseResult{Case: c.Name}
+
+			var opts []llm.Option
+			if c.System != "" {
+				opts = append(opts, llm.WithSystem(c.System))
+			}
+			resp, err := llm.ExecutePrompt(ctx, config, c.Prompt, opts...)
+			if err != nil {
+				result.Error = err.Error()
+				report.Cases = append(report.Cases, result)
+				continue
+			}
+			result.Output = resp.Content
+			result.Usage = resp.Usage
+			report.Cost += resp.Usage.Cost
+
+			score, err := scorer.Score(ctx, c, resp.Content)
+			if err != nil {
+				return nil, fmt.Errorf("scorer %s failed on case %s: %w", scorer.Name(), c.Name, err)
+			}
+			result.Score = score
+			if score >= 1 {
+				passed++
+			}
+			report.Cases = append(report.Cases, result)
+
+			report.Accuracy += score
+		}
+
+		report.Accuracy /= float64(len(cases))
+		report.PassRate = float64(passed) / float64(len(cases))
+		reports = append(reports, report)
+	}
+	return reports, nil
+}
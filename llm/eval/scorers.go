@@ -0,0 +1,100 @@
+package eval
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/santhosh-tekuri/jsonschema/v5"
+
+	"github.com/flanksource/commons-db/context"
+	"github.com/flanksource/commons-db/llm"
+)
+
+// ExactMatch scores 1 when the output equals Expected after trimming
+// whitespace, 0 otherwise.
+func ExactMatch() Scorer {
+	return exactMatch{}
+}
+
+type exactMatch struct{}
+
+func (exactMatch) Name() string { return "exact-match" }
+
+func (exactMatch) Score(_ context.Context, c Case, output string) (float64, error) {
+	if strings.TrimSpace(output) == strings.TrimSpace(c.Expected) {
+		return 1, nil
+	}
+	return 0, nil
+}
+
+// JSONSchema scores 1 when the output parses as JSON (fenced code
+// blocks are unwrapped) and validates against the given JSON schema, 0
+// otherwise. It panics on an invalid schema, which is a suite bug.
+func JSONSchema(schema string) Scorer {
+	compiled, err := jsonschema.CompileString("expected.schema.json", schema)
+	if err != nil {
+		panic(fmt.Sprintf("invalid eval schema: %v", err))
+	}
+	return jsonSchema{schema: compiled}
+}
+
+type jsonSchema struct {
+	schema *jsonschema.Schema
+}
+
+func (jsonSchema) Name() string { return "json-schema" }
+
+func (s jsonSchema) Score(_ context.Context, _ Case, output string) (float64, error) {
+	raw := strings.TrimSpace(output)
+	if blocks := llm.ExtractCodeBlocks(output, "json"); len(blocks) > 0 {
+		raw = blocks[0]
+	}
+
+	var value any
+	if err := json.Unmarshal([]byte(raw), &value); err != nil {
+		return 0, nil
+	}
+	if err := s.schema.Validate(value); err != nil {
+		return 0, nil
+	}
+	return 1, nil
+}
+
+// judgeSystem is the grading instruction for the LLM judge.
+const judgeSystem = `You grade candidate answers against a reference answer.
+Score how well the candidate matches the reference in meaning and completeness,
+from 0 (wrong or missing) to 10 (fully equivalent).
+Respond with only the number.`
+
+// Judge scores with another model: the judge config is given the case's
+// reference answer and the candidate output and asked for a 0-10 grade,
+// normalized to 0..1. Use a strong, cheap model and keep the judge
+// config out of the models under test.
+func Judge(config llm.Config) Scorer {
+	return judge{config: config}
+}
+
+type judge struct {
+	config llm.Config
+}
+
+func (judge) Name() string { return "llm-judge" }
+
+func (j judge) Score(ctx context.Context, c Case, output string) (float64, error) {
+	prompt := fmt.Sprintf("Question:\n%s\n\nReference answer:\n%s\n\nCandidate answer:\n%s", c.Prompt, c.Expected, output)
+	resp, err := llm.ExecutePrompt(ctx, j.config, prompt, llm.WithSystem(judgeSystem), llm.WithTemperature(0))
+	if err != nil {
+		return 0, fmt.Errorf("judge call failed: %w", err)
+	}
+
+	grade, err := strconv.ParseFloat(strings.TrimSpace(resp.Content), 64)
+	if err != nil {
+		return 0, fmt.Errorf("judge returned a non-numeric grade %q", resp.Content)
+	}
+	if grade < 0 || grade > 10 {
+		return 0, fmt.Errorf("judge grade %v out of range", grade)
+	}
+	return grade / 10, nil
+}
@@ -0,0 +1,97 @@
+package llm
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/flanksource/commons-db/context"
+)
+
+// DefaultMaxFileSize bounds a single attachment (the smallest provider
+// document limit); overridable via the llm.files.max_size property.
+const DefaultMaxFileSize = 32 << 20
+
+// File is a named attachment sent alongside the prompt. Providers use
+// their native mechanism where one exists (file inputs, document
+// blocks) and inline text content into the prompt otherwise.
+type File struct {
+	Name string
+	MIME string
+	Data []byte
+}
+
+// EstimateTokens approximates the prompt tokens the file adds: ~4 bytes
+// per token for text content, and the base64 overhead for binary
+// formats the provider re-tokenizes. Multiply by the model's input rate
+// to estimate cost before sending.
+func (f File) EstimateTokens() int {
+	if isTextMIME(f.MIME) {
+		return len(f.Data) / 4
+	}
+	return len(f.Data) / 3
+}
+
+// EstimateFileTokens sums the token estimate of all attachments.
+func EstimateFileTokens(files []File) int {
+	var total int
+	for _, f := range files {
+		total += f.EstimateTokens()
+	}
+	return total
+}
+
+// WithFile attaches a file (a report, CSV, PDF, ...) to the request for
+// the model to analyze. The reader is consumed and validated against the
+// size limit here, once — options are applied to more than one request
+// (e.g. every sample of ExecutePromptBestOf), so the returned closure
+// only appends the captured content. Oversized or unreadable files fail
+// the call when it executes.
+func WithFile(name string, reader io.Reader, mime string) Option {
+	limit := int64(context.GlobalProperties().Int("llm.files.max_size", DefaultMaxFileSize))
+
+	file := File{Name: name, MIME: mime}
+	var fileErr error
+	data, err := io.ReadAll(io.LimitReader(reader, limit+1))
+	switch {
+	case err != nil:
+		fileErr = fmt.Errorf("failed to read file %s: %w", name, err)
+	case int64(len(data)) > limit:
+		fileErr = fmt.Errorf("file %s exceeds the %d byte attachment limit", name, limit)
+	default:
+		file.Data = data
+	}
+
+	return func(r *Request) {
+		if fileErr != nil {
+			r.filesErr = fileErr
+			return
+		}
+		r.Files = append(r.Files, file)
+	}
+}
+
+func isTextMIME(mime string) bool {
+	if strings.HasPrefix(mime, "text/") {
+		return true
+	}
+	switch mime {
+	case "application/json", "application/xml", "application/yaml", "application/x-yaml", "text/csv", "":
+		return true
+	}
+	return false
+}
+
+// inlineFiles renders text attachments into a prompt suffix for
+// providers without native file inputs; binary attachments are an error
+// there rather than silently dropped.
+func inlineFiles(files []File) (string, error) {
+	var b strings.Builder
+	for _, f := range files {
+		if !isTextMIME(f.MIME) {
+			return "", fmt.Errorf("file %s (%s): this backend only supports text attachments", f.Name, f.MIME)
+		}
+		fmt.Fprintf(&b, "\n\n--- file: %s ---\n%s", f.Name, f.Data)
+	}
+	return b.String(), nil
+}
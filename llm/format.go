@@ -0,0 +1,114 @@
+package llm
+
+import (
+	"regexp"
+	"strings"
+)
+
+// Helpers for turning model output into structured artifacts. Models
+// routinely wrap answers in markdown even when asked not to; these are
+// the post-processing steps consumers otherwise reimplement after every
+// ExecutePrompt call.
+
+// ExtractCodeBlocks returns the contents of fenced code blocks, in
+// order. When language is non-empty only blocks tagged with that
+// language are returned; untagged blocks match the empty language.
+func ExtractCodeBlocks(markdown, language string) []string {
+	var blocks []string
+	lines := strings.Split(markdown, "\n")
+
+	var inBlock bool
+	var blockLang string
+	var current []string
+	for _, line := range lines {
+		trimmed := strings.TrimSpace(line)
+		if strings.HasPrefix(trimmed, "```") {
+			if !inBlock {
+				inBlock = true
+				blockLang = strings.ToLower(strings.TrimSpace(strings.TrimPrefix(trimmed, "```")))
+				current = current[:0]
+				continue
+			}
+
+			inBlock = false
+			if language == "" || blockLang == strings.ToLower(language) {
+				blocks = append(blocks, strings.Join(current, "\n"))
+			}
+			continue
+		}
+		if inBlock {
+			current = append(current, line)
+		}
+	}
+	return blocks
+}
+
+// Table is a parsed markdown table.
+type Table struct {
+	Headers []string
+	Rows    [][]string
+}
+
+var tableSeparatorRegexp = regexp.MustCompile(`^\s*\|?[\s:|-]+\|?\s*$`)
+
+// ExtractTables parses all markdown tables in the text. Cells are
+// trimmed; rows with a different column count than the header are
+// padded or truncated to match.
+func ExtractTables(markdown string) []Table {
+	var tables []Table
+	lines := strings.Split(markdown, "\n")
+
+	for i := 0; i < len(lines)-1; i++ {
+		if !isTableRow(lines[i]) || !tableSeparatorRegexp.MatchString(lines[i+1]) || !strings.Contains(lines[i+1], "-") {
+			continue
+		}
+
+		table := Table{Headers: splitTableRow(lines[i])}
+		for j := i + 2; j < len(lines) && isTableRow(lines[j]); j++ {
+			row := splitTableRow(lines[j])
+			for len(row) < len(table.Headers) {
+				row = append(row, "")
+			}
+			table.Rows = append(table.Rows, row[:len(table.Headers)])
+			i = j
+		}
+		tables = append(tables, table)
+	}
+	return tables
+}
+
+func isTableRow(line string) bool {
+	trimmed := strings.TrimSpace(line)
+	return strings.HasPrefix(trimmed, "|") && strings.Count(trimmed, "|") >= 2
+}
+
+func splitTableRow(line string) []string {
+	trimmed := strings.Trim(strings.TrimSpace(line), "|")
+	cells := strings.Split(trimmed, "|")
+	for i := range cells {
+		cells[i] = strings.TrimSpace(cells[i])
+	}
+	return cells
+}
+
+var (
+	boldItalicRegexp = regexp.MustCompile(`(\*{1,3}|_{1,3})([^*_]+)(\*{1,3}|_{1,3})`)
+	inlineCodeRegexp = regexp.MustCompile("`([^`]*)`")
+	headingRegexp    = regexp.MustCompile(`(?m)^#{1,6}\s+`)
+	linkRegexp       = regexp.MustCompile(`\[([^\]]*)\]\([^)]*\)`)
+	listRegexp       = regexp.MustCompile(`(?m)^\s*[-*+]\s+`)
+)
+
+// StripMarkdown removes common markdown formatting (headings, emphasis,
+// inline code, links, list bullets) leaving plain text. Fenced code
+// blocks keep their content but lose the fences.
+func StripMarkdown(markdown string) string {
+	out := markdown
+	out = strings.ReplaceAll(out, "```", "")
+	out = headingRegexp.ReplaceAllString(out, "")
+	out = linkRegexp.ReplaceAllString(out, "$1")
+	out = boldItalicRegexp.ReplaceAllString(out, "$2")
+	out = inlineCodeRegexp.ReplaceAllString(out, "$1")
+	out = listRegexp.ReplaceAllString(out, "")
+	return strings.TrimSpace(out)
+}
@@ -0,0 +1,39 @@
+package llm
+
+import "testing"
+
+func TestExtractCodeBlocks(t *testing.T) {
+	markdown := "Here is the fix:\n```go\nfunc main() {}\n```\nand the config:\n```yaml\nkey: value\n```\n"
+
+	if blocks := ExtractCodeBlocks(markdown, "go"); len(blocks) != 1 || blocks[0] != "func main() {}" {
+		t.Errorf("go blocks: got %v", blocks)
+	}
+	if blocks := ExtractCodeBlocks(markdown, ""); len(blocks) != 2 {
+		t.Errorf("all blocks: got %v", blocks)
+	}
+}
+
+func TestExtractTables(t *testing.T) {
+	markdown := `Summary:
+
+| Name | Status |
+|------|--------|
+| api  | healthy |
+| db   | unhealthy |
+`
+	tables := ExtractTables(markdown)
+	if len(tables) != 1 {
+		t.Fatalf("got %d tables", len(tables))
+	}
+	if tables[0].Headers[0] != "Name" || len(tables[0].Rows) != 2 || tables[0].Rows[1][1] != "unhealthy" {
+		t.Errorf("got %+v", tables[0])
+	}
+}
+
+func TestStripMarkdown(t *testing.T) {
+	got := StripMarkdown("# Title\n\nThis is **bold** and `code` with a [link](http://example.com).")
+	want := "Title\n\nThis is bold and code with a link."
+	if got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,150 @@
+package llm
+
+import (
+	gocontext "context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/flanksource/commons-db/context"
+	"github.com/flanksource/commons-db/types"
+)
+
+// keyCooldown is how long a key that just failed with 401/429 is
+// skipped before being tried again.
+const keyCooldown = time.Minute
+
+// KeyResolver resolves an EnvVar-based API key to its value; it is
+// called on every key pick so rotated secrets take effect without a
+// restart. The default resolver handles only static values.
+type KeyResolver func(ctx gocontext.Context, e types.EnvVar) (string, error)
+
+var (
+	keyResolverMu sync.RWMutex
+	keyResolver   KeyResolver = func(_ gocontext.Context, e types.EnvVar) (string, error) {
+		if e.ValueFrom.IsEmpty() {
+			return e.ValueStatic, nil
+		}
+		return "", fmt.Errorf("no key resolver registered to resolve %s", e.String())
+	}
+)
+
+// SetKeyResolver registers the resolver used for EnvVar-based API keys.
+func SetKeyResolver(resolver KeyResolver) {
+	keyResolverMu.Lock()
+	defer keyResolverMu.Unlock()
+	keyResolver = resolver
+}
+
+// key quarantine is process-global, keyed by resolved key value, so all
+// callers sharing a rate-limited key back off together.
+var (
+	quarantineMu sync.Mutex
+	quarantined  = map[string]time.Time{}
+)
+
+func quarantine(key string) {
+	quarantineMu.Lock()
+	defer quarantineMu.Unlock()
+	quarantined[key] = time.Now().Add(keyCooldown)
+}
+
+func isQuarantined(key string) bool {
+	quarantineMu.Lock()
+	defer quarantineMu.Unlock()
+	until, ok := quarantined[key]
+	if !ok {
+		return false
+	}
+	if time.Now().After(until) {
+		delete(quarantined, key)
+		return false
+	}
+	return true
+}
+
+// round-robin position per backend
+var (
+	keyCursorMu sync.Mutex
+	keyCursors  = map[string]int{}
+)
+
+// nextKey resolves the config's key pool and picks the next usable key
+// round-robin, skipping quarantined ones. With no pool configured it
+// returns the static APIKey.
+func (c Config) nextKey(ctx gocontext.Context) (string, error) {
+	if len(c.APIKeys) == 0 {
+		return c.APIKey, nil
+	}
+
+	keyResolverMu.RLock()
+	resolver := keyResolver
+	keyResolverMu.RUnlock()
+
+	keyCursorMu.Lock()
+	start := keyCursors[c.Backend]
+	keyCursors[c.Backend] = (start + 1) % len(c.APIKeys)
+	keyCursorMu.Unlock()
+
+	var firstErr error
+	for i := 0; i < len(c.APIKeys); i++ {
+		envVar := c.APIKeys[(start+i)%len(c.APIKeys)]
+		key, err := resolver(ctx, envVar)
+		if err != nil {
+			if firstErr == nil {
+				firstErr = err
+			}
+			continue
+		}
+		if key == "" || isQuarantined(key) {
+			continue
+		}
+		return key, nil
+	}
+
+	if firstErr != nil {
+		return "", fmt.Errorf("no usable api key for backend %s: %w", c.Backend, firstErr)
+	}
+	return "", fmt.Errorf("all api keys for backend %s are cooling down", c.Backend)
+}
+
+// isKeyError reports whether the error indicates the key itself is the
+// problem (rejected or rate limited), warranting a failover to the next
+// key in the pool.
+func isKeyError(err error) bool {
+	if err == nil {
+		return false
+	}
+	msg := strings.ToLower(err.Error())
+	return strings.Contains(msg, "401") || strings.Contains(msg, "unauthorized") ||
+		strings.Contains(msg, "invalid api key") ||
+		strings.Contains(msg, "429") || strings.Contains(msg, "rate limit")
+}
+
+// executeWithKeyPool runs the request, rotating through the key pool on
+// 401/429 until a key succeeds or the pool is exhausted.
+func executeWithKeyPool(ctx context.Context, provider Provider, req Request) (*Response, error) {
+	attempts := max(len(req.Config.APIKeys), 1)
+
+	var err error
+	for attempt := 0; attempt < attempts; attempt++ {
+		var key string
+		if key, err = req.Config.nextKey(ctx); err != nil {
+			return nil, err
+		}
+		req.Config.APIKey = key
+
+		var resp *Response
+		if resp, err = provider.Execute(ctx, req); err == nil {
+			return resp, nil
+		}
+		if !isKeyError(err) || len(req.Config.APIKeys) == 0 {
+			return nil, err
+		}
+
+		quarantine(key)
+		ctx.Warnf("llm key for %s rejected (%v), failing over to next key", req.Config.Backend, err)
+	}
+	return nil, err
+}
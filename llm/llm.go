@@ -0,0 +1,212 @@
+// Package llm provides a thin, provider-agnostic interface for calling
+// large language models, with per-call options, usage accounting, and
+// pluggable backends (API-based or CLI-based).
+package llm
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/flanksource/commons-db/context"
+	"github.com/flanksource/commons-db/types"
+)
+
+// Config selects a backend and model and carries its credentials.
+type Config struct {
+	Backend string `json:"backend"` // e.g. "openai", "anthropic", "claude-code"
+	Model   string `json:"model,omitempty"`
+	APIKey  string `json:"apiKey,omitempty"`
+	BaseURL string `json:"baseUrl,omitempty"`
+
+	// APIKeys is an optional pool of keys used round-robin, with
+	// failover to the next key on 401/429. Keys are EnvVars resolved on
+	// every pick (see SetKeyResolver), so secret rotation takes effect
+	// without a restart. When set, APIKey is ignored.
+	APIKeys []types.EnvVar `json:"apiKeys,omitempty"`
+}
+
+// Request is a fully-resolved prompt request passed to a provider.
+type Request struct {
+	Config Config
+
+	System string
+	Prompt string
+
+	Temperature *float64
+	MaxTokens   int
+
+	// N is the number of independent samples requested (see WithN);
+	// providers execute one completion per call, sampling is driven by
+	// ExecutePromptBestOf.
+	N int
+
+	// DryRun short-circuits the call with a deterministic placeholder
+	// response instead of reaching the provider (see WithDryRun and
+	// OfflineEnvVar).
+	DryRun bool
+
+	// ProviderOptions are backend-specific parameters forwarded verbatim
+	// (e.g. OpenAI logprobs/user, Anthropic metadata, Gemini
+	// candidateCount); see WithProviderOption. Providers ignore keys they
+	// don't recognize.
+	ProviderOptions map[string]string
+
+	// Files are attachments for the model to analyze (see WithFile);
+	// providers upload or inline them per their capability.
+	Files []File
+
+	// filesErr records a WithFile failure (unreadable or oversized) to
+	// surface when the request executes.
+	filesErr error
+}
+
+// Usage is the token/cost accounting for a single response.
+type Usage struct {
+	PromptTokens     int           `json:"prompt_tokens"`
+	CompletionTokens int           `json:"completion_tokens"`
+	Cost             float64       `json:"cost"`
+	Duration         time.Duration `json:"duration"`
+}
+
+// Response is a completed model response.
+type Response struct {
+	Content string `json:"content"`
+	Model   string `json:"model"`
+	Usage   Usage  `json:"usage"`
+
+	// DryRun marks a placeholder response produced without calling the
+	// provider (see WithDryRun and OfflineEnvVar).
+	DryRun bool `json:"dry_run,omitempty"`
+
+	// Compression is set when the prompt was compressed before sending
+	// (see the middleware package).
+	Compression *CompressionStats `json:"compression,omitempty"`
+}
+
+// CompressionStats records how much a prompt shrank during compression.
+type CompressionStats struct {
+	OriginalTokens   int `json:"original_tokens"`
+	CompressedTokens int `json:"compressed_tokens"`
+}
+
+// Option mutates a request before it is sent.
+type Option func(*Request)
+
+func WithSystem(system string) Option {
+	return func(r *Request) { r.System = system }
+}
+
+func WithTemperature(t float64) Option {
+	return func(r *Request) { r.Temperature = &t }
+}
+
+func WithMaxTokens(n int) Option {
+	return func(r *Request) { r.MaxTokens = n }
+}
+
+// WithProviderOption forwards a backend-specific parameter that has no
+// first-class option yet. Options participate in the cache key, so
+// responses produced with different provider options don't collide.
+func WithProviderOption(key, value string) Option {
+	return func(r *Request) {
+		if r.ProviderOptions == nil {
+			r.ProviderOptions = map[string]string{}
+		}
+		r.ProviderOptions[key] = value
+	}
+}
+
+// CacheKey derives a stable key identifying this request for the
+// response cache: backend, model, prompts, sampling parameters and
+// provider options (in sorted order) all participate, so any variation
+// produces a distinct key. Credentials do not.
+func (r Request) CacheKey() string {
+	h := sha256.New()
+	fmt.Fprintf(h, "%s|%s|%s|%s|%d|%d", r.Config.Backend, r.Config.Model, r.System, r.Prompt, r.MaxTokens, r.N)
+	if r.DryRun {
+		// placeholder responses must never collide with real ones
+		fmt.Fprint(h, "|dry-run")
+	}
+	if r.Temperature != nil {
+		fmt.Fprintf(h, "|t=%v", *r.Temperature)
+	}
+
+	for _, f := range r.Files {
+		fmt.Fprintf(h, "|file=%s:%s:%x", f.Name, f.MIME, sha256.Sum256(f.Data))
+	}
+
+	keys := make([]string, 0, len(r.ProviderOptions))
+	for k := range r.ProviderOptions {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		fmt.Fprintf(h, "|%s=%s", k, r.ProviderOptions[k])
+	}
+
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// Provider executes requests against a single backend.
+type Provider interface {
+	Name() string
+	Execute(ctx context.Context, req Request) (*Response, error)
+}
+
+var (
+	providersMu sync.RWMutex
+	providers   = map[string]Provider{}
+)
+
+// RegisterProvider makes a provider available by backend name. It is
+// typically called from an init() in the provider's file.
+func RegisterProvider(p Provider) {
+	providersMu.Lock()
+	defer providersMu.Unlock()
+	providers[p.Name()] = p
+}
+
+func getProvider(backend string) (Provider, error) {
+	providersMu.RLock()
+	defer providersMu.RUnlock()
+	if p, ok := providers[backend]; ok {
+		return p, nil
+	}
+	return nil, fmt.Errorf("unknown llm backend: %s", backend)
+}
+
+// ExecutePrompt sends a prompt to the backend selected by config.
+func ExecutePrompt(ctx context.Context, config Config, prompt string, opts ...Option) (*Response, error) {
+	req := Request{Config: config, Prompt: prompt}
+	for _, opt := range opts {
+		opt(&req)
+	}
+	if req.filesErr != nil {
+		return nil, req.filesErr
+	}
+
+	// checked before provider resolution so airgapped environments don't
+	// need the backend compiled in or configured
+	if isOffline(req) {
+		return dryRunResponse(req), nil
+	}
+
+	provider, err := getProvider(config.Backend)
+	if err != nil {
+		return nil, err
+	}
+
+	started := time.Now()
+	resp, err := executeWithKeyPool(ctx, provider, req)
+	if err != nil {
+		return nil, err
+	}
+	if resp.Usage.Duration == 0 {
+		resp.Usage.Duration = time.Since(started)
+	}
+	return resp, nil
+}
@@ -0,0 +1,116 @@
+package middleware
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/flanksource/commons-db/context"
+	"github.com/flanksource/commons-db/llm"
+)
+
+// charsPerToken is the usual rough estimate for English/log text; the
+// compressor only needs to be in the right ballpark.
+const charsPerToken = 4
+
+const summarizeSystem = "You compress operational context. Summarize the following text, " +
+	"preserving identifiers, error messages, timestamps and numbers exactly. " +
+	"Output only the summary."
+
+// WithContextCompression returns a middleware that shrinks prompts
+// whose estimated token count exceeds targetTokens before sending.
+//
+// Oversized prompts are compressed map-reduce style: the prompt is
+// split into chunks, each chunk is summarized with the same backend,
+// and the summaries are joined (recursively, until under target). If
+// summarization itself fails, the prompt falls back to head+tail
+// truncation so the call still goes out. Original and compressed token
+// estimates are recorded on the response's Compression field.
+func WithContextCompression(targetTokens int) Middleware {
+	return func(next Executor) Executor {
+		return func(ctx context.Context, config llm.Config, prompt string, opts ...llm.Option) (*llm.Response, error) {
+			original := EstimateTokens(prompt)
+			if targetTokens <= 0 || original <= targetTokens {
+				return next(ctx, config, prompt, opts...)
+			}
+
+			compressed, err := compress(ctx, next, config, prompt, targetTokens)
+			if err != nil {
+				ctx.Warnf("prompt compression failed, truncating instead: %v", err)
+				compressed = truncate(prompt, targetTokens)
+			}
+
+			resp, err := next(ctx, config, compressed, opts...)
+			if err != nil {
+				return nil, err
+			}
+			resp.Compression = &llm.CompressionStats{
+				OriginalTokens:   original,
+				CompressedTokens: EstimateTokens(compressed),
+			}
+			return resp, nil
+		}
+	}
+}
+
+// EstimateTokens is a cheap length-based token estimate.
+func EstimateTokens(text string) int {
+	return (len(text) + charsPerToken - 1) / charsPerToken
+}
+
+// compress map-reduces the prompt down below targetTokens, recursing
+// when a single pass of summaries is still too large.
+func compress(ctx context.Context, exec Executor, config llm.Config, prompt string, targetTokens int) (string, error) {
+	chunkChars := targetTokens * charsPerToken / 2 // summaries should halve each pass
+	chunks := splitChunks(prompt, chunkChars)
+
+	summaries := make([]string, 0, len(chunks))
+	for i, chunk := range chunks {
+		resp, err := exec(ctx, config, chunk,
+			llm.WithSystem(summarizeSystem),
+			llm.WithMaxTokens(targetTokens/len(chunks)+64))
+		if err != nil {
+			return "", fmt.Errorf("failed to summarize chunk %d/%d: %w", i+1, len(chunks), err)
+		}
+		summaries = append(summaries, strings.TrimSpace(resp.Content))
+	}
+
+	combined := strings.Join(summaries, "\n\n")
+	if EstimateTokens(combined) > targetTokens && len(chunks) > 1 {
+		return compress(ctx, exec, config, combined, targetTokens)
+	}
+	return combined, nil
+}
+
+// splitChunks splits on line boundaries where possible so log entries
+// stay intact.
+func splitChunks(text string, chunkChars int) []string {
+	if chunkChars <= 0 || len(text) <= chunkChars {
+		return []string{text}
+	}
+
+	var chunks []string
+	for len(text) > chunkChars {
+		cut := chunkChars
+		if idx := strings.LastIndexByte(text[:chunkChars], '\n'); idx > chunkChars/2 {
+			cut = idx + 1
+		}
+		chunks = append(chunks, text[:cut])
+		text = text[cut:]
+	}
+	if len(text) > 0 {
+		chunks = append(chunks, text)
+	}
+	return chunks
+}
+
+// truncate keeps the head and tail of the prompt, which is where
+// instructions and the most recent context usually live.
+func truncate(prompt string, targetTokens int) string {
+	budget := targetTokens * charsPerToken
+	if len(prompt) <= budget {
+		return prompt
+	}
+	head := budget * 2 / 3
+	tail := budget - head
+	return prompt[:head] + "\n\n[... truncated ...]\n\n" + prompt[len(prompt)-tail:]
+}
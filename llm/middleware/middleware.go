@@ -0,0 +1,23 @@
+// Package middleware provides composable wrappers around llm execution,
+// e.g. prompt compression for oversized inputs.
+package middleware
+
+import (
+	"github.com/flanksource/commons-db/context"
+	"github.com/flanksource/commons-db/llm"
+)
+
+// Executor is the llm.ExecutePrompt signature; middlewares wrap it.
+type Executor func(ctx context.Context, config llm.Config, prompt string, opts ...llm.Option) (*llm.Response, error)
+
+// Middleware decorates an Executor.
+type Middleware func(next Executor) Executor
+
+// Chain applies middlewares to an executor, outermost first, so
+// Chain(exec, a, b) runs a → b → exec.
+func Chain(executor Executor, middlewares ...Middleware) Executor {
+	for i := len(middlewares) - 1; i >= 0; i-- {
+		executor = middlewares[i](executor)
+	}
+	return executor
+}
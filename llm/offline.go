@@ -0,0 +1,36 @@
+package llm
+
+import (
+	"fmt"
+	"os"
+	"strings"
+)
+
+// OfflineEnvVar switches every llm call into dry-run mode when set to
+// "true", so pipelines that embed LLM calls can run in airgapped CI
+// environments without per-call-site changes.
+const OfflineEnvVar = "LLM_OFFLINE"
+
+// WithDryRun makes this call return a deterministic placeholder response
+// with zero cost instead of reaching the provider.
+func WithDryRun() Option {
+	return func(r *Request) { r.DryRun = true }
+}
+
+// isOffline reports whether the request should be served without
+// touching a provider.
+func isOffline(req Request) bool {
+	return req.DryRun || strings.EqualFold(os.Getenv(OfflineEnvVar), "true")
+}
+
+// dryRunResponse is the canned response for offline calls: deterministic
+// for a given request (the cache key pins backend, model, prompts and
+// sampling parameters), zero cost, and flagged so callers can tell it
+// apart from a real completion.
+func dryRunResponse(req Request) *Response {
+	return &Response{
+		Content: fmt.Sprintf("[dry-run] %s/%s response %s", req.Config.Backend, req.Config.Model, req.CacheKey()[:12]),
+		Model:   req.Config.Model,
+		DryRun:  true,
+	}
+}
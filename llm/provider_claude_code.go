@@ -0,0 +1,84 @@
+package llm
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/flanksource/commons-db/context"
+	"github.com/flanksource/commons-db/llm/cliexec"
+)
+
+func init() {
+	RegisterProvider(&claudeCodeProvider{})
+}
+
+// claudeCodeProvider shells out to the `claude` CLI in non-interactive
+// mode. All subprocess lifecycle concerns (cancellation, process-group
+// kill, output bounds) are handled by cliexec.
+type claudeCodeProvider struct{}
+
+func (p *claudeCodeProvider) Name() string { return "claude-code" }
+
+// claudeCodeOutput is the JSON envelope emitted by `claude -p
+// --output-format json`.
+type claudeCodeOutput struct {
+	Result  string  `json:"result"`
+	IsError bool    `json:"is_error"`
+	CostUSD float64 `json:"total_cost_usd"`
+	Usage   struct {
+		InputTokens  int `json:"input_tokens"`
+		OutputTokens int `json:"output_tokens"`
+	} `json:"usage"`
+}
+
+func (p *claudeCodeProvider) Execute(ctx context.Context, req Request) (*Response, error) {
+	args := []string{"-p", "--output-format", "json"}
+	if req.Config.Model != "" {
+		args = append(args, "--model", req.Config.Model)
+	}
+	if req.System != "" {
+		args = append(args, "--append-system-prompt", req.System)
+	}
+
+	prompt := req.Prompt
+	if len(req.Files) > 0 {
+		// no native file input over stdin; text attachments are inlined
+		suffix, err := inlineFiles(req.Files)
+		if err != nil {
+			return nil, fmt.Errorf("claude-code: %w", err)
+		}
+		prompt += suffix
+	}
+
+	result, err := cliexec.Run(ctx, cliexec.Command{
+		Name:  "claude",
+		Args:  args,
+		Stdin: strings.NewReader(prompt),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("claude-code: %w", err)
+	}
+	if result.Truncated {
+		return nil, fmt.Errorf("claude-code: output exceeded %d bytes", cliexec.DefaultMaxOutputBytes)
+	}
+
+	var output claudeCodeOutput
+	if err := json.Unmarshal(result.Stdout, &output); err != nil {
+		return nil, fmt.Errorf("claude-code: failed to parse output: %w", err)
+	}
+	if output.IsError {
+		return nil, fmt.Errorf("claude-code: %s", output.Result)
+	}
+
+	return &Response{
+		Content: output.Result,
+		Model:   req.Config.Model,
+		Usage: Usage{
+			PromptTokens:     output.Usage.InputTokens,
+			CompletionTokens: output.Usage.OutputTokens,
+			Cost:             output.CostUSD,
+			Duration:         result.Duration,
+		},
+	}, nil
+}
@@ -0,0 +1,122 @@
+package llm
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/flanksource/commons-db/context"
+)
+
+// WithN requests n independent completions for the same prompt, for
+// tasks where single-sample reliability is insufficient. Use
+// ExecutePromptBestOf to combine the samples.
+func WithN(n int) Option {
+	return func(r *Request) { r.N = n }
+}
+
+// Selector picks the best response out of several samples.
+type Selector func(responses []*Response) (*Response, error)
+
+// SelectLongest picks the longest response — a cheap proxy for the most
+// complete answer in extraction tasks.
+func SelectLongest(responses []*Response) (*Response, error) {
+	var best *Response
+	for _, r := range responses {
+		if best == nil || len(r.Content) > len(best.Content) {
+			best = r
+		}
+	}
+	return best, nil
+}
+
+// SelectMajority picks the most frequent response. Contents that parse
+// as JSON are compared structurally (key order and whitespace don't
+// split the vote).
+func SelectMajority(responses []*Response) (*Response, error) {
+	votes := map[string][]*Response{}
+	for _, r := range responses {
+		votes[normalizeContent(r.Content)] = append(votes[normalizeContent(r.Content)], r)
+	}
+
+	var best []*Response
+	for _, group := range votes {
+		if len(group) > len(best) {
+			best = group
+		}
+	}
+	if len(best) == 0 {
+		return nil, fmt.Errorf("no responses to select from")
+	}
+	return best[0], nil
+}
+
+// SelectByScore picks the response with the highest score.
+func SelectByScore(score func(*Response) float64) Selector {
+	return func(responses []*Response) (*Response, error) {
+		var best *Response
+		var bestScore float64
+		for _, r := range responses {
+			if s := score(r); best == nil || s > bestScore {
+				best, bestScore = r, s
+			}
+		}
+		return best, nil
+	}
+}
+
+// BestOf is the outcome of a multi-sample call: the selected response,
+// every sample, and usage aggregated across all of them (you pay for
+// every sample, not just the winner).
+type BestOf struct {
+	Selected *Response
+	Samples  []*Response
+	Usage    Usage
+}
+
+// ExecutePromptBestOf samples the prompt n times (from WithN, default 3)
+// and returns the selector's pick with aggregate cost accounting.
+func ExecutePromptBestOf(ctx context.Context, config Config, prompt string, selector Selector, opts ...Option) (*BestOf, error) {
+	req := Request{Config: config, Prompt: prompt}
+	for _, opt := range opts {
+		opt(&req)
+	}
+	if req.N <= 0 {
+		req.N = 3
+	}
+	if selector == nil {
+		selector = SelectMajority
+	}
+
+	out := BestOf{Samples: make([]*Response, 0, req.N)}
+	for i := 0; i < req.N; i++ {
+		response, err := ExecutePrompt(ctx, config, prompt, opts...)
+		if err != nil {
+			return nil, fmt.Errorf("sample %d/%d failed: %w", i+1, req.N, err)
+		}
+		out.Samples = append(out.Samples, response)
+		out.Usage.PromptTokens += response.Usage.PromptTokens
+		out.Usage.CompletionTokens += response.Usage.CompletionTokens
+		out.Usage.Cost += response.Usage.Cost
+		out.Usage.Duration += response.Usage.Duration
+	}
+
+	selected, err := selector(out.Samples)
+	if err != nil {
+		return nil, err
+	}
+	out.Selected = selected
+	return &out, nil
+}
+
+// normalizeContent canonicalizes JSON content for voting.
+func normalizeContent(content string) string {
+	var v any
+	if err := json.Unmarshal([]byte(content), &v); err != nil {
+		return content
+	}
+	normalized, err := json.Marshal(v)
+	if err != nil {
+		return content
+	}
+	return string(normalized)
+}
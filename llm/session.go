@@ -0,0 +1,112 @@
+package llm
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/flanksource/commons-db/context"
+	"github.com/flanksource/commons-db/llm/cache"
+)
+
+// DefaultSessionTTL is how long an idle conversation survives; every
+// Save resets the clock.
+const DefaultSessionTTL = 7 * 24 * time.Hour
+
+// Turn is one exchange in a conversation.
+type Turn struct {
+	Role    string    `json:"role"` // "user" or "assistant"
+	Content string    `json:"content"`
+	At      time.Time `json:"at"`
+}
+
+// Session is a multi-turn conversation that persists across process
+// invocations: turns are stored in the local cache DB keyed by the
+// session id, so a CLI can resume where it left off.
+type Session struct {
+	ID     string `json:"id"`
+	Config Config `json:"config"`
+	System string `json:"system,omitempty"`
+	Turns  []Turn `json:"turns,omitempty"`
+
+	cache *cache.Cache
+	ttl   time.Duration
+}
+
+// NewSession starts a fresh conversation stored in c under id.
+func NewSession(c *cache.Cache, id string, config Config) *Session {
+	return &Session{ID: id, Config: config, cache: c, ttl: DefaultSessionTTL}
+}
+
+// LoadSession restores the conversation stored under id; found is false
+// when there is none (expired or never saved), in which case callers
+// typically fall back to NewSession.
+func LoadSession(c *cache.Cache, id string) (session *Session, found bool, err error) {
+	data, found, err := c.GetSession(id)
+	if err != nil || !found {
+		return nil, false, err
+	}
+
+	session = &Session{cache: c, ttl: DefaultSessionTTL}
+	if err := json.Unmarshal(data, session); err != nil {
+		return nil, false, fmt.Errorf("failed to decode session %s: %w", id, err)
+	}
+	return session, true, nil
+}
+
+// WithTTL overrides how long the session survives between saves.
+func (s *Session) WithTTL(ttl time.Duration) *Session {
+	s.ttl = ttl
+	return s
+}
+
+// Save persists the conversation, resetting its TTL.
+func (s *Session) Save() error {
+	data, err := json.Marshal(s)
+	if err != nil {
+		return err
+	}
+	return s.cache.SetSession(s.ID, data, s.ttl)
+}
+
+// Chat sends the next user message with the full conversation history
+// as context, records both sides as turns, and saves the session.
+func (s *Session) Chat(ctx context.Context, message string, opts ...Option) (*Response, error) {
+	if s.System != "" {
+		// per-call options may still override the session's system prompt
+		opts = append([]Option{WithSystem(s.System)}, opts...)
+	}
+
+	resp, err := ExecutePrompt(ctx, s.Config, s.transcript(message), opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	s.Turns = append(s.Turns,
+		Turn{Role: "user", Content: message, At: now},
+		Turn{Role: "assistant", Content: resp.Content, At: now},
+	)
+	if err := s.Save(); err != nil {
+		return resp, fmt.Errorf("response received but session not saved: %w", err)
+	}
+	return resp, nil
+}
+
+// transcript renders the history plus the next message as a single
+// prompt, since providers take one prompt string.
+func (s *Session) transcript(next string) string {
+	if len(s.Turns) == 0 {
+		return next
+	}
+
+	var b strings.Builder
+	b.WriteString("The conversation so far:\n\n")
+	for _, turn := range s.Turns {
+		fmt.Fprintf(&b, "%s: %s\n\n", strings.ToUpper(turn.Role), turn.Content)
+	}
+	b.WriteString("USER: ")
+	b.WriteString(next)
+	return b.String()
+}
@@ -0,0 +1,196 @@
+package llm
+
+import (
+	"encoding/json"
+	"strings"
+)
+
+// Incremental parsing for streamed structured outputs: as JSON tokens
+// arrive, partially-populated values are emitted so UIs can render long
+// extraction results progressively instead of waiting for the final
+// brace.
+
+// PartialDecoder accumulates streamed chunks of a JSON document and
+// invokes OnUpdate with the latest partially-populated value whenever
+// the buffered prefix can be completed into valid JSON.
+type PartialDecoder[T any] struct {
+	// OnUpdate receives each successfully parsed partial value.
+	OnUpdate func(partial T)
+
+	buf  strings.Builder
+	last string
+}
+
+// Write appends a streamed chunk. It never returns an error for
+// malformed prefixes — those simply don't produce an update.
+func (d *PartialDecoder[T]) Write(chunk []byte) (int, error) {
+	d.buf.Write(chunk)
+
+	if value, ok := ParsePartial[T](d.buf.String()); ok {
+		// suppress duplicate updates for chunks that didn't complete
+		// any new field
+		if serialized, err := json.Marshal(value); err == nil && string(serialized) != d.last {
+			d.last = string(serialized)
+			if d.OnUpdate != nil {
+				d.OnUpdate(value)
+			}
+		}
+	}
+	return len(chunk), nil
+}
+
+// Final parses the complete document strictly.
+func (d *PartialDecoder[T]) Final() (T, error) {
+	var value T
+	err := json.Unmarshal([]byte(strings.TrimSpace(d.buf.String())), &value)
+	return value, err
+}
+
+// ParsePartial completes a JSON prefix (closing open strings, arrays and
+// objects, dropping a trailing partial key or value) and unmarshals it.
+// The bool result is false when the prefix cannot be repaired yet.
+func ParsePartial[T any](prefix string) (T, bool) {
+	var value T
+
+	completed := completeJSON(prefix)
+	if completed == "" {
+		return value, false
+	}
+	if err := json.Unmarshal([]byte(completed), &value); err != nil {
+		return value, false
+	}
+	return value, true
+}
+
+// completeJSON turns a JSON prefix into syntactically valid JSON, or ""
+// when it can't.
+func completeJSON(prefix string) string {
+	s := strings.TrimSpace(prefix)
+	// models often wrap structured output in a fence even when asked
+	// for raw JSON
+	s = strings.TrimPrefix(s, "```json")
+	s = strings.TrimPrefix(s, "```")
+	s = strings.TrimSpace(s)
+	if s == "" || (s[0] != '{' && s[0] != '[') {
+		return ""
+	}
+
+	var stack []byte
+	inString := false
+	escaped := false
+	lastComplete := 0 // end of the last structurally complete element
+
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case escaped:
+			escaped = false
+		case c == '\\' && inString:
+			escaped = true
+		case c == '"':
+			inString = !inString
+			if !inString {
+				lastComplete = i + 1
+			}
+		case inString:
+			// string contents don't affect structure
+		case c == '{' || c == '[':
+			stack = append(stack, c)
+			lastComplete = i + 1 // an empty scope is itself valid
+		case c == '}' || c == ']':
+			if len(stack) == 0 {
+				return "" // malformed
+			}
+			stack = stack[:len(stack)-1]
+			lastComplete = i + 1
+		case c == ',' || c == ':':
+			// keep going
+		default:
+			if !isSpaceByte(c) {
+				lastComplete = i + 1 // numbers, true/false/null
+			}
+		}
+	}
+
+	if len(stack) == 0 && !inString {
+		return s // already complete
+	}
+
+	// cut back to the last complete element so we don't emit a
+	// truncated key or half a number, then drop a dangling separator
+	out := strings.TrimRight(s[:lastComplete], " \t\n\r")
+	out = strings.TrimRight(out, ",:")
+	if out == "" {
+		return ""
+	}
+
+	// re-derive the open scopes for the truncated prefix
+	stack = stack[:0]
+	inString = false
+	escaped = false
+	for i := 0; i < len(out); i++ {
+		c := out[i]
+		switch {
+		case escaped:
+			escaped = false
+		case c == '\\' && inString:
+			escaped = true
+		case c == '"':
+			inString = !inString
+		case inString:
+		case c == '{' || c == '[':
+			stack = append(stack, c)
+		case c == '}' || c == ']':
+			stack = stack[:len(stack)-1]
+		}
+	}
+	if inString {
+		return ""
+	}
+
+	// a truncated object may end in a key with no value (the pending
+	// colon was already trimmed): drop the key. A trailing string that
+	// follows a colon is a complete value and is kept.
+	if len(stack) > 0 && stack[len(stack)-1] == '{' && strings.HasSuffix(out, `"`) && !stringIsValue(out) {
+		if idx := strings.LastIndexAny(out[:len(out)-1], ",{"); idx >= 0 {
+			out = strings.TrimRight(out[:idx+1], ",")
+		}
+	}
+
+	for i := len(stack) - 1; i >= 0; i-- {
+		if stack[i] == '{' {
+			out += "}"
+		} else {
+			out += "]"
+		}
+	}
+	return out
+}
+
+// stringIsValue reports whether the trailing quoted string in out is an
+// object value (preceded by ':') rather than a key.
+func stringIsValue(out string) bool {
+	end := len(out) - 1 // closing quote
+	start := -1
+	for i := end - 1; i >= 0; i-- {
+		if out[i] == '"' && (i == 0 || out[i-1] != '\\') {
+			start = i
+			break
+		}
+	}
+	for i := start - 1; i >= 0; i-- {
+		switch out[i] {
+		case ' ', '\t', '\n', '\r':
+			continue
+		case ':':
+			return true
+		default:
+			return false
+		}
+	}
+	return false
+}
+
+func isSpaceByte(c byte) bool {
+	return c == ' ' || c == '\t' || c == '\n' || c == '\r'
+}
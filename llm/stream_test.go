@@ -0,0 +1,61 @@
+package llm
+
+import "testing"
+
+type extraction struct {
+	Name  string   `json:"name"`
+	Items []string `json:"items"`
+}
+
+func TestParsePartial(t *testing.T) {
+	tests := []struct {
+		prefix   string
+		wantOK   bool
+		wantName string
+		wantLen  int
+	}{
+		{`{"name": "api", "items": ["a", "b"]}`, true, "api", 2},
+		{`{"name": "api", "items": ["a", "b"`, true, "api", 2},
+		{`{"name": "api", "items": ["a", "b`, true, "api", 1},
+		{`{"name": "api", "ite`, true, "api", 0},
+		{`{"name": "ap`, true, "", 0},
+		{`{"na`, true, "", 0},
+		{"```json\n{\"name\": \"api\"", true, "api", 0},
+		{`not json`, false, "", 0},
+	}
+
+	for _, test := range tests {
+		got, ok := ParsePartial[extraction](test.prefix)
+		if ok != test.wantOK {
+			t.Errorf("ParsePartial(%q) ok = %v, want %v", test.prefix, ok, test.wantOK)
+			continue
+		}
+		if got.Name != test.wantName || len(got.Items) != test.wantLen {
+			t.Errorf("ParsePartial(%q) = %+v, want name=%q items=%d", test.prefix, got, test.wantName, test.wantLen)
+		}
+	}
+}
+
+func TestPartialDecoder(t *testing.T) {
+	var updates []extraction
+	decoder := PartialDecoder[extraction]{OnUpdate: func(partial extraction) {
+		updates = append(updates, partial)
+	}}
+
+	for _, chunk := range []string{`{"name": "a`, `pi", "items": [`, `"a"]}`} {
+		if _, err := decoder.Write([]byte(chunk)); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	final, err := decoder.Final()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if final.Name != "api" || len(final.Items) != 1 {
+		t.Errorf("final = %+v", final)
+	}
+	if len(updates) == 0 {
+		t.Error("expected at least one partial update")
+	}
+}
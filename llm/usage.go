@@ -0,0 +1,81 @@
+package llm
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/flanksource/commons-db/llm/cache"
+)
+
+// UsageFormat selects the ExportUsage output format.
+type UsageFormat string
+
+const (
+	// UsageFormatOpenAI mirrors the OpenAI usage API response shape so
+	// existing cost dashboards can ingest the output unchanged.
+	UsageFormatOpenAI UsageFormat = "openai"
+	UsageFormatCSV    UsageFormat = "csv"
+)
+
+// openAIUsage mirrors the OpenAI /v1/usage response.
+type openAIUsage struct {
+	Object string             `json:"object"`
+	Data   []openAIUsageEntry `json:"data"`
+}
+
+type openAIUsageEntry struct {
+	AggregationTimestamp  int64  `json:"aggregation_timestamp"`
+	NRequests             int64  `json:"n_requests"`
+	NContextTokensTotal   int64  `json:"n_context_tokens_total"`
+	NGeneratedTokensTotal int64  `json:"n_generated_tokens_total"`
+	SnapshotID            string `json:"snapshot_id"` // model name
+}
+
+// ExportUsage renders usage aggregates from the local cache since the
+// given time in the requested format.
+func ExportUsage(c *cache.Cache, since time.Time, format UsageFormat) ([]byte, error) {
+	rows, err := c.UsageSince(since)
+	if err != nil {
+		return nil, fmt.Errorf("failed to aggregate usage: %w", err)
+	}
+
+	switch format {
+	case UsageFormatOpenAI, "":
+		out := openAIUsage{Object: "list", Data: make([]openAIUsageEntry, 0, len(rows))}
+		for _, row := range rows {
+			out.Data = append(out.Data, openAIUsageEntry{
+				AggregationTimestamp:  row.Day.Unix(),
+				NRequests:             row.Requests,
+				NContextTokensTotal:   row.PromptTokens,
+				NGeneratedTokensTotal: row.CompletionTokens,
+				SnapshotID:            row.Model,
+			})
+		}
+		return json.MarshalIndent(out, "", "  ")
+
+	case UsageFormatCSV:
+		var buf bytes.Buffer
+		w := csv.NewWriter(&buf)
+		_ = w.Write([]string{"date", "model", "requests", "cache_hits", "prompt_tokens", "completion_tokens", "cost_usd"})
+		for _, row := range rows {
+			_ = w.Write([]string{
+				row.Day.Format("2006-01-02"),
+				row.Model,
+				strconv.FormatInt(row.Requests, 10),
+				strconv.FormatInt(row.CacheHits, 10),
+				strconv.FormatInt(row.PromptTokens, 10),
+				strconv.FormatInt(row.CompletionTokens, 10),
+				strconv.FormatFloat(row.Cost, 'f', 6, 64),
+			})
+		}
+		w.Flush()
+		return buf.Bytes(), w.Error()
+
+	default:
+		return nil, fmt.Errorf("unknown usage format: %s", format)
+	}
+}
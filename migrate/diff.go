@@ -0,0 +1,214 @@
+package migrate
+
+import (
+	"database/sql"
+	"fmt"
+	"regexp"
+	"strings"
+
+	_ "github.com/jackc/pgx/v5/stdlib" // pgx database/sql driver
+
+	"github.com/flanksource/commons-db/api"
+	"github.com/flanksource/commons-db/context"
+)
+
+// Diff treats the embedded scripts as a declarative description of the
+// schema and renders the SQL statements needed to bring a live database
+// up to it, without executing anything. It covers the structures the
+// scripts declare — tables, columns, indexes, views and functions — so
+// an operator can review exactly what a migration run would change in
+// production.
+//
+// The output is informational: it is rendered from the script sources,
+// not fed back through the executor. Running migrations remains the way
+// to apply it.
+func Diff(ctx context.Context, connection string) ([]string, error) {
+	pool, err := sql.Open("pgx", connection)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open connection: %w", err)
+	}
+	defer pool.Close() //nolint:errcheck
+
+	scripts, err := allScripts(api.DefaultConfig)
+	if err != nil {
+		return nil, err
+	}
+
+	var statements []string
+	for _, script := range scripts {
+		scriptStatements, err := diffScript(ctx, pool, script)
+		if err != nil {
+			return nil, fmt.Errorf("failed to diff %s: %w", script.Path, err)
+		}
+		statements = append(statements, scriptStatements...)
+	}
+	return statements, nil
+}
+
+var (
+	createTableRE  = regexp.MustCompile(`(?i)^CREATE\s+TABLE\s+(?:IF\s+NOT\s+EXISTS\s+)?([\w".]+)`)
+	createIndexRE  = regexp.MustCompile(`(?i)^CREATE\s+(?:UNIQUE\s+)?INDEX\s+(?:IF\s+NOT\s+EXISTS\s+)?([\w"]+)`)
+	alterColumnRE  = regexp.MustCompile(`(?i)^ALTER\s+TABLE\s+([\w".]+)\s+ADD\s+COLUMN\s+(?:IF\s+NOT\s+EXISTS\s+)?([\w"]+)`)
+	columnLineRE   = regexp.MustCompile(`^\s*([\w"]+)\s+\w`)
+	tableConstrRE  = regexp.MustCompile(`(?i)^\s*(PRIMARY|UNIQUE|CHECK|CONSTRAINT|FOREIGN|EXCLUDE)`)
+	createObjectRE = createStatementRE // views and functions, shared with drift.go
+)
+
+// diffScript renders the statements of one script that would change the
+// live database: missing tables and indexes are reported as their full
+// CREATE statement, existing tables are reduced to the ALTERs for their
+// missing columns, and absent views/functions are reported as their
+// CREATE OR REPLACE.
+func diffScript(ctx context.Context, pool *sql.DB, script Script) ([]string, error) {
+	var out []string
+	for _, statement := range splitStatements(script.Content) {
+		switch {
+		case createTableRE.MatchString(statement):
+			table := unquote(createTableRE.FindStringSubmatch(statement)[1])
+			exists, err := tableExists(ctx, pool, table)
+			if err != nil {
+				return nil, err
+			}
+			if !exists {
+				out = append(out, statement)
+				continue
+			}
+			alters, err := missingColumns(ctx, pool, table, statement)
+			if err != nil {
+				return nil, err
+			}
+			out = append(out, alters...)
+
+		case createIndexRE.MatchString(statement):
+			index := unquote(createIndexRE.FindStringSubmatch(statement)[1])
+			exists, err := indexExists(ctx, pool, index)
+			if err != nil {
+				return nil, err
+			}
+			if !exists {
+				out = append(out, statement)
+			}
+
+		case alterColumnRE.MatchString(statement):
+			match := alterColumnRE.FindStringSubmatch(statement)
+			exists, err := columnExists(ctx, pool, unquote(match[1]), unquote(match[2]))
+			if err != nil {
+				return nil, err
+			}
+			if !exists {
+				out = append(out, statement)
+			}
+
+		case createObjectRE.MatchString(statement):
+			match := createObjectRE.FindStringSubmatch(statement)
+			object := dbObject{Kind: strings.ToLower(match[1]), Name: unquote(match[2])}
+			live, err := liveDefinition(pool, object)
+			if err != nil {
+				return nil, err
+			}
+			// Only absence is detectable from the sources (the server
+			// normalizes definitions); drifted-but-present objects are
+			// Verify's job.
+			if live == "" {
+				out = append(out, statement)
+			}
+		}
+	}
+	return out, nil
+}
+
+// splitStatements breaks a script into its top-level statements,
+// honoring dollar-quoted function bodies. Comment-only lines are
+// dropped.
+func splitStatements(content string) []string {
+	var statements []string
+	var current strings.Builder
+	inDollarQuote := false
+
+	for _, line := range strings.Split(content, "\n") {
+		if strings.HasPrefix(strings.TrimSpace(line), "--") && current.Len() == 0 {
+			continue
+		}
+		if strings.Count(line, "$$")%2 == 1 {
+			inDollarQuote = !inDollarQuote
+		}
+		current.WriteString(line)
+		current.WriteString("\n")
+		if !inDollarQuote && strings.HasSuffix(strings.TrimRight(line, " \t"), ";") {
+			if statement := strings.TrimSpace(current.String()); statement != "" {
+				statements = append(statements, statement)
+			}
+			current.Reset()
+		}
+	}
+	if statement := strings.TrimSpace(current.String()); statement != "" {
+		statements = append(statements, statement)
+	}
+	return statements
+}
+
+// missingColumns renders ALTER TABLE ADD COLUMN statements for columns
+// the CREATE TABLE statement declares that the live table lacks.
+func missingColumns(ctx context.Context, pool *sql.DB, table, createStatement string) ([]string, error) {
+	live := map[string]bool{}
+	rows, err := pool.QueryContext(ctx, `
+		SELECT column_name FROM information_schema.columns
+		WHERE table_schema = 'public' AND table_name = $1`, table)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close() //nolint:errcheck
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			return nil, err
+		}
+		live[name] = true
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	var out []string
+	body := createStatement
+	if open := strings.Index(body, "("); open >= 0 {
+		body = body[open+1:]
+	}
+	for _, line := range strings.Split(body, "\n") {
+		if tableConstrRE.MatchString(line) || !columnLineRE.MatchString(line) {
+			continue
+		}
+		definition := strings.TrimRight(strings.TrimSpace(line), ",")
+		column := unquote(columnLineRE.FindStringSubmatch(line)[1])
+		if !live[column] {
+			out = append(out, fmt.Sprintf("ALTER TABLE %s ADD COLUMN %s;", table, definition))
+		}
+	}
+	return out, nil
+}
+
+func tableExists(ctx context.Context, pool *sql.DB, table string) (bool, error) {
+	var exists bool
+	err := pool.QueryRowContext(ctx, `SELECT to_regclass($1) IS NOT NULL`, table).Scan(&exists)
+	return exists, err
+}
+
+func indexExists(ctx context.Context, pool *sql.DB, index string) (bool, error) {
+	var exists bool
+	err := pool.QueryRowContext(ctx, `SELECT EXISTS (SELECT FROM pg_indexes WHERE indexname = $1)`, index).Scan(&exists)
+	return exists, err
+}
+
+func columnExists(ctx context.Context, pool *sql.DB, table, column string) (bool, error) {
+	var exists bool
+	err := pool.QueryRowContext(ctx, `
+		SELECT EXISTS (
+			SELECT FROM information_schema.columns
+			WHERE table_schema = 'public' AND table_name = $1 AND column_name = $2
+		)`, table, column).Scan(&exists)
+	return exists, err
+}
+
+func unquote(s string) string {
+	return strings.ReplaceAll(s, `"`, "")
+}
@@ -0,0 +1,178 @@
+package migrate
+
+import (
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/flanksource/commons-db/api"
+)
+
+// Views and functions are re-runnable scripts, so migration_logs hashes
+// only prove the embedded script ran — not that the live definition
+// still matches it. An operator hot-patching a view in production leaves
+// migration_logs intact. To catch that, runMigrations snapshots the
+// live definition of every view/function the scripts create into
+// migration_object_snapshots; Verify re-reads the live definitions and
+// reports anything that changed (or disappeared) since.
+
+// createStatementRE matches the objects a script creates; name may be
+// schema-qualified and/or quoted.
+var createStatementRE = regexp.MustCompile(`(?i)CREATE\s+(?:OR\s+REPLACE\s+)?(VIEW|FUNCTION)\s+(?:IF\s+NOT\s+EXISTS\s+)?([\w".]+)`)
+
+// dbObject is a view or function created by an embedded script.
+type dbObject struct {
+	Kind string // "view" or "function"
+	Name string
+}
+
+// objectsInScripts extracts the views and functions the embedded scripts
+// create, deduplicated and in first-seen order.
+func objectsInScripts(scripts []Script) []dbObject {
+	seen := map[dbObject]bool{}
+	var objects []dbObject
+	for _, script := range scripts {
+		for _, match := range createStatementRE.FindAllStringSubmatch(script.Content, -1) {
+			object := dbObject{
+				Kind: strings.ToLower(match[1]),
+				Name: strings.ReplaceAll(match[2], `"`, ""),
+			}
+			if !seen[object] {
+				seen[object] = true
+				objects = append(objects, object)
+			}
+		}
+	}
+	return objects
+}
+
+// liveDefinition returns the server's current definition of the object,
+// or "" when it doesn't exist. Overloaded functions are aggregated so
+// adding or editing any overload registers as drift.
+func liveDefinition(pool *sql.DB, object dbObject) (string, error) {
+	schema, name := "public", object.Name
+	if before, after, found := strings.Cut(object.Name, "."); found {
+		schema, name = before, after
+	}
+
+	var query string
+	switch object.Kind {
+	case "view":
+		query = `SELECT COALESCE(pg_get_viewdef(to_regclass($1 || '.' || $2)), '')`
+	case "function":
+		query = `
+			SELECT COALESCE(string_agg(pg_get_functiondef(p.oid), E'\n' ORDER BY p.oid), '')
+			FROM pg_proc p
+			JOIN pg_namespace n ON n.oid = p.pronamespace
+			WHERE n.nspname = $1 AND p.proname = $2`
+	default:
+		return "", fmt.Errorf("unknown object kind %q", object.Kind)
+	}
+
+	var definition string
+	if err := pool.QueryRow(query, schema, name).Scan(&definition); err != nil {
+		return "", fmt.Errorf("failed to read live definition of %s %s: %w", object.Kind, object.Name, err)
+	}
+	return definition, nil
+}
+
+func definitionHash(definition string) string {
+	hash := sha256.Sum256([]byte(definition))
+	return hex.EncodeToString(hash[:])
+}
+
+// snapshotObjects records the live definition hash of every view and
+// function the embedded scripts create. Called at the end of a
+// migration run, so the snapshot always reflects what the scripts just
+// (re)applied.
+func snapshotObjects(pool *sql.DB, config api.Config) error {
+	if _, err := pool.Exec(`
+		CREATE TABLE IF NOT EXISTS migration_object_snapshots (
+			kind       TEXT NOT NULL,
+			name       TEXT NOT NULL,
+			hash       TEXT NOT NULL,
+			updated_at TIMESTAMP NOT NULL DEFAULT NOW(),
+			PRIMARY KEY (kind, name)
+		)`); err != nil {
+		return fmt.Errorf("failed to create migration_object_snapshots: %w", err)
+	}
+
+	scripts, err := allScripts(config)
+	if err != nil {
+		return err
+	}
+
+	for _, object := range objectsInScripts(scripts) {
+		definition, err := liveDefinition(pool, object)
+		if err != nil {
+			return err
+		}
+		if definition == "" {
+			// conditionally-created or since-dropped by a later script
+			continue
+		}
+		if _, err := pool.Exec(`
+			INSERT INTO migration_object_snapshots (kind, name, hash)
+			VALUES ($1, $2, $3)
+			ON CONFLICT (kind, name) DO UPDATE SET hash = $3, updated_at = NOW()`,
+			object.Kind, object.Name, definitionHash(definition)); err != nil {
+			return fmt.Errorf("failed to snapshot %s %s: %w", object.Kind, object.Name, err)
+		}
+	}
+	return nil
+}
+
+// Drift is a view or function whose live definition no longer matches
+// the snapshot taken when migrations last ran.
+type Drift struct {
+	Kind string
+	Name string
+
+	// Missing is true when the object was dropped entirely.
+	Missing bool
+}
+
+func (d Drift) String() string {
+	if d.Missing {
+		return fmt.Sprintf("%s %s: dropped since migrations last ran", d.Kind, d.Name)
+	}
+	return fmt.Sprintf("%s %s: live definition changed since migrations last ran", d.Kind, d.Name)
+}
+
+// Verify compares the live definition of every snapshotted view and
+// function against the hash recorded at the end of the last migration
+// run, catching manual edits made directly in production. An empty
+// result means the live objects match what the migrations installed;
+// re-running migrations (which re-applies the scripts) clears drift.
+func Verify(pool *sql.DB) ([]Drift, error) {
+	rows, err := pool.Query("SELECT kind, name, hash FROM migration_object_snapshots")
+	if err != nil {
+		return nil, fmt.Errorf("failed to read migration_object_snapshots (have migrations run?): %w", err)
+	}
+	defer rows.Close() //nolint:errcheck
+
+	var drifts []Drift
+	for rows.Next() {
+		var object dbObject
+		var snapshotHash string
+		if err := rows.Scan(&object.Kind, &object.Name, &snapshotHash); err != nil {
+			return nil, err
+		}
+
+		definition, err := liveDefinition(pool, object)
+		if err != nil {
+			return nil, err
+		}
+		if definition == "" {
+			drifts = append(drifts, Drift{Kind: object.Kind, Name: object.Name, Missing: true})
+			continue
+		}
+		if definitionHash(definition) != snapshotHash {
+			drifts = append(drifts, Drift{Kind: object.Kind, Name: object.Name})
+		}
+	}
+	return drifts, rows.Err()
+}
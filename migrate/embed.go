@@ -0,0 +1,6 @@
+package migrate
+
+import "embed"
+
+//go:embed scripts/*.sql
+var scriptFS embed.FS
@@ -0,0 +1,42 @@
+package migrate
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+)
+
+// MigrationLog is one audited migration run.
+type MigrationLog struct {
+	Path       string        `json:"path"`
+	Hash       string        `json:"hash"`
+	Duration   time.Duration `json:"duration"`
+	ExecutedBy string        `json:"executedBy,omitempty"`
+	AppVersion string        `json:"appVersion,omitempty"`
+	UpdatedAt  time.Time     `json:"updatedAt"`
+}
+
+// History returns the audit trail of applied migrations, most recent
+// first.
+func History(pool *sql.DB) ([]MigrationLog, error) {
+	rows, err := pool.Query(`
+		SELECT path, hash, COALESCE(duration_ms, 0), COALESCE(executed_by, ''), COALESCE(app_version, ''), updated_at
+		FROM migration_logs
+		ORDER BY updated_at DESC`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read migration history: %w", err)
+	}
+	defer rows.Close() //nolint:errcheck
+
+	var logs []MigrationLog
+	for rows.Next() {
+		var log MigrationLog
+		var durationMs int64
+		if err := rows.Scan(&log.Path, &log.Hash, &durationMs, &log.ExecutedBy, &log.AppVersion, &log.UpdatedAt); err != nil {
+			return nil, err
+		}
+		log.Duration = time.Duration(durationMs) * time.Millisecond
+		logs = append(logs, log)
+	}
+	return logs, rows.Err()
+}
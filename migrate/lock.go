@@ -0,0 +1,47 @@
+package migrate
+
+import (
+	gocontext "context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/flanksource/commons-db/context"
+)
+
+// advisoryLockID identifies this library's migration lock; any value
+// works as long as every replica uses the same one.
+const advisoryLockID = 5432_0001
+
+// defaultLockTimeout is how long a replica waits for a sibling's
+// migration run to finish; override with the migrate.lock_timeout
+// property.
+const defaultLockTimeout = 5 * time.Minute
+
+// withAdvisoryLock runs fn holding a session-level advisory lock, so
+// replicas starting simultaneously serialize their migration runs
+// instead of racing on DDL. The lock is tied to a dedicated connection
+// and released (and the connection returned) when fn completes.
+func withAdvisoryLock(pool *sql.DB, fn func() error) error {
+	timeout := context.GlobalProperties().Duration("migrate.lock_timeout", defaultLockTimeout)
+	ctx, cancel := gocontext.WithTimeout(gocontext.Background(), timeout)
+	defer cancel()
+
+	conn, err := pool.Conn(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to acquire connection for migration lock: %w", err)
+	}
+	defer conn.Close() //nolint:errcheck
+
+	if _, err := conn.ExecContext(ctx, "SELECT pg_advisory_lock($1)", advisoryLockID); err != nil {
+		if ctx.Err() != nil {
+			return fmt.Errorf("timed out after %s waiting for the migration lock (is another replica migrating?)", timeout)
+		}
+		return fmt.Errorf("failed to acquire migration lock: %w", err)
+	}
+	// unlock on the same connection; without a timeout so a long fn
+	// doesn't leave the lock held by a closed session
+	defer conn.ExecContext(gocontext.Background(), "SELECT pg_advisory_unlock($1)", advisoryLockID) //nolint:errcheck
+
+	return fn()
+}
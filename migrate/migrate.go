@@ -0,0 +1,220 @@
+// Package migrate runs the embedded SQL migration scripts in order,
+// recording each applied script (and its hash) in the migration_logs
+// table so that unchanged scripts are skipped on subsequent runs.
+package migrate
+
+import (
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"fmt"
+	"io/fs"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/flanksource/commons-db/api"
+	"github.com/flanksource/commons/logger"
+)
+
+// requiredExtensions are Postgres extensions this library depends on.
+// pg_trgm backs the fuzzy (~=) search operator.
+var requiredExtensions = []string{"pg_trgm"}
+
+// optionalExtensions enable extra features when installed on the server
+// but do not fail migrations when missing. vector (pgvector) backs
+// types.Vector columns and query.SimilaritySearch.
+var optionalExtensions = []string{"vector"}
+
+// AppVersion is recorded with each migration run for auditing; hosts
+// set it at startup (typically from their build info).
+var AppVersion = "unknown"
+
+// RunMigrations applies all embedded migration scripts that have not run
+// yet (or whose content changed since they last ran). Runs across
+// replicas are serialized with an advisory lock, so a fleet starting
+// simultaneously doesn't race on DDL; replicas that waited re-check the
+// hashes and find nothing left to do.
+func RunMigrations(pool *sql.DB, config api.Config) error {
+	if config.Migrations.Skip {
+		return nil
+	}
+
+	if config.Migrations.DryRun {
+		plan, err := Plan(pool, config)
+		if err != nil {
+			return err
+		}
+		for _, planned := range plan {
+			logger.Infof("dry-run: would run %s migration script %s", planned.Reason, planned.Path)
+		}
+		return nil
+	}
+
+	return withAdvisoryLock(pool, func() error {
+		return runMigrations(pool, config)
+	})
+}
+
+func runMigrations(pool *sql.DB, config api.Config) error {
+	if err := createMigrationLogTable(pool); err != nil {
+		return fmt.Errorf("failed to create migration log table: %w", err)
+	}
+
+	if err := createExtensions(pool); err != nil {
+		return err
+	}
+
+	scripts, err := allScripts(config)
+	if err != nil {
+		return err
+	}
+
+	for _, script := range scripts {
+		if skip, err := skipped(config, script.Path); err != nil {
+			return err
+		} else if skip {
+			logger.Debugf("skipping migration script %s", script.Path)
+			continue
+		}
+
+		var currentHash string
+		row := pool.QueryRow("SELECT hash FROM migration_logs WHERE path = $1", script.Path)
+		if err := row.Scan(&currentHash); err != nil && err != sql.ErrNoRows {
+			return fmt.Errorf("failed to read migration log for %s: %w", script.Path, err)
+		}
+		if currentHash == script.Hash {
+			continue
+		}
+
+		logger.Infof("running migration script %s", script.Path)
+		started := time.Now()
+		if _, err := pool.Exec(script.Content); err != nil {
+			return fmt.Errorf("failed to run migration script %s: %w", script.Path, err)
+		}
+
+		if _, err := pool.Exec(`
+			INSERT INTO migration_logs (path, hash, duration_ms, executed_by, app_version)
+			VALUES ($1, $2, $3, current_user, $4)
+			ON CONFLICT (path) DO UPDATE SET
+				hash = $2, duration_ms = $3, executed_by = current_user,
+				app_version = $4, updated_at = NOW()`,
+			script.Path, script.Hash, time.Since(started).Milliseconds(), AppVersion); err != nil {
+			return fmt.Errorf("failed to record migration %s: %w", script.Path, err)
+		}
+	}
+
+	// re-snapshot view/function definitions for drift detection (see
+	// Verify)
+	return snapshotObjects(pool, config)
+}
+
+// createExtensions ensures required Postgres extensions exist, failing
+// with an actionable error when the extension is unavailable on the
+// server.
+func createExtensions(pool *sql.DB) error {
+	for _, extension := range requiredExtensions {
+		if _, err := pool.Exec(fmt.Sprintf("CREATE EXTENSION IF NOT EXISTS %s", extension)); err != nil {
+			return fmt.Errorf("failed to create extension %s (is it installed on the server?): %w", extension, err)
+		}
+	}
+
+	for _, extension := range optionalExtensions {
+		if _, err := pool.Exec(fmt.Sprintf("CREATE EXTENSION IF NOT EXISTS %s", extension)); err != nil {
+			logger.Warnf("optional extension %s unavailable, dependent features are disabled: %v", extension, err)
+		}
+	}
+	return nil
+}
+
+func createMigrationLogTable(pool *sql.DB) error {
+	_, err := pool.Exec(`
+		CREATE TABLE IF NOT EXISTS migration_logs (
+			path        TEXT PRIMARY KEY,
+			hash        TEXT NOT NULL,
+			duration_ms BIGINT,
+			executed_by TEXT,
+			app_version TEXT,
+			updated_at  TIMESTAMP NOT NULL DEFAULT NOW()
+		)`)
+	if err != nil {
+		return err
+	}
+
+	// audit columns added after the table first shipped
+	for _, column := range []string{
+		"duration_ms BIGINT",
+		"executed_by TEXT",
+		"app_version TEXT",
+	} {
+		if _, err := pool.Exec("ALTER TABLE migration_logs ADD COLUMN IF NOT EXISTS " + column); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Script is a single embedded migration script.
+type Script struct {
+	Path    string
+	Content string
+	Hash    string
+}
+
+// allScripts returns the builtin embedded scripts in lexical order,
+// followed by the scripts of registered filesystems (see RegisterFS).
+// Template placeholders are rendered from config before hashing, so
+// hashes reflect the SQL that actually runs.
+func allScripts(config api.Config) ([]Script, error) {
+	scripts, err := scriptsFromFS(scriptFS, "scripts", "")
+	if err != nil {
+		return nil, fmt.Errorf("failed to read embedded migration scripts: %w", err)
+	}
+
+	registered, err := registeredScripts()
+	if err != nil {
+		return nil, err
+	}
+	scripts = append(scripts, registered...)
+
+	for i, script := range scripts {
+		rendered, err := renderScript(script.Path, script.Content, config)
+		if err != nil {
+			return nil, err
+		}
+		if rendered != script.Content {
+			hash := sha256.Sum256([]byte(rendered))
+			scripts[i].Content = rendered
+			scripts[i].Hash = hex.EncodeToString(hash[:])
+		}
+	}
+	return scripts, nil
+}
+
+// scriptsFromFS collects the .sql files under root in lexical path
+// order, recording each under prefix+path.
+func scriptsFromFS(fsys fs.FS, root, prefix string) ([]Script, error) {
+	var scripts []Script
+	err := fs.WalkDir(fsys, root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() || !strings.HasSuffix(path, ".sql") {
+			return err
+		}
+		content, err := fs.ReadFile(fsys, path)
+		if err != nil {
+			return err
+		}
+		hash := sha256.Sum256(content)
+		scripts = append(scripts, Script{
+			Path:    prefix + path,
+			Content: string(content),
+			Hash:    hex.EncodeToString(hash[:]),
+		})
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	sort.Slice(scripts, func(i, j int) bool { return scripts[i].Path < scripts[j].Path })
+	return scripts, nil
+}
@@ -0,0 +1,72 @@
+package migrate
+
+import (
+	"database/sql"
+	"fmt"
+
+	"github.com/flanksource/commons-db/api"
+)
+
+// PlannedScript is one script RunMigrations would execute, with the
+// reason why.
+type PlannedScript struct {
+	Path string `json:"path"`
+
+	// Reason is "new" for scripts never applied, "changed" for scripts
+	// whose content differs from the recorded run.
+	Reason string `json:"reason"`
+
+	// StoredHash is the hash recorded in migration_logs, empty for new
+	// scripts; Hash is the embedded script's current hash.
+	StoredHash string `json:"storedHash,omitempty"`
+	Hash       string `json:"hash"`
+}
+
+// Plan returns the ordered scripts that RunMigrations would execute for
+// this config, without running anything — for CI review of pending
+// schema changes. A database without a migration_logs table plans every
+// script as new.
+func Plan(pool *sql.DB, config api.Config) ([]PlannedScript, error) {
+	if config.Migrations.Skip {
+		return nil, nil
+	}
+
+	scripts, err := allScripts(config)
+	if err != nil {
+		return nil, err
+	}
+
+	applied := map[string]string{}
+	rows, err := pool.Query("SELECT path, hash FROM migration_logs")
+	if err == nil {
+		defer rows.Close() //nolint:errcheck
+		for rows.Next() {
+			var path, hash string
+			if err := rows.Scan(&path, &hash); err != nil {
+				return nil, err
+			}
+			applied[path] = hash
+		}
+		if err := rows.Err(); err != nil {
+			return nil, fmt.Errorf("failed to read migration logs: %w", err)
+		}
+	}
+
+	var plan []PlannedScript
+	for _, script := range scripts {
+		if skip, err := skipped(config, script.Path); err != nil {
+			return nil, err
+		} else if skip {
+			continue
+		}
+
+		stored, ok := applied[script.Path]
+		switch {
+		case !ok:
+			plan = append(plan, PlannedScript{Path: script.Path, Reason: "new", Hash: script.Hash})
+		case stored != script.Hash:
+			plan = append(plan, PlannedScript{Path: script.Path, Reason: "changed", StoredHash: stored, Hash: script.Hash})
+		}
+	}
+	return plan, nil
+}
@@ -0,0 +1,58 @@
+package migrate
+
+import (
+	"fmt"
+	"io/fs"
+	"sort"
+	"sync"
+)
+
+// Downstream repos can contribute their own schema directories; they are
+// hashed and executed by the same pipeline as the builtin scripts.
+// Builtin scripts always run first, then registered sets in lexical
+// order of their registration name, scripts within a set in path order.
+var (
+	registeredMu sync.Mutex
+	registeredFS = map[string]fs.FS{}
+)
+
+// RegisterFS registers an additional script filesystem under a unique
+// name (typically the consuming module's short name). All .sql files in
+// it are included in migration runs; their migration_logs paths are
+// prefixed with the name, so two consumers can ship scripts with the
+// same filename. Call it from an init() before SetupDB.
+func RegisterFS(name string, scripts fs.FS) {
+	registeredMu.Lock()
+	defer registeredMu.Unlock()
+	if _, exists := registeredFS[name]; exists {
+		panic(fmt.Sprintf("migration fs %q registered twice", name))
+	}
+	registeredFS[name] = scripts
+}
+
+// registeredScripts returns the scripts of all registered filesystems,
+// ordered by registration name then path.
+func registeredScripts() ([]Script, error) {
+	registeredMu.Lock()
+	names := make([]string, 0, len(registeredFS))
+	for name := range registeredFS {
+		names = append(names, name)
+	}
+	filesystems := make(map[string]fs.FS, len(registeredFS))
+	for name, scripts := range registeredFS {
+		filesystems[name] = scripts
+	}
+	registeredMu.Unlock()
+
+	sort.Strings(names)
+
+	var all []Script
+	for _, name := range names {
+		scripts, err := scriptsFromFS(filesystems[name], ".", name+"/")
+		if err != nil {
+			return nil, fmt.Errorf("failed to read registered migration fs %q: %w", name, err)
+		}
+		all = append(all, scripts...)
+	}
+	return all, nil
+}
@@ -0,0 +1,22 @@
+CREATE TABLE IF NOT EXISTS config_items (
+    id          UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    agent_id    UUID,
+    parent_id   UUID REFERENCES config_items (id),
+    name        TEXT,
+    namespace   TEXT,
+    type        TEXT,
+    status      TEXT,
+    health      TEXT,
+    tags        JSONB DEFAULT '{}'::jsonb,
+    labels      JSONB DEFAULT '{}'::jsonb,
+    config      JSONB,
+    created_at  TIMESTAMP NOT NULL DEFAULT NOW(),
+    updated_at  TIMESTAMP NOT NULL DEFAULT NOW(),
+    deleted_at  TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_config_items_type ON config_items (type);
+CREATE INDEX IF NOT EXISTS idx_config_items_tags ON config_items USING GIN (tags);
+
+-- trigram index backing the fuzzy (~=) search operator
+CREATE INDEX IF NOT EXISTS idx_config_items_name_trgm ON config_items USING GIN (name gin_trgm_ops);
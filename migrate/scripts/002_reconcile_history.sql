@@ -0,0 +1,12 @@
+CREATE TABLE IF NOT EXISTS reconcile_history (
+    id          UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    agent_name  TEXT NOT NULL,
+    started_at  TIMESTAMP NOT NULL,
+    duration    BIGINT NOT NULL DEFAULT 0,
+    success     INT NOT NULL DEFAULT 0,
+    fk_failed   INT NOT NULL DEFAULT 0,
+    errors      INT NOT NULL DEFAULT 0,
+    summary     JSONB
+);
+
+CREATE INDEX IF NOT EXISTS idx_reconcile_history_started_at ON reconcile_history (started_at);
@@ -0,0 +1,16 @@
+CREATE TABLE IF NOT EXISTS notification_sends (
+    id              UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    event           TEXT NOT NULL,
+    resource_id     TEXT NOT NULL,
+    recipient       TEXT NOT NULL,
+    severity        TEXT,
+    body            TEXT,
+    created_at      TIMESTAMP NOT NULL DEFAULT NOW(),
+    acked_at        TIMESTAMP,
+    acked_by        TEXT,
+    escalated_from  UUID REFERENCES notification_sends (id),
+    escalation_step INT NOT NULL DEFAULT 0
+);
+
+CREATE INDEX IF NOT EXISTS idx_notification_sends_unacked ON notification_sends (created_at) WHERE acked_at IS NULL;
+CREATE INDEX IF NOT EXISTS idx_notification_sends_escalated_from ON notification_sends (escalated_from);
@@ -0,0 +1,15 @@
+CREATE TABLE IF NOT EXISTS config_changes (
+    id          UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    config_id   UUID NOT NULL REFERENCES config_items (id),
+    change_type TEXT NOT NULL,
+    severity    TEXT,
+    source      TEXT,
+    -- for HealthChanged/StatusChanged rows: {"from": "...", "to": "..."}
+    details     JSONB,
+    created_at  TIMESTAMP NOT NULL DEFAULT NOW(),
+    is_pushed   BOOLEAN NOT NULL DEFAULT FALSE
+);
+
+CREATE INDEX IF NOT EXISTS idx_config_changes_config_id ON config_changes (config_id);
+CREATE INDEX IF NOT EXISTS idx_config_changes_created_at ON config_changes (created_at);
+CREATE INDEX IF NOT EXISTS idx_config_changes_unpushed ON config_changes (is_pushed) WHERE is_pushed IS FALSE;
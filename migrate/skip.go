@@ -0,0 +1,80 @@
+package migrate
+
+import (
+	"fmt"
+	"path"
+	"strings"
+
+	"github.com/flanksource/commons-db/api"
+)
+
+// Profiles are named skip lists for common deployment shapes. An agent
+// only pushes data upstream, so it doesn't need the views and functions
+// that back the UI.
+var Profiles = map[string][]string{
+	"agent": {"views_*.sql", "functions_*.sql"},
+	"full":  {},
+}
+
+// skipped reports whether a script is excluded by the config's ignore
+// patterns, profile, or Only allowlist. Patterns are globs matched
+// against the script path and its base name; a malformed pattern fails
+// the migration run rather than being silently ignored.
+func skipped(config api.Config, scriptPath string) (bool, error) {
+	if len(config.Migrations.Only) > 0 && !inGroups(scriptPath, config.Migrations.Only) {
+		return true, nil
+	}
+
+	patterns := config.Migrations.IgnoreFiles
+	if config.Migrations.Profile != "" {
+		profile, ok := Profiles[config.Migrations.Profile]
+		if !ok {
+			return false, fmt.Errorf("unknown migration profile %q", config.Migrations.Profile)
+		}
+		patterns = append(patterns, profile...)
+	}
+
+	for _, pattern := range patterns {
+		for _, candidate := range []string{scriptPath, path.Base(scriptPath)} {
+			match, err := path.Match(pattern, candidate)
+			if err != nil {
+				return false, fmt.Errorf("invalid migration ignore pattern %q: %w", pattern, err)
+			}
+			if match {
+				return true, nil
+			}
+		}
+	}
+	return false, nil
+}
+
+// inGroups reports whether the script belongs to any of the named
+// groups (see MigrationMode.Only). A script's groups are its top-level
+// directory ("scripts" for builtins, the registration name for
+// registered sets) and its base-name prefix up to the first underscore,
+// with any numeric ordering prefix stripped — so "views_topology.sql"
+// is in group "views" and "functions/001_rollup.sql" is in both
+// "functions" and "rollup".
+func inGroups(scriptPath string, groups []string) bool {
+	candidates := map[string]bool{}
+	if dir, _, found := strings.Cut(scriptPath, "/"); found {
+		candidates[dir] = true
+	}
+
+	base := strings.TrimSuffix(path.Base(scriptPath), ".sql")
+	if i := strings.IndexFunc(base, func(r rune) bool { return r < '0' || r > '9' }); i > 0 && base[i] == '_' {
+		base = base[i+1:]
+	}
+	if prefix, _, found := strings.Cut(base, "_"); found {
+		candidates[prefix] = true
+	} else {
+		candidates[base] = true
+	}
+
+	for _, group := range groups {
+		if candidates[group] {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,41 @@
+package migrate
+
+import (
+	"fmt"
+	"strings"
+	"text/template"
+
+	"github.com/flanksource/commons-db/api"
+)
+
+// Scripts may use Go-template placeholders — {{.Schema}} and {{.Role}}
+// — resolved from api.Config, so the same embedded scripts can target
+// non-default schemas and role names without downstream string
+// replacement. Rendering happens before hashing, so changing the
+// template values re-runs the affected scripts.
+
+// templateData is the data available to script templates.
+type templateData struct {
+	Schema string
+	Role   string
+}
+
+// renderScript expands template placeholders in content. Scripts
+// without placeholders pass through byte-identical, keeping their
+// historical hashes stable.
+func renderScript(path, content string, config api.Config) (string, error) {
+	if !strings.Contains(content, "{{") {
+		return content, nil
+	}
+
+	tmpl, err := template.New(path).Option("missingkey=error").Parse(content)
+	if err != nil {
+		return "", fmt.Errorf("failed to parse template in %s: %w", path, err)
+	}
+
+	var rendered strings.Builder
+	if err := tmpl.Execute(&rendered, templateData{Schema: config.Schema, Role: config.Role}); err != nil {
+		return "", fmt.Errorf("failed to render template in %s: %w", path, err)
+	}
+	return rendered.String(), nil
+}
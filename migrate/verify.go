@@ -0,0 +1,100 @@
+package migrate
+
+import (
+	"database/sql"
+	"fmt"
+
+	"github.com/flanksource/commons-db/api"
+)
+
+// HashMismatch is a migration_logs entry whose recorded hash no longer
+// matches the embedded script — i.e. the script was edited after it ran,
+// which usually indicates rewritten migration history.
+type HashMismatch struct {
+	Path         string
+	StoredHash   string
+	EmbeddedHash string
+
+	// Orphaned is true when the log entry has no corresponding
+	// embedded script at all.
+	Orphaned bool
+}
+
+func (t HashMismatch) String() string {
+	if t.Orphaned {
+		return fmt.Sprintf("%s: recorded in migration_logs but no longer embedded", t.Path)
+	}
+	return fmt.Sprintf("%s: stored hash %.12s does not match embedded %.12s", t.Path, t.StoredHash, t.EmbeddedHash)
+}
+
+// VerifyHashes compares every migration_logs entry against the embedded
+// scripts and returns the mismatches. An empty result means the applied
+// history matches the binary's embedded sources. Templated scripts are
+// rendered with api.DefaultConfig; deployments overriding Schema/Role
+// should compare against migrate.Plan output instead.
+func VerifyHashes(pool *sql.DB) ([]HashMismatch, error) {
+	scripts, err := allScripts(api.DefaultConfig)
+	if err != nil {
+		return nil, err
+	}
+	embedded := make(map[string]string, len(scripts))
+	for _, script := range scripts {
+		embedded[script.Path] = script.Hash
+	}
+
+	rows, err := pool.Query("SELECT path, hash FROM migration_logs")
+	if err != nil {
+		return nil, fmt.Errorf("failed to read migration_logs: %w", err)
+	}
+	defer rows.Close() //nolint:errcheck
+
+	var mismatches []HashMismatch
+	for rows.Next() {
+		var path, storedHash string
+		if err := rows.Scan(&path, &storedHash); err != nil {
+			return nil, err
+		}
+
+		embeddedHash, ok := embedded[path]
+		if !ok {
+			mismatches = append(mismatches, HashMismatch{Path: path, StoredHash: storedHash, Orphaned: true})
+			continue
+		}
+		if embeddedHash != storedHash {
+			mismatches = append(mismatches, HashMismatch{Path: path, StoredHash: storedHash, EmbeddedHash: embeddedHash})
+		}
+	}
+	return mismatches, rows.Err()
+}
+
+// Rebaseline overwrites the stored hashes with the embedded ones
+// without re-executing any script, reconciling VerifyHashes mismatches
+// after a deliberate history edit. It is the implementation behind
+// --force-rebaseline and must only be run once the operator has
+// confirmed the live schema matches the embedded scripts.
+func Rebaseline(pool *sql.DB) error {
+	mismatches, err := VerifyHashes(pool)
+	if err != nil {
+		return err
+	}
+
+	tx, err := pool.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback() //nolint:errcheck
+
+	for _, mismatch := range mismatches {
+		if mismatch.Orphaned {
+			if _, err := tx.Exec("DELETE FROM migration_logs WHERE path = $1", mismatch.Path); err != nil {
+				return fmt.Errorf("failed to remove orphaned log %s: %w", mismatch.Path, err)
+			}
+			continue
+		}
+		if _, err := tx.Exec("UPDATE migration_logs SET hash = $1, updated_at = NOW() WHERE path = $2",
+			mismatch.EmbeddedHash, mismatch.Path); err != nil {
+			return fmt.Errorf("failed to rebaseline %s: %w", mismatch.Path, err)
+		}
+	}
+	return tx.Commit()
+}
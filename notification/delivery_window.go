@@ -0,0 +1,164 @@
+package notification
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// criticalSeverities bypass delivery windows and quiet hours.
+var criticalSeverities = []string{"critical", "emergency"}
+
+// DeliveryWindow is a recurring window (per weekday, in a timezone)
+// during which notifications may be delivered, e.g. business hours.
+type DeliveryWindow struct {
+	// Timezone is an IANA name like "Europe/Berlin"; UTC when empty.
+	Timezone string `json:"timezone,omitempty" yaml:"timezone,omitempty"`
+
+	// Days the window applies to (e.g. "monday"); every day when empty.
+	Days []string `json:"days,omitempty" yaml:"days,omitempty"`
+
+	// Start and End are times of day as "15:04". An End before Start
+	// wraps past midnight.
+	Start string `json:"start" yaml:"start"`
+	End   string `json:"end" yaml:"end"`
+}
+
+// DeliverySchedule combines delivery windows and quiet hours for a
+// channel or team. Windows allow, quiet hours veto: a send must fall in
+// some window (if any are configured) and in no quiet period.
+type DeliverySchedule struct {
+	Windows    []DeliveryWindow `json:"windows,omitempty" yaml:"windows,omitempty"`
+	QuietHours []DeliveryWindow `json:"quietHours,omitempty" yaml:"quietHours,omitempty"`
+}
+
+// Validate checks all window definitions, so malformed schedules are
+// rejected at config load instead of silently never matching.
+func (t DeliverySchedule) Validate() error {
+	for _, w := range append(append([]DeliveryWindow{}, t.Windows...), t.QuietHours...) {
+		if err := w.validate(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (w DeliveryWindow) validate() error {
+	if _, err := w.location(); err != nil {
+		return fmt.Errorf("invalid timezone %q: %w", w.Timezone, err)
+	}
+	for _, value := range []string{w.Start, w.End} {
+		if _, err := time.Parse("15:04", value); err != nil {
+			return fmt.Errorf("invalid time of day %q (want HH:MM): %w", value, err)
+		}
+	}
+	for _, day := range w.Days {
+		if _, ok := parseWeekday(day); !ok {
+			return fmt.Errorf("invalid weekday %q", day)
+		}
+	}
+	return nil
+}
+
+func (w DeliveryWindow) location() (*time.Location, error) {
+	if w.Timezone == "" {
+		return time.UTC, nil
+	}
+	return time.LoadLocation(w.Timezone)
+}
+
+// contains reports whether the instant falls inside the window.
+func (w DeliveryWindow) contains(at time.Time) bool {
+	loc, err := w.location()
+	if err != nil {
+		return false
+	}
+	local := at.In(loc)
+
+	if len(w.Days) > 0 {
+		match := false
+		for _, day := range w.Days {
+			if weekday, ok := parseWeekday(day); ok && weekday == local.Weekday() {
+				match = true
+				break
+			}
+		}
+		if !match {
+			return false
+		}
+	}
+
+	minutes := local.Hour()*60 + local.Minute()
+	start := minutesOfDay(w.Start)
+	end := minutesOfDay(w.End)
+	if start <= end {
+		return minutes >= start && minutes < end
+	}
+	return minutes >= start || minutes < end // wraps midnight
+}
+
+// Deliverable reports whether a send of the given severity may go out
+// at the given time. Critical severities always pass.
+func (t DeliverySchedule) Deliverable(at time.Time, severity string) bool {
+	if contains(criticalSeverities, strings.ToLower(severity)) {
+		return true
+	}
+
+	for _, quiet := range t.QuietHours {
+		if quiet.contains(at) {
+			return false
+		}
+	}
+
+	if len(t.Windows) == 0 {
+		return true
+	}
+	for _, window := range t.Windows {
+		if window.contains(at) {
+			return true
+		}
+	}
+	return false
+}
+
+// NextDeliverable returns the earliest instant at or after `at` when a
+// send becomes deliverable, scanning in minute steps up to a week out.
+// Deferred sends go back on the retry queue with this as their next
+// attempt time.
+func (t DeliverySchedule) NextDeliverable(at time.Time, severity string) time.Time {
+	at = at.Truncate(time.Minute)
+	for probe := at; probe.Before(at.Add(7 * 24 * time.Hour)); probe = probe.Add(time.Minute) {
+		if t.Deliverable(probe, severity) {
+			return probe
+		}
+	}
+	return at // schedule never opens; don't defer forever
+}
+
+func parseWeekday(day string) (time.Weekday, bool) {
+	switch strings.ToLower(day) {
+	case "sunday":
+		return time.Sunday, true
+	case "monday":
+		return time.Monday, true
+	case "tuesday":
+		return time.Tuesday, true
+	case "wednesday":
+		return time.Wednesday, true
+	case "thursday":
+		return time.Thursday, true
+	case "friday":
+		return time.Friday, true
+	case "saturday":
+		return time.Saturday, true
+	}
+	return 0, false
+}
+
+func minutesOfDay(value string) int {
+	t, err := time.Parse("15:04", value)
+	if err != nil {
+		return 0
+	}
+	return t.Hour()*60 + t.Minute()
+}
@@ -0,0 +1,169 @@
+package notification
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/flanksource/commons-db/context"
+)
+
+// Send is a persisted record of a delivered notification. Escalated
+// sends link back to the original via EscalatedFrom, so the full chain
+// for an event can be reconstructed.
+type Send struct {
+	ID         string     `json:"id" gorm:"default:gen_random_uuid()"`
+	Event      string     `json:"event"`
+	ResourceID string     `json:"resource_id"`
+	Recipient  string     `json:"recipient"`
+	Severity   string     `json:"severity,omitempty"`
+	Body       string     `json:"body,omitempty"`
+	CreatedAt  time.Time  `json:"created_at"`
+	AckedAt    *time.Time `json:"acked_at,omitempty"`
+	AckedBy    string     `json:"acked_by,omitempty"`
+
+	// EscalatedFrom is the id of the send this one escalates, nil for
+	// the original.
+	EscalatedFrom *string `json:"escalated_from,omitempty"`
+
+	// EscalationStep is the 1-based index into the policy's steps.
+	EscalationStep int `json:"escalation_step,omitempty"`
+}
+
+func (Send) TableName() string {
+	return "notification_sends"
+}
+
+// Acknowledge marks a send (and transitively its escalations) as
+// acknowledged, stopping further escalation.
+func Acknowledge(ctx context.Context, sendID, by string) error {
+	result := ctx.DB().Model(&Send{}).
+		Where("(id = ? OR escalated_from = ?) AND acked_at IS NULL", sendID, sendID).
+		Updates(map[string]any{"acked_at": time.Now(), "acked_by": by})
+	if result.Error != nil {
+		return fmt.Errorf("failed to acknowledge %s: %w", sendID, result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return fmt.Errorf("notification send %s not found or already acknowledged", sendID)
+	}
+	return nil
+}
+
+// EscalationStep is one hop of an escalation chain.
+type EscalationStep struct {
+	// After is how long the previous recipient has to acknowledge.
+	After time.Duration `json:"after" yaml:"after"`
+
+	// Recipient is the team/channel notified at this step.
+	Recipient string `json:"recipient" yaml:"recipient"`
+}
+
+// EscalationPolicy escalates unacknowledged sends for matching events
+// through its steps in order.
+type EscalationPolicy struct {
+	// Events the policy applies to; all events when empty.
+	Events []string `json:"events,omitempty" yaml:"events,omitempty"`
+
+	Steps []EscalationStep `json:"steps" yaml:"steps"`
+}
+
+func (t EscalationPolicy) matches(event string) bool {
+	if len(t.Events) == 0 {
+		return true
+	}
+	return contains(t.Events, event)
+}
+
+// Sender delivers an escalated notification. It is provided by the host
+// service, which owns the actual delivery channels.
+type Sender func(ctx context.Context, send Send) error
+
+// Escalator walks unacknowledged sends through their escalation chains.
+// Run it on a schedule (e.g. every minute) from the host's job runner.
+type Escalator struct {
+	Policies []EscalationPolicy
+	Send     Sender
+}
+
+// Run performs one escalation pass: for every unacked send whose step
+// deadline has passed, it records and delivers the next step.
+func (t *Escalator) Run(ctx context.Context) error {
+	var pending []Send
+	if err := ctx.DB().
+		Where("acked_at IS NULL AND escalated_from IS NULL").
+		Find(&pending).Error; err != nil {
+		return fmt.Errorf("failed to list unacknowledged sends: %w", err)
+	}
+
+	for _, original := range pending {
+		policy, ok := t.policyFor(original.Event)
+		if !ok {
+			continue
+		}
+		if err := t.escalate(ctx, original, policy); err != nil {
+			ctx.Errorf("failed to escalate %s: %v", original.ID, err)
+		}
+	}
+	return nil
+}
+
+func (t *Escalator) policyFor(event string) (EscalationPolicy, bool) {
+	for _, policy := range t.Policies {
+		if policy.matches(event) {
+			return policy, true
+		}
+	}
+	return EscalationPolicy{}, false
+}
+
+func (t *Escalator) escalate(ctx context.Context, original Send, policy EscalationPolicy) error {
+	// find how far the chain has already progressed
+	var lastStep int
+	if err := ctx.DB().Model(&Send{}).
+		Where("escalated_from = ?", original.ID).
+		Select("COALESCE(MAX(escalation_step), 0)").
+		Scan(&lastStep).Error; err != nil {
+		return err
+	}
+	if lastStep >= len(policy.Steps) {
+		return nil // chain exhausted
+	}
+
+	// the clock for the next step starts at the previous step's send
+	stepStart := original.CreatedAt
+	if lastStep > 0 {
+		var previous Send
+		if err := ctx.DB().
+			Where("escalated_from = ? AND escalation_step = ?", original.ID, lastStep).
+			First(&previous).Error; err != nil {
+			return err
+		}
+		stepStart = previous.CreatedAt
+	}
+
+	step := policy.Steps[lastStep]
+	if time.Since(stepStart) < step.After {
+		return nil // not due yet
+	}
+
+	escalated := Send{
+		Event:          original.Event,
+		ResourceID:     original.ResourceID,
+		Recipient:      step.Recipient,
+		Severity:       original.Severity,
+		Body:           original.Body,
+		CreatedAt:      time.Now(),
+		EscalatedFrom:  &original.ID,
+		EscalationStep: lastStep + 1,
+	}
+	if err := ctx.DB().Create(&escalated).Error; err != nil {
+		return err
+	}
+
+	if t.Send != nil {
+		if err := t.Send(ctx, escalated); err != nil {
+			return fmt.Errorf("delivery failed: %w", err)
+		}
+	}
+	ctx.Infof("escalated %s (%s) to %s (step %d)", original.Event, original.ID, step.Recipient, lastStep+1)
+	return nil
+}
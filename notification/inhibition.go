@@ -0,0 +1,175 @@
+// Package notification contains the delivery-side building blocks for
+// notifications: inhibition, escalation and scheduling policies that are
+// shared by downstream services.
+package notification
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/flanksource/commons-db/context"
+)
+
+// InhibitionRule suppresses notifications for an event when another
+// event recently fired for the same resource (or one of its ancestors),
+// preventing alert storms when a parent fails.
+//
+// Example: if config.unhealthy fired for a Kubernetes node, suppress
+// pod.crashlooping for every config whose ancestry includes that node
+// for 10 minutes.
+type InhibitionRule struct {
+	// Source is the event that triggers the inhibition.
+	Source string `json:"source" yaml:"source"`
+
+	// Inhibited are the events suppressed while the rule is active.
+	Inhibited []string `json:"inhibited" yaml:"inhibited"`
+
+	// Window is how long the inhibition lasts after the source event.
+	Window time.Duration `json:"window" yaml:"window"`
+
+	// Direction controls which resources are covered:
+	//   same     - only the resource the source event fired for
+	//   children - the resource and its descendants (default)
+	Direction InhibitionDirection `json:"direction,omitempty" yaml:"direction,omitempty"`
+
+	// Depth bounds ancestry traversal for the children direction
+	// (default 5).
+	Depth int `json:"depth,omitempty" yaml:"depth,omitempty"`
+}
+
+type InhibitionDirection string
+
+const (
+	InhibitionSame     InhibitionDirection = "same"
+	InhibitionChildren InhibitionDirection = "children"
+)
+
+// firedEvent is a recorded source-event occurrence.
+type firedEvent struct {
+	resourceID string
+	expiresAt  time.Time
+}
+
+// Inhibitor evaluates inhibition rules. Source events are recorded via
+// Record; Suppressed is consulted before a send.
+type Inhibitor struct {
+	rules []InhibitionRule
+
+	mu    sync.Mutex
+	fired map[string][]firedEvent // source event -> occurrences
+}
+
+func NewInhibitor(rules []InhibitionRule) *Inhibitor {
+	return &Inhibitor{
+		rules: rules,
+		fired: make(map[string][]firedEvent),
+	}
+}
+
+// Record notes that an event fired for a resource. Only events that are
+// a source in some rule are retained.
+func (t *Inhibitor) Record(event, resourceID string) {
+	var window time.Duration
+	for _, rule := range t.rules {
+		if rule.Source == event && rule.Window > window {
+			window = rule.Window
+		}
+	}
+	if window == 0 {
+		return
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.fired[event] = append(t.pruneLocked(event), firedEvent{
+		resourceID: resourceID,
+		expiresAt:  time.Now().Add(window),
+	})
+}
+
+// Suppressed reports whether the event for the given resource is
+// currently inhibited, and by which rule.
+func (t *Inhibitor) Suppressed(ctx context.Context, event, resourceID string) (bool, *InhibitionRule, error) {
+	for i, rule := range t.rules {
+		if !contains(rule.Inhibited, event) {
+			continue
+		}
+
+		t.mu.Lock()
+		occurrences := t.pruneLocked(rule.Source)
+		t.fired[rule.Source] = occurrences
+		t.mu.Unlock()
+
+		if len(occurrences) == 0 {
+			continue
+		}
+
+		// resources the event's resource is covered by: itself, plus
+		// its ancestors for the children direction
+		covered := []string{resourceID}
+		if rule.Direction != InhibitionSame {
+			ancestors, err := resourceAncestors(ctx, resourceID, rule.depth())
+			if err != nil {
+				return false, nil, err
+			}
+			covered = append(covered, ancestors...)
+		}
+
+		for _, occurrence := range occurrences {
+			if contains(covered, occurrence.resourceID) {
+				return true, &t.rules[i], nil
+			}
+		}
+	}
+	return false, nil, nil
+}
+
+func (t InhibitionRule) depth() int {
+	if t.Depth <= 0 {
+		return 5
+	}
+	return t.Depth
+}
+
+// pruneLocked drops expired occurrences; callers hold t.mu.
+func (t *Inhibitor) pruneLocked(event string) []firedEvent {
+	now := time.Now()
+	kept := t.fired[event][:0]
+	for _, occurrence := range t.fired[event] {
+		if occurrence.expiresAt.After(now) {
+			kept = append(kept, occurrence)
+		}
+	}
+	return kept
+}
+
+// resourceAncestors walks the config_items parent chain up to depth
+// levels using a recursive CTE.
+func resourceAncestors(ctx context.Context, resourceID string, depth int) ([]string, error) {
+	var ancestors []string
+	err := ctx.DB().Raw(`
+		WITH RECURSIVE ancestors AS (
+			SELECT id, parent_id, 0 AS depth FROM config_items WHERE id = ?
+			UNION ALL
+			SELECT c.id, c.parent_id, a.depth + 1
+			FROM config_items c
+			JOIN ancestors a ON c.id = a.parent_id
+			WHERE a.depth < ?
+		)
+		SELECT id FROM ancestors WHERE depth > 0`, resourceID, depth).
+		Scan(&ancestors).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve ancestors of %s: %w", resourceID, err)
+	}
+	return ancestors, nil
+}
+
+func contains(items []string, item string) bool {
+	for _, i := range items {
+		if i == item {
+			return true
+		}
+	}
+	return false
+}
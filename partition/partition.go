@@ -0,0 +1,122 @@
+// Package partition creates and drops monthly partitions for the
+// high-churn time-series tables, keeping the number of live partitions
+// in line with retention so old data is removed by dropping a partition
+// instead of a slow bulk DELETE.
+package partition
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/flanksource/commons-db/context"
+)
+
+// PartitionedTable describes one table managed by this package.
+type PartitionedTable struct {
+	// Table is the parent (partitioned) table name.
+	Table string
+
+	// TimeColumn is the range partition key.
+	TimeColumn string
+
+	// DefaultRetentionMonths applies when the
+	// partition.retention.<table> property is unset.
+	DefaultRetentionMonths int
+}
+
+// Tables are the time-series tables managed by default.
+var Tables = []PartitionedTable{
+	{Table: "check_statuses", TimeColumn: "time", DefaultRetentionMonths: 3},
+	{Table: "config_changes", TimeColumn: "created_at", DefaultRetentionMonths: 6},
+	{Table: "job_history", TimeColumn: "created_at", DefaultRetentionMonths: 2},
+}
+
+// Run performs one maintenance pass over all managed tables: it ensures
+// partitions exist for the current and next month and drops partitions
+// older than the table's retention. Call it from RunMigrations or a
+// scheduled job; every operation is idempotent.
+func Run(ctx context.Context) error {
+	for _, table := range Tables {
+		if err := EnsurePartitions(ctx, table); err != nil {
+			return err
+		}
+		if err := DropExpired(ctx, table); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// EnsurePartitions creates the partitions for the current and next
+// month so inserts never race partition creation at month rollover.
+func EnsurePartitions(ctx context.Context, table PartitionedTable) error {
+	now := time.Now().UTC()
+	for _, month := range []time.Time{startOfMonth(now), startOfMonth(now).AddDate(0, 1, 0)} {
+		name := partitionName(table.Table, month)
+		statement := fmt.Sprintf(
+			"CREATE TABLE IF NOT EXISTS %s PARTITION OF %s FOR VALUES FROM ('%s') TO ('%s')",
+			name, table.Table,
+			month.Format("2006-01-02"), month.AddDate(0, 1, 0).Format("2006-01-02"))
+		if err := ctx.DB().Exec(statement).Error; err != nil {
+			return fmt.Errorf("failed to create partition %s: %w", name, err)
+		}
+	}
+	return nil
+}
+
+// DropExpired drops partitions wholly older than the table's retention,
+// which is read from the partition.retention.<table> property (in
+// months) on each pass so retention changes apply without a restart.
+func DropExpired(ctx context.Context, table PartitionedTable) error {
+	months := ctx.Properties().Int("partition.retention."+table.Table, table.DefaultRetentionMonths)
+	if months <= 0 {
+		return nil // retention disabled
+	}
+
+	cutoff := startOfMonth(time.Now().UTC()).AddDate(0, -months, 0)
+
+	var names []string
+	if err := ctx.DB().Raw(`
+		SELECT c.relname
+		FROM pg_inherits i
+		JOIN pg_class c ON c.oid = i.inhrelid
+		JOIN pg_class p ON p.oid = i.inhparent
+		WHERE p.relname = ?`, table.Table).Scan(&names).Error; err != nil {
+		return fmt.Errorf("failed to list partitions of %s: %w", table.Table, err)
+	}
+
+	for _, name := range names {
+		month, ok := partitionMonth(table.Table, name)
+		if !ok || !month.Before(cutoff) {
+			continue
+		}
+		ctx.Infof("dropping expired partition %s (retention %d months)", name, months)
+		if err := ctx.DB().Exec("DROP TABLE IF EXISTS " + name).Error; err != nil {
+			return fmt.Errorf("failed to drop partition %s: %w", name, err)
+		}
+	}
+	return nil
+}
+
+func startOfMonth(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, time.UTC)
+}
+
+// partitionName follows the <table>_yYYYYmMM convention, e.g.
+// check_statuses_y2026m08.
+func partitionName(table string, month time.Time) string {
+	return fmt.Sprintf("%s_y%dm%02d", table, month.Year(), int(month.Month()))
+}
+
+// partitionMonth parses the month back out of a partition name; false
+// for names that don't follow the convention (e.g. a manual partition).
+func partitionMonth(table, name string) (time.Time, bool) {
+	var year, month int
+	if _, err := fmt.Sscanf(name, table+"_y%dm%d", &year, &month); err != nil {
+		return time.Time{}, false
+	}
+	if month < 1 || month > 12 {
+		return time.Time{}, false
+	}
+	return time.Date(year, time.Month(month), 1, 0, 0, 0, 0, time.UTC), true
+}
@@ -0,0 +1,223 @@
+// Package notify manages Postgres LISTEN/NOTIFY subscriptions on a
+// dedicated connection, exposing notifications as Go channels so
+// consumers stop re-implementing connection handling and reconnects.
+package notify
+
+import (
+	"sync"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"github.com/flanksource/commons-db/context"
+)
+
+// subscriberBuffer is the per-subscriber channel capacity; a subscriber
+// that falls this far behind starts losing the oldest notifications
+// (NOTIFY is a wake-up signal, not a durable queue — consumers must
+// re-query on wake anyway).
+const subscriberBuffer = 64
+
+// Notification is a single NOTIFY event.
+type Notification struct {
+	Channel string
+	Payload string
+}
+
+// Manager owns one connection from the pool dedicated to LISTEN and
+// fans notifications out to subscribers. A lost connection is re-opened
+// with backoff and all channels are re-LISTENed, so subscribers survive
+// failovers (but may miss notifications sent while disconnected).
+type Manager struct {
+	// ctx is the manager's lifecycle: the listener runs until it is
+	// cancelled, independent of any one subscriber's context.
+	ctx  context.Context
+	pool *pgxpool.Pool
+
+	mu          sync.Mutex
+	subscribers map[string][]chan Notification
+	relisten    chan struct{}
+	started     bool
+}
+
+// NewManager creates a manager on the pool; the listening connection is
+// acquired lazily on the first Subscribe and lives until ctx is
+// cancelled.
+func NewManager(ctx context.Context, pool *pgxpool.Pool) *Manager {
+	return &Manager{
+		ctx:         ctx,
+		pool:        pool,
+		subscribers: map[string][]chan Notification{},
+		relisten:    make(chan struct{}, 1),
+	}
+}
+
+// Subscribe returns a channel receiving notifications for the Postgres
+// channel name. The subscription lasts until ctx is cancelled; the
+// returned channel is closed on cancellation.
+func (m *Manager) Subscribe(ctx context.Context, channel string) <-chan Notification {
+	sub := make(chan Notification, subscriberBuffer)
+
+	m.mu.Lock()
+	m.subscribers[channel] = append(m.subscribers[channel], sub)
+	if !m.started {
+		m.started = true
+		go m.run(m.ctx)
+	}
+	m.mu.Unlock()
+
+	// nudge the listener to LISTEN on the new channel
+	select {
+	case m.relisten <- struct{}{}:
+	default:
+	}
+
+	go func() {
+		<-ctx.Done()
+		m.unsubscribe(channel, sub)
+	}()
+	return sub
+}
+
+func (m *Manager) unsubscribe(channel string, sub chan Notification) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	subs := m.subscribers[channel]
+	for i, s := range subs {
+		if s == sub {
+			m.subscribers[channel] = append(subs[:i], subs[i+1:]...)
+			close(sub)
+			break
+		}
+	}
+	if len(m.subscribers[channel]) == 0 {
+		delete(m.subscribers, channel)
+	}
+}
+
+func (m *Manager) channels() []string {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	out := make([]string, 0, len(m.subscribers))
+	for channel := range m.subscribers {
+		out = append(out, channel)
+	}
+	return out
+}
+
+// run is the listener loop: one dedicated connection, re-acquired with
+// backoff on any failure.
+func (m *Manager) run(ctx context.Context) {
+	backoff := time.Second
+	for {
+		if ctx.Err() != nil {
+			return
+		}
+
+		if err := m.listen(ctx); err != nil && ctx.Err() == nil {
+			ctx.Warnf("pg listener disconnected, reconnecting in %s: %v", backoff, err)
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(backoff):
+			}
+			backoff = min(backoff*2, 30*time.Second)
+			continue
+		}
+		backoff = time.Second
+	}
+}
+
+// listen holds one connection: LISTENs on all subscribed channels, then
+// blocks on notifications, re-LISTENing when new subscriptions arrive.
+func (m *Manager) listen(ctx context.Context) error {
+	conn, err := m.pool.Acquire(ctx)
+	if err != nil {
+		return err
+	}
+	defer conn.Release()
+
+	listened := map[string]bool{}
+	ensureListens := func() error {
+		for _, channel := range m.channels() {
+			if listened[channel] {
+				continue
+			}
+			if _, err := conn.Exec(ctx, "LISTEN "+quoteIdentifier(channel)); err != nil {
+				return err
+			}
+			listened[channel] = true
+		}
+		return nil
+	}
+	if err := ensureListens(); err != nil {
+		return err
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-m.relisten:
+			if err := ensureListens(); err != nil {
+				return err
+			}
+		default:
+		}
+
+		// bounded wait so pending re-listens are picked up periodically
+		waitCtx, cancel := ctx.WithTimeout(30 * time.Second)
+		notification, err := conn.Conn().WaitForNotification(waitCtx)
+		cancel()
+		if err != nil {
+			if waitCtx.Err() != nil && ctx.Err() == nil {
+				continue // idle timeout, loop to re-check subscriptions
+			}
+			return err
+		}
+
+		m.dispatch(ctx, Notification{Channel: notification.Channel, Payload: notification.Payload})
+	}
+}
+
+func (m *Manager) dispatch(ctx context.Context, n Notification) {
+	// sends stay under the lock: unsubscribe closes channels under the
+	// same lock, so a subscriber cancelling mid-dispatch can't turn a
+	// send into a panic. Sends never block (see below), so the critical
+	// section stays short.
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for _, sub := range m.subscribers[n.Channel] {
+		select {
+		case sub <- n:
+		default:
+			// drop the oldest so slow consumers see the newest signal
+			select {
+			case <-sub:
+			default:
+			}
+			select {
+			case sub <- n:
+			default:
+			}
+			ctx.Debugf("pg listener: subscriber on %s is lagging, dropped a notification", n.Channel)
+		}
+	}
+}
+
+// quoteIdentifier quotes a channel name for LISTEN, which takes an
+// identifier rather than a bind parameter.
+func quoteIdentifier(name string) string {
+	out := make([]byte, 0, len(name)+2)
+	out = append(out, '"')
+	for i := 0; i < len(name); i++ {
+		if name[i] == '"' {
+			out = append(out, '"', '"')
+			continue
+		}
+		out = append(out, name[i])
+	}
+	return string(append(out, '"'))
+}
@@ -0,0 +1,91 @@
+package query
+
+import (
+	"fmt"
+
+	"gorm.io/gorm"
+
+	"github.com/flanksource/commons-db/context"
+)
+
+// Budget caps how much a single selector query may return, protecting
+// API servers from selectors like name=* over huge catalogs. Zero
+// fields are unlimited.
+type Budget struct {
+	MaxRows  int64
+	MaxBytes int64 // estimated serialized size, via pg_column_size
+}
+
+// budgetKey carries a per-caller Budget on the context.
+type budgetKey struct{}
+
+// WithBudget returns a context whose selector queries are capped by the
+// given budget, overriding the query.budget.max_rows /
+// query.budget.max_bytes properties for this caller.
+func WithBudget(ctx context.Context, budget Budget) context.Context {
+	return ctx.WithValue(budgetKey{}, budget)
+}
+
+// budgetFor returns the caller's budget, falling back to the
+// property-configured defaults (unlimited when unset).
+func budgetFor(ctx context.Context) Budget {
+	if budget, ok := ctx.Value(budgetKey{}).(Budget); ok {
+		return budget
+	}
+	return Budget{
+		MaxRows:  int64(ctx.Properties().Int("query.budget.max_rows", 0)),
+		MaxBytes: int64(ctx.Properties().Int("query.budget.max_bytes", 0)),
+	}
+}
+
+// ErrResultTooLarge is returned when a selector's result set exceeds
+// the caller's budget before any rows are fetched.
+type ErrResultTooLarge struct {
+	Table          string
+	Rows           int64
+	EstimatedBytes int64
+	Budget         Budget
+}
+
+func (e *ErrResultTooLarge) Error() string {
+	if e.Budget.MaxRows > 0 && e.Rows > e.Budget.MaxRows {
+		return fmt.Sprintf("selector on %s matches %d rows, over the budget of %d; narrow the selector or raise the budget",
+			e.Table, e.Rows, e.Budget.MaxRows)
+	}
+	return fmt.Sprintf("selector on %s matches ~%d bytes over %d rows, over the budget of %d bytes; narrow the selector or raise the budget",
+		e.Table, e.EstimatedBytes, e.Rows, e.Budget.MaxBytes)
+}
+
+// enforceBudget sizes the result set of q with a COUNT(*) (and a
+// pg_column_size sum when a bytes budget is set) and rejects it with
+// ErrResultTooLarge before the caller fetches any rows.
+func enforceBudget(ctx context.Context, q *gorm.DB, table string) error {
+	budget := budgetFor(ctx)
+	if budget.MaxRows <= 0 && budget.MaxBytes <= 0 {
+		return nil
+	}
+
+	sizing := "COUNT(*) AS row_count, 0 AS byte_count"
+	if budget.MaxBytes > 0 {
+		sizing = fmt.Sprintf("COUNT(*) AS row_count, COALESCE(SUM(pg_column_size(%s.*)), 0) AS byte_count", table)
+	}
+
+	var result struct {
+		RowCount  int64
+		ByteCount int64
+	}
+	if err := q.Session(&gorm.Session{}).Select(sizing).Scan(&result).Error; err != nil {
+		return fmt.Errorf("failed to size result set for %s: %w", table, err)
+	}
+
+	if (budget.MaxRows > 0 && result.RowCount > budget.MaxRows) ||
+		(budget.MaxBytes > 0 && result.ByteCount > budget.MaxBytes) {
+		return &ErrResultTooLarge{
+			Table:          table,
+			Rows:           result.RowCount,
+			EstimatedBytes: result.ByteCount,
+			Budget:         budget,
+		}
+	}
+	return nil
+}
@@ -0,0 +1,144 @@
+package query
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	"gorm.io/gorm"
+
+	"github.com/flanksource/commons-db/api"
+	"github.com/flanksource/commons-db/context"
+	"github.com/flanksource/commons-db/types"
+)
+
+// defaultChangesBatchSize bounds how many changes one call returns;
+// overridable via the query.changes.batch_size property.
+const defaultChangesBatchSize = 1000
+
+// defaultChangesLag is how far behind now() the feed trails. Row
+// timestamps are assigned before the writing transaction commits, so
+// reading right up to now() can miss rows that commit a moment later
+// with a slightly older timestamp. Overridable via the query.changes.lag
+// property.
+const defaultChangesLag = 2 * time.Second
+
+// ChangeCursor marks a position in a table's change feed: the change
+// timestamp and id of the last row consumed, compared as a tuple so rows
+// sharing a timestamp are not skipped.
+type ChangeCursor struct {
+	Time time.Time `json:"time"`
+	ID   string    `json:"id,omitempty"`
+}
+
+func (c ChangeCursor) IsZero() bool {
+	return c.Time.IsZero() && c.ID == ""
+}
+
+// String encodes the cursor as an opaque token for consumers to persist.
+func (c ChangeCursor) String() string {
+	data, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+// ParseChangeCursor decodes a token produced by ChangeCursor.String; an
+// empty token is the zero cursor (start of the feed).
+func ParseChangeCursor(token string) (ChangeCursor, error) {
+	if token == "" {
+		return ChangeCursor{}, nil
+	}
+	data, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil {
+		return ChangeCursor{}, api.Errorf(api.EINVALID, "invalid change cursor: %v", err)
+	}
+	var cursor ChangeCursor
+	if err := json.Unmarshal(data, &cursor); err != nil {
+		return ChangeCursor{}, api.Errorf(api.EINVALID, "invalid change cursor: %v", err)
+	}
+	return cursor, nil
+}
+
+// ChangeSet is one batch of a table's change feed.
+type ChangeSet struct {
+	Created []string `json:"created,omitempty"`
+	Updated []string `json:"updated,omitempty"`
+	Deleted []string `json:"deleted,omitempty"`
+
+	// Cursor resumes the feed after this batch.
+	Cursor ChangeCursor `json:"cursor"`
+
+	// More is set when the batch was cut off at the batch size and the
+	// caller should fetch again immediately.
+	More bool `json:"more,omitempty"`
+}
+
+// changeAtExpr is a row's change timestamp: its last update, or its
+// deletion when that came later. GREATEST ignores NULLs in postgres.
+const changeAtExpr = "GREATEST(COALESCE(updated_at, created_at), deleted_at)"
+
+type changeRow struct {
+	ID        string
+	CreatedAt time.Time
+	DeletedAt *time.Time
+	ChangeAt  time.Time
+}
+
+// Changes returns the ids created, updated and deleted since the cursor
+// for rows matching the selector, so consumers can keep derived stores
+// in sync without re-querying everything. Batches are ordered by change
+// time; feed the returned cursor into the next call. A zero cursor
+// starts from the beginning, reporting every live row as created and
+// omitting tombstones.
+func Changes(ctx context.Context, qm QueryModel, rs types.ResourceSelector, since ChangeCursor) (*ChangeSet, error) {
+	ctx, span := ctx.StartSpan("query.changes", attribute.String("table", qm.Table))
+	defer span.End()
+
+	if rs.IsEmpty() {
+		return nil, api.Errorf(api.EINVALID, "empty resource selector")
+	}
+
+	batchSize := ctx.Properties().Int("query.changes.batch_size", defaultChangesBatchSize)
+	lag := ctx.Properties().Duration("query.changes.lag", defaultChangesLag)
+
+	var rows []changeRow
+	err := withQueryClass(ctx, qm.Class, func(tx *gorm.DB) error {
+		q, err := applySelectorFilters(ctx, tx.Table(qm.Table), qm, rs)
+		if err != nil {
+			return err
+		}
+
+		q = q.Select("id, created_at, deleted_at, "+changeAtExpr+" AS change_at").
+			Where(changeAtExpr+" <= NOW() - (? * interval '1 second')", lag.Seconds()).
+			Order("change_at, id").
+			Limit(batchSize)
+		if !since.IsZero() {
+			q = q.Where("("+changeAtExpr+", id) > (?, ?)", since.Time, since.ID)
+		}
+
+		if err := q.Scan(&rows).Error; err != nil {
+			return fmt.Errorf("error querying %s changes: %w", qm.Table, err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	set := &ChangeSet{Cursor: since, More: len(rows) == batchSize}
+	for _, row := range rows {
+		switch {
+		case row.DeletedAt != nil:
+			if !since.IsZero() {
+				set.Deleted = append(set.Deleted, row.ID)
+			}
+		case since.IsZero() || row.CreatedAt.After(since.Time):
+			set.Created = append(set.Created, row.ID)
+		default:
+			set.Updated = append(set.Updated, row.ID)
+		}
+		set.Cursor = ChangeCursor{Time: row.ChangeAt, ID: row.ID}
+	}
+	return set, nil
+}
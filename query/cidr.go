@@ -0,0 +1,40 @@
+package query
+
+import (
+	"fmt"
+	"net"
+
+	"github.com/flanksource/commons-db/api"
+	"github.com/flanksource/commons-db/query/grammar"
+	"gorm.io/gorm"
+)
+
+// isCIDR reports whether the value is a network range (e.g. 10.0.0.0/8).
+// Plain IPs are not treated as CIDRs so that exact matches on text
+// columns keep working.
+func isCIDR(value string) bool {
+	_, _, err := net.ParseCIDR(value)
+	return err == nil
+}
+
+// applyCIDR compiles `field=<cidr>` into a Postgres inet containment
+// check. Text columns (and JSONB properties) are cast to inet; rows
+// whose value is not a valid address are excluded rather than erroring
+// the whole query.
+func applyCIDR(column string, columnType ColumnType, expr grammar.Expression, q *gorm.DB) (*gorm.DB, error) {
+	var inetExpr string
+	switch columnType {
+	case ColumnTypeInet:
+		inetExpr = column
+	case ColumnTypeText:
+		// the regex guard prevents cast errors on non-IP values
+		inetExpr = fmt.Sprintf("CASE WHEN %s ~ '^[0-9a-fA-F:.]+$' THEN %s::inet END", column, column)
+	default:
+		return nil, api.Errorf(api.EINVALID, "field %s does not support CIDR matching", expr.Field)
+	}
+
+	if expr.Op == grammar.OpNotEquals {
+		return q.Where(fmt.Sprintf("NOT (%s <<= ?::inet)", inetExpr), expr.Value), nil
+	}
+	return q.Where(fmt.Sprintf("%s <<= ?::inet", inetExpr), expr.Value), nil
+}
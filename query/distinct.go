@@ -0,0 +1,71 @@
+package query
+
+import (
+	"fmt"
+
+	"github.com/flanksource/commons-db/api"
+	"github.com/flanksource/commons-db/context"
+)
+
+// DistinctValues returns the distinct values of a column (or a key
+// inside a JSONB column, e.g. a tag) for building faceted filter
+// dropdowns. An optional prefix narrows the values (typeahead), and
+// limit caps the result (default 100, max 1000).
+func DistinctValues(ctx context.Context, qm QueryModel, field, prefix string, limit int) ([]string, error) {
+	if limit <= 0 {
+		limit = 100
+	}
+	if limit > 1000 {
+		limit = 1000
+	}
+
+	column, _, err := qm.resolveField(field)
+	if err != nil {
+		return nil, err
+	}
+
+	// For JSONB keys a lateral jsonb_each_text scan beats ->> per row
+	// on large tables because it can reuse a single expansion; for
+	// plain columns, DISTINCT on the column uses its index when one
+	// exists.
+	query := fmt.Sprintf(`
+		SELECT DISTINCT %s AS value FROM %s
+		WHERE deleted_at IS NULL AND %s IS NOT NULL`, column, qm.Table, column)
+
+	args := []any{}
+	if prefix != "" {
+		query += fmt.Sprintf(" AND %s LIKE ?", column)
+		args = append(args, prefix+"%")
+	}
+	query += fmt.Sprintf(" ORDER BY value LIMIT %d", limit)
+
+	var values []string
+	if err := ctx.ReadDB().Raw(query, args...).Scan(&values).Error; err != nil {
+		return nil, api.Errorf(api.EINTERNAL, "failed to query distinct values of %s.%s: %v", qm.Table, field, err)
+	}
+	return values, nil
+}
+
+// DistinctTagKeys returns the distinct keys present across a JSONB
+// column (e.g. every tag name in use), for building the key side of a
+// faceted filter.
+func DistinctTagKeys(ctx context.Context, qm QueryModel, limit int) ([]string, error) {
+	if len(qm.JSONColumns) == 0 {
+		return nil, api.Errorf(api.EINVALID, "%s has no JSONB columns", qm.Table)
+	}
+	if limit <= 0 {
+		limit = 100
+	}
+
+	query := fmt.Sprintf(`
+		SELECT DISTINCT kv.key FROM %s,
+		LATERAL jsonb_each_text(%s) AS kv
+		WHERE deleted_at IS NULL
+		ORDER BY kv.key LIMIT %d`, qm.Table, qm.JSONColumns[0], limit)
+
+	var keys []string
+	if err := ctx.ReadDB().Raw(query).Scan(&keys).Error; err != nil {
+		return nil, api.Errorf(api.EINTERNAL, "failed to query distinct keys of %s.%s: %v", qm.Table, qm.JSONColumns[0], err)
+	}
+	return keys, nil
+}
@@ -0,0 +1,159 @@
+package query
+
+import (
+	"fmt"
+	"strings"
+
+	"gorm.io/gorm"
+
+	"github.com/flanksource/commons-db/context"
+	"github.com/flanksource/commons-db/kubernetes"
+	"github.com/flanksource/commons-db/types"
+)
+
+// ResourceSource records where a federated query result came from.
+type ResourceSource string
+
+const (
+	SourceDatabase   ResourceSource = "database"
+	SourceKubernetes ResourceSource = "kubernetes"
+)
+
+// Resource is a selector query result with provenance.
+type Resource struct {
+	ID        string              `json:"id,omitempty"`
+	Name      string              `json:"name"`
+	Namespace string              `json:"namespace,omitempty"`
+	Type      string              `json:"type"`
+	Status    string              `json:"status,omitempty"`
+	Health    string              `json:"health,omitempty"`
+	Tags      types.JSONStringMap `json:"tags,omitempty"`
+
+	// Source is database for catalog rows and kubernetes for live
+	// cluster results.
+	Source ResourceSource `json:"source"`
+}
+
+// FindResourcesOption configures FindResources.
+type FindResourcesOption func(*findResourcesOptions)
+
+type findResourcesOptions struct {
+	client        *kubernetes.Client
+	forceFallback bool
+}
+
+// WithKubernetesFallback enables falling back to live cluster queries
+// using the given client.
+func WithKubernetesFallback(client *kubernetes.Client) FindResourcesOption {
+	return func(o *findResourcesOptions) { o.client = client }
+}
+
+// WithLiveOnly skips the database and queries the cluster directly.
+func WithLiveOnly() FindResourcesOption {
+	return func(o *findResourcesOptions) { o.forceFallback = true }
+}
+
+// FindResources queries the catalog for resources matching the selector
+// and, when a Kubernetes client is configured, falls back to querying
+// the cluster directly if the database has no matches (or the selector
+// explicitly targets live scope). Results are deduplicated by
+// type/namespace/name with database rows taking precedence.
+func FindResources(ctx context.Context, selector types.ResourceSelector, opts ...FindResourcesOption) ([]Resource, error) {
+	var options findResourcesOptions
+	for _, opt := range opts {
+		opt(&options)
+	}
+
+	var resources []Resource
+	if !options.forceFallback {
+		var err error
+		if resources, err = findDBResources(ctx, selector); err != nil {
+			return nil, err
+		}
+	}
+
+	if options.client != nil && (options.forceFallback || len(resources) == 0) && isKubernetesSelector(selector) {
+		live, err := findLiveResources(ctx, options.client, selector)
+		if err != nil {
+			return nil, err
+		}
+		resources = mergeResources(resources, live)
+	}
+	return resources, nil
+}
+
+func findDBResources(ctx context.Context, selector types.ResourceSelector) ([]Resource, error) {
+	var resources []Resource
+	err := withQueryClass(ctx, ConfigQueryModel.Class, func(tx *gorm.DB) error {
+		q, err := queryResourceSelector(ctx, tx, ConfigQueryModel, selector)
+		if err != nil {
+			return err
+		}
+		return q.Select("id", "name", "namespace", "type", "status", "health", "tags").
+			Find(&resources).Error
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to query resources: %w", err)
+	}
+
+	for i := range resources {
+		resources[i].Source = SourceDatabase
+	}
+	return resources, nil
+}
+
+func findLiveResources(ctx context.Context, client *kubernetes.Client, selector types.ResourceSelector) ([]Resource, error) {
+	items, err := client.QueryResources(ctx, selector)
+	if err != nil {
+		return nil, fmt.Errorf("kubernetes fallback failed: %w", err)
+	}
+
+	resources := make([]Resource, 0, len(items))
+	for _, item := range items {
+		resources = append(resources, Resource{
+			ID:        string(item.GetUID()),
+			Name:      item.GetName(),
+			Namespace: item.GetNamespace(),
+			Type:      "Kubernetes::" + item.GetKind(),
+			Tags:      item.GetLabels(),
+			Source:    SourceKubernetes,
+		})
+	}
+	return resources, nil
+}
+
+// isKubernetesSelector reports whether every type in the selector is a
+// Kubernetes config type (or a bare kind).
+func isKubernetesSelector(selector types.ResourceSelector) bool {
+	if len(selector.Types) == 0 {
+		return false
+	}
+	for _, t := range selector.Types {
+		if strings.Contains(t, "::") && !strings.HasPrefix(t, "Kubernetes::") {
+			return false
+		}
+	}
+	return true
+}
+
+// mergeResources combines database and live results, dropping live rows
+// that duplicate a database row.
+func mergeResources(db, live []Resource) []Resource {
+	seen := make(map[string]struct{}, len(db))
+	for _, r := range db {
+		seen[resourceKey(r)] = struct{}{}
+	}
+
+	merged := db
+	for _, r := range live {
+		if _, dup := seen[resourceKey(r)]; dup {
+			continue
+		}
+		merged = append(merged, r)
+	}
+	return merged
+}
+
+func resourceKey(r Resource) string {
+	return fmt.Sprintf("%s/%s/%s", r.Type, r.Namespace, r.Name)
+}
@@ -0,0 +1,120 @@
+package grammar
+
+import (
+	"fmt"
+	"strings"
+)
+
+// ParseError is a parse failure annotated with the position in the
+// query, so callers can render a caret snippet pointing at the exact
+// problem instead of echoing the whole query back.
+type ParseError struct {
+	Query   string
+	Offset  int // byte offset into Query
+	Message string
+
+	// Hint is an optional "did you mean" suggestion.
+	Hint string
+}
+
+// Position returns the 1-based line and column of the error.
+func (e *ParseError) Position() (line, column int) {
+	line, column = 1, 1
+	for i, r := range e.Query {
+		if i >= e.Offset {
+			break
+		}
+		if r == '\n' {
+			line++
+			column = 1
+		} else {
+			column++
+		}
+	}
+	return line, column
+}
+
+// Snippet renders the offending line with a caret under the error
+// position.
+func (e *ParseError) Snippet() string {
+	start := strings.LastIndexByte(e.Query[:min(e.Offset, len(e.Query))], '\n') + 1
+	end := len(e.Query)
+	if i := strings.IndexByte(e.Query[start:], '\n'); i >= 0 {
+		end = start + i
+	}
+	return e.Query[start:end] + "\n" + strings.Repeat(" ", e.Offset-start) + "^"
+}
+
+func (e *ParseError) Error() string {
+	line, column := e.Position()
+	msg := fmt.Sprintf("line %d column %d: %s", line, column, e.Message)
+	if e.Hint != "" {
+		msg += fmt.Sprintf(" (did you mean %s?)", e.Hint)
+	}
+	return msg + "\n" + e.Snippet()
+}
+
+// quoteHint quotes a suggested token for display, passing empty
+// suggestions through.
+func quoteHint(s string) string {
+	if s == "" {
+		return ""
+	}
+	return fmt.Sprintf("%q", s)
+}
+
+// suggestOperator maps common operator typos onto the operator the user
+// probably meant; returns "" when there is no good guess.
+func suggestOperator(s string) string {
+	switch s {
+	case ":":
+		return OpEquals
+	case "==":
+		return OpEquals
+	case "<>", "=!":
+		return OpNotEquals
+	case "=>":
+		return OpGte
+	case "=<":
+		return OpLte
+	case "~", "=~":
+		return OpFuzzy
+	}
+	return ""
+}
+
+// ClosestMatch returns the candidate within Levenshtein distance 2 of s
+// (ties broken by candidate order), or "" when nothing is close. Used
+// for "did you mean" suggestions on unknown field names.
+func ClosestMatch(s string, candidates []string) string {
+	best, bestDistance := "", 3
+	for _, candidate := range candidates {
+		if d := levenshtein(s, candidate); d < bestDistance {
+			best, bestDistance = candidate, d
+		}
+	}
+	return best
+}
+
+func levenshtein(a, b string) int {
+	if a == b {
+		return 0
+	}
+	prev := make([]int, len(b)+1)
+	current := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		current[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			current[j] = min(prev[j]+1, current[j-1]+1, prev[j-1]+cost)
+		}
+		prev, current = current, prev
+	}
+	return prev[len(b)]
+}
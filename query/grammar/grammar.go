@@ -0,0 +1,178 @@
+// Package grammar implements the search query grammar used by resource
+// selectors, e.g.
+//
+//	type=Kubernetes::Pod namespace!=kube-system name=api-* created_at>now-1h
+//
+// A query is a whitespace-separated list of field expressions. Quoted
+// values may contain spaces. A bare word (no operator) is treated as a
+// name search.
+package grammar
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+)
+
+// Operators, in the order they are matched (longest first).
+const (
+	OpNotEquals = "!="
+	OpGte       = ">="
+	OpLte       = "<="
+	OpFuzzy     = "~=" // trigram similarity match
+	OpGt        = ">"
+	OpLt        = "<"
+	OpEquals    = "="
+)
+
+var operators = []string{OpNotEquals, OpGte, OpLte, OpFuzzy, OpGt, OpLt, OpEquals}
+
+// Expression is a single field comparison in a query.
+type Expression struct {
+	Field string
+	Op    string
+	Value string
+}
+
+func (e Expression) String() string {
+	return fmt.Sprintf("%s%s%s", e.Field, e.Op, quoteIfNeeded(e.Value))
+}
+
+// Parse parses a search query into its component expressions. Failures
+// are returned as *ParseError, carrying the position of the offending
+// token and — for recognizable typos — a suggested fix.
+func Parse(query string) ([]Expression, error) {
+	tokens, err := tokenize(query)
+	if err != nil {
+		return nil, err
+	}
+
+	var expressions []Expression
+	for _, token := range tokens {
+		expr, err := parseToken(query, token)
+		if err != nil {
+			return nil, err
+		}
+		expressions = append(expressions, expr)
+	}
+	return expressions, nil
+}
+
+func parseToken(query string, token token) (Expression, error) {
+	for _, op := range operators {
+		if i := strings.Index(token.text, op); i > 0 {
+			field := token.text[:i]
+			rest := token.text[i+len(op):]
+
+			// Operator typos like == => =< <> =~ match a shorter
+			// operator and leak the extra character into the value;
+			// catch them with a suggestion instead.
+			if rest != "" && strings.ContainsAny(rest[:1], "=<>~!") {
+				typo := op + rest[:1]
+				return Expression{}, &ParseError{
+					Query:   query,
+					Offset:  token.offset + i,
+					Message: fmt.Sprintf("%q is not an operator", typo),
+					Hint:    quoteHint(suggestOperator(typo)),
+				}
+			}
+
+			if !isValidField(field) {
+				// Typos like => and =< match the shorter second half
+				// first, folding the stray = into the field.
+				if typo := field[len(field)-1:] + op; suggestOperator(typo) != "" {
+					return Expression{}, &ParseError{
+						Query:   query,
+						Offset:  token.offset + i - 1,
+						Message: fmt.Sprintf("%q is not an operator", typo),
+						Hint:    quoteHint(suggestOperator(typo)),
+					}
+				}
+				return Expression{}, &ParseError{
+					Query:   query,
+					Offset:  token.offset,
+					Message: fmt.Sprintf("invalid field %q", field),
+				}
+			}
+			return Expression{Field: field, Op: op, Value: strings.Trim(rest, `"`)}, nil
+		}
+	}
+
+	// A colon where an operator belongs is the most common typo coming
+	// from label-selector syntax; catch it before it silently turns
+	// into a name match. Quoting the term keeps the old behavior.
+	if i := strings.IndexAny(token.text, ":~"); i > 0 && !strings.HasPrefix(token.text, `"`) {
+		typo := string(token.text[i])
+		return Expression{}, &ParseError{
+			Query:   query,
+			Offset:  token.offset + i,
+			Message: fmt.Sprintf("%q is not an operator", typo),
+			Hint:    quoteHint(suggestOperator(typo)),
+		}
+	}
+
+	// A bare term is shorthand for a name match.
+	return Expression{Field: "name", Op: OpEquals, Value: strings.Trim(token.text, `"`)}, nil
+}
+
+// token is a raw query token and its byte offset in the query, kept for
+// error positions.
+type token struct {
+	text   string
+	offset int
+}
+
+// tokenize splits a query on whitespace, honoring double quotes.
+func tokenize(query string) ([]token, error) {
+	var tokens []token
+	var current strings.Builder
+	start, quoteStart := 0, 0
+	inQuotes := false
+
+	for i, r := range query {
+		switch {
+		case r == '"':
+			if !inQuotes {
+				quoteStart = i
+			}
+			inQuotes = !inQuotes
+			if current.Len() == 0 {
+				start = i
+			}
+			current.WriteRune(r)
+		case unicode.IsSpace(r) && !inQuotes:
+			if current.Len() > 0 {
+				tokens = append(tokens, token{text: current.String(), offset: start})
+				current.Reset()
+			}
+		default:
+			if current.Len() == 0 {
+				start = i
+			}
+			current.WriteRune(r)
+		}
+	}
+	if inQuotes {
+		return nil, &ParseError{Query: query, Offset: quoteStart, Message: "unterminated quote"}
+	}
+	if current.Len() > 0 {
+		tokens = append(tokens, token{text: current.String(), offset: start})
+	}
+	return tokens, nil
+}
+
+func isValidField(field string) bool {
+	for _, r := range field {
+		if !unicode.IsLetter(r) && !unicode.IsDigit(r) && r != '_' && r != '.' && r != '-' {
+			return false
+		}
+	}
+	return field != ""
+}
+
+func quoteIfNeeded(v string) string {
+	if strings.ContainsAny(v, " \t") {
+		return `"` + v + `"`
+	}
+	return v
+}
@@ -0,0 +1,53 @@
+package grammar
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestParseErrorPositions(t *testing.T) {
+	tests := []struct {
+		query  string
+		column int
+		hint   string
+	}{
+		{`name==api`, 5, `"="`},
+		{`count=>5`, 6, `">="`},
+		{`status:healthy`, 7, `"="`},
+		{`name="api`, 6, ""},
+	}
+
+	for _, test := range tests {
+		_, err := Parse(test.query)
+		if err == nil {
+			t.Errorf("Parse(%q) expected error", test.query)
+			continue
+		}
+
+		var parseErr *ParseError
+		if !errors.As(err, &parseErr) {
+			t.Errorf("Parse(%q) returned %T, want *ParseError", test.query, err)
+			continue
+		}
+		if _, column := parseErr.Position(); column != test.column {
+			t.Errorf("Parse(%q) error at column %d, want %d", test.query, column, test.column)
+		}
+		if test.hint != "" && !strings.Contains(parseErr.Error(), test.hint) {
+			t.Errorf("Parse(%q) error %q missing hint %s", test.query, parseErr.Error(), test.hint)
+		}
+		if !strings.Contains(parseErr.Error(), "^") {
+			t.Errorf("Parse(%q) error %q missing caret snippet", test.query, parseErr.Error())
+		}
+	}
+}
+
+func TestClosestMatch(t *testing.T) {
+	fields := []string{"name", "namespace", "created_at"}
+	if got := ClosestMatch("namespac", fields); got != "namespace" {
+		t.Errorf("ClosestMatch(namespac) = %q, want namespace", got)
+	}
+	if got := ClosestMatch("zzz", fields); got != "" {
+		t.Errorf("ClosestMatch(zzz) = %q, want no match", got)
+	}
+}
@@ -0,0 +1,93 @@
+package query
+
+import (
+	"sort"
+	"strings"
+
+	"go.opentelemetry.io/otel/attribute"
+	"gorm.io/gorm"
+
+	"github.com/flanksource/commons-db/api"
+	"github.com/flanksource/commons-db/context"
+	"github.com/flanksource/commons-db/types"
+)
+
+// QueryResourceSelectorsInto hydrates rows matching the selectors
+// directly into T (a struct with gorm column tags), replacing the
+// two-step IDs-then-GetByIDs pattern for simple reads.
+//
+// columns restricts the SELECT list; empty means all model columns.
+// Requested columns are validated against the query model so a typo
+// surfaces as an EINVALID error instead of SQL noise.
+func QueryResourceSelectorsInto[T any](ctx context.Context, qm QueryModel, columns []string, selectors ...types.ResourceSelector) ([]T, error) {
+	selected, err := qm.selectColumns(columns)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, span := ctx.StartSpan("query.hydrate",
+		attribute.String("table", qm.Table),
+		attribute.Int("selectors", len(selectors)))
+	defer span.End()
+
+	var results []T
+	err = withQueryClass(ctx, qm.Class, func(tx *gorm.DB) error {
+		for _, rs := range selectors {
+			q, err := queryResourceSelector(ctx, tx, qm, rs)
+			if err != nil {
+				return err
+			}
+
+			var batch []T
+			if err := q.Select(selected).Find(&batch).Error; err != nil {
+				return err
+			}
+			results = append(results, batch...)
+		}
+		return nil
+	})
+	return results, err
+}
+
+// selectColumns validates the requested columns against the model and
+// returns the database column names to select.
+func (qm QueryModel) selectColumns(columns []string) ([]string, error) {
+	if len(columns) == 0 {
+		selected := make([]string, 0, len(qm.Columns)+len(qm.JSONColumns))
+		for _, field := range qm.Columns {
+			selected = append(selected, field.Column)
+		}
+		selected = append(selected, qm.JSONColumns...)
+		return selected, nil
+	}
+
+	known := make(map[string]string, len(qm.Columns))
+	for name, field := range qm.Columns {
+		known[name] = field.Column
+		known[field.Column] = field.Column
+	}
+	for _, col := range qm.JSONColumns {
+		known[col] = col
+	}
+
+	selected := make([]string, 0, len(columns))
+	for _, col := range columns {
+		dbColumn, ok := known[col]
+		if !ok {
+			return nil, api.Errorf(api.EINVALID, "unknown column %q for table %s (known: %s)",
+				col, qm.Table, strings.Join(qm.columnNames(), ", "))
+		}
+		selected = append(selected, dbColumn)
+	}
+	return selected, nil
+}
+
+func (qm QueryModel) columnNames() []string {
+	names := make([]string, 0, len(qm.Columns)+len(qm.JSONColumns))
+	for name := range qm.Columns {
+		names = append(names, name)
+	}
+	names = append(names, qm.JSONColumns...)
+	sort.Strings(names)
+	return names
+}
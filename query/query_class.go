@@ -0,0 +1,67 @@
+package query
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/flanksource/commons-db/context"
+	"gorm.io/gorm"
+)
+
+// QueryClass classifies the traffic a query belongs to so that resource
+// limits can be applied per class: exports get generous timeouts and
+// work_mem without being allowed to starve interactive traffic, and
+// vice versa.
+type QueryClass string
+
+const (
+	QueryClassInteractive QueryClass = "interactive"
+	QueryClassBackground  QueryClass = "background"
+	QueryClassExport      QueryClass = "export"
+)
+
+// Per-class defaults, overridable via properties
+// (query.timeout.<class>, query.work_mem.<class>).
+var queryClassDefaults = map[QueryClass]struct {
+	timeout time.Duration
+	workMem string
+}{
+	QueryClassInteractive: {timeout: 15 * time.Second, workMem: "16MB"},
+	QueryClassBackground:  {timeout: 2 * time.Minute, workMem: "64MB"},
+	QueryClassExport:      {timeout: 10 * time.Minute, workMem: "256MB"},
+}
+
+func (qc QueryClass) timeout(ctx context.Context) time.Duration {
+	defaults := queryClassDefaults[qc.orDefault()]
+	return ctx.Properties().Duration(fmt.Sprintf("query.timeout.%s", qc.orDefault()), defaults.timeout)
+}
+
+func (qc QueryClass) workMem(ctx context.Context) string {
+	defaults := queryClassDefaults[qc.orDefault()]
+	return ctx.Properties().String(fmt.Sprintf("query.work_mem.%s", qc.orDefault()), defaults.workMem)
+}
+
+func (qc QueryClass) orDefault() QueryClass {
+	if _, ok := queryClassDefaults[qc]; !ok {
+		return QueryClassInteractive
+	}
+	return qc
+}
+
+// withQueryClass runs fn inside a transaction with the class's
+// statement_timeout and work_mem applied via SET LOCAL, so the settings
+// are scoped to this query and reset on commit/rollback. Queries run on
+// the read replica when one is configured.
+func withQueryClass(ctx context.Context, class QueryClass, fn func(tx *gorm.DB) error) error {
+	return ctx.ReadDB().Transaction(func(tx *gorm.DB) error {
+		timeout := class.timeout(ctx)
+		if err := tx.Exec(fmt.Sprintf("SET LOCAL statement_timeout = %d", timeout.Milliseconds())).Error; err != nil {
+			return fmt.Errorf("failed to set statement_timeout: %w", err)
+		}
+		// SET does not accept bind parameters; set_config does
+		if err := tx.Exec("SELECT set_config('work_mem', ?, true)", class.workMem(ctx)).Error; err != nil {
+			return fmt.Errorf("failed to set work_mem: %w", err)
+		}
+		return fn(tx)
+	})
+}
@@ -0,0 +1,160 @@
+package query
+
+import (
+	"fmt"
+
+	"go.opentelemetry.io/otel/attribute"
+
+	"github.com/flanksource/commons-db/api"
+	"github.com/flanksource/commons-db/context"
+	"github.com/flanksource/commons-db/types"
+	"gorm.io/gorm"
+)
+
+// ConfigQueryModel is the query model for the config_items table.
+var ConfigQueryModel = QueryModel{
+	Table: "config_items",
+	Columns: map[string]QueryField{
+		"id":         {Column: "id"},
+		"name":       {Column: "name"},
+		"namespace":  {Column: "namespace"},
+		"type":       {Column: "type"},
+		"status":     {Column: "status"},
+		"health":     {Column: "health"},
+		"agent":      {Column: "agent_id"},
+		"created_at": {Column: "created_at", Type: ColumnTypeDateTime},
+		"updated_at": {Column: "updated_at", Type: ColumnTypeDateTime},
+	},
+	JSONColumns: []string{"tags"},
+}
+
+// queryResourceSelector compiles a ResourceSelector into a gorm query
+// against the model's table, built on the given tx so that class-level
+// session settings apply. The caller's result budget (see WithBudget)
+// is enforced here, before any rows are fetched. Callers add
+// SELECT/LIMIT on the returned query.
+func queryResourceSelector(ctx context.Context, tx *gorm.DB, qm QueryModel, rs types.ResourceSelector) (*gorm.DB, error) {
+	if rs.IsEmpty() {
+		return nil, api.Errorf(api.EINVALID, "empty resource selector")
+	}
+
+	q, err := applySelectorFilters(ctx, tx.Table(qm.Table).Where("deleted_at IS NULL"), qm, rs)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := enforceBudget(ctx, q, qm.Table); err != nil {
+		return nil, err
+	}
+
+	return q, nil
+}
+
+// applySelectorFilters compiles the selector's filters onto q. It does
+// not add the deleted_at guard, so Changes can match deleted rows too.
+func applySelectorFilters(ctx context.Context, q *gorm.DB, qm QueryModel, rs types.ResourceSelector) (*gorm.DB, error) {
+	if rs.ID != "" {
+		q = q.Where("id = ?", rs.ID)
+	}
+	if rs.Name != "" {
+		q = applyWildcard(q, "name", rs.Name)
+	}
+	if rs.Namespace != "" {
+		q = applyWildcard(q, "namespace", rs.Namespace)
+	}
+	if len(rs.Types) > 0 {
+		q = q.Where("type IN ?", []string(rs.Types))
+	}
+	if len(rs.Statuses) > 0 {
+		q = q.Where("status IN ?", []string(rs.Statuses))
+	}
+	if rs.Health != "" {
+		q = q.Where("health = ?", rs.Health)
+	}
+
+	for _, selector := range []string{rs.TagSelector, rs.LabelSelector, rs.FieldSelector, rs.Search} {
+		if selector == "" {
+			continue
+		}
+		var err error
+		if q, err = qm.Apply(ctx, selector, q); err != nil {
+			return nil, err
+		}
+	}
+
+	return q, nil
+}
+
+func applyWildcard(q *gorm.DB, column, value string) *gorm.DB {
+	if containsWildcard(value) {
+		return q.Where(fmt.Sprintf("%s LIKE ?", column), wildcardToLike(value))
+	}
+	return q.Where(fmt.Sprintf("%s = ?", column), value)
+}
+
+func containsWildcard(value string) bool {
+	for i := 0; i < len(value); i++ {
+		if value[i] == '*' {
+			return true
+		}
+	}
+	return false
+}
+
+func wildcardToLike(value string) string {
+	out := make([]byte, 0, len(value))
+	for i := 0; i < len(value); i++ {
+		if value[i] == '*' {
+			out = append(out, '%')
+		} else {
+			out = append(out, value[i])
+		}
+	}
+	return string(out)
+}
+
+// FindIDsExcluding returns the ids matching any of the include
+// selectors minus those matching any exclude selector. The exclusion is
+// compiled into the SQL (`AND id NOT IN (...)`) so "all Deployments
+// except those matched by X" doesn't require filtering rows in
+// application code.
+func FindIDsExcluding(ctx context.Context, qm QueryModel, selectors, excludeSelectors []types.ResourceSelector) ([]string, error) {
+	ctx, span := ctx.StartSpan("query.resource-selector",
+		attribute.String("table", qm.Table),
+		attribute.Int("selectors", len(selectors)),
+		attribute.Int("exclude_selectors", len(excludeSelectors)))
+	defer span.End()
+
+	var ids []string
+	err := withQueryClass(ctx, qm.Class, func(tx *gorm.DB) error {
+		for _, rs := range selectors {
+			q, err := queryResourceSelector(ctx, tx, qm, rs)
+			if err != nil {
+				return err
+			}
+
+			for _, exclude := range excludeSelectors {
+				sub, err := queryResourceSelector(ctx, tx, qm, exclude)
+				if err != nil {
+					return err
+				}
+				q = q.Where("id NOT IN (?)", sub.Select("id"))
+			}
+
+			var batch []string
+			if err := q.Select("id").Find(&batch).Error; err != nil {
+				return fmt.Errorf("error querying %s with selector %s: %w", qm.Table, rs, err)
+			}
+			ids = append(ids, batch...)
+		}
+		return nil
+	})
+	return ids, err
+}
+
+// FindIDsByResourceSelector returns the ids of resources matching any of
+// the given selectors (selectors are ORed, fields within a selector are
+// ANDed).
+func FindIDsByResourceSelector(ctx context.Context, qm QueryModel, selectors ...types.ResourceSelector) ([]string, error) {
+	return FindIDsExcluding(ctx, qm, selectors, nil)
+}
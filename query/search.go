@@ -0,0 +1,202 @@
+package query
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/flanksource/commons-db/api"
+	"github.com/flanksource/commons-db/context"
+	"github.com/flanksource/commons-db/query/grammar"
+	"gorm.io/gorm"
+)
+
+// ColumnType describes how a queryable field maps onto SQL.
+type ColumnType int
+
+const (
+	ColumnTypeText ColumnType = iota
+	ColumnTypeNumber
+	ColumnTypeDateTime
+	ColumnTypeJSONB // a key inside a jsonb column (tags, labels, properties)
+	ColumnTypeInet  // an inet column, or text holding IP addresses
+)
+
+// QueryField describes a single queryable field of a model.
+type QueryField struct {
+	// Column is the SQL column, or for JSONB fields the column holding
+	// the object (e.g. "tags").
+	Column string
+	Type   ColumnType
+}
+
+// QueryModel describes the queryable surface of a table: which fields the
+// search grammar may reference and how they compile to SQL.
+type QueryModel struct {
+	Table string
+
+	// Class selects the statement timeout / work_mem profile applied
+	// to queries against this model (interactive when unset).
+	Class QueryClass
+
+	// Columns maps grammar field names to SQL columns.
+	Columns map[string]QueryField
+
+	// JSONColumns are jsonb columns whose keys may be referenced
+	// directly (field names not present in Columns fall through to
+	// these, in order).
+	JSONColumns []string
+}
+
+// defaultSimilarityThreshold is the minimum trigram similarity for
+// fuzzy (~=) matches, overridable via the query.similarity.threshold
+// property.
+const defaultSimilarityThreshold = 0.3
+
+// Apply compiles a search query and adds the resulting WHERE clauses to q.
+func (qm QueryModel) Apply(ctx context.Context, search string, q *gorm.DB) (*gorm.DB, error) {
+	// ParseError renders its own position-annotated snippet, so the
+	// query isn't repeated here.
+	expressions, err := grammar.Parse(search)
+	if err != nil {
+		return nil, api.Errorf(api.EINVALID, "invalid search: %v", err)
+	}
+
+	for _, expr := range expressions {
+		q, err = qm.applyExpression(ctx, expr, q)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return q, nil
+}
+
+func (qm QueryModel) applyExpression(ctx context.Context, expr grammar.Expression, q *gorm.DB) (*gorm.DB, error) {
+	column, columnType, err := qm.resolveField(expr.Field)
+	if err != nil {
+		return nil, err
+	}
+
+	// CIDR values compile to inet containment regardless of column
+	// type: `ip=10.0.0.0/8` matches any address in the range.
+	if isCIDR(expr.Value) && (expr.Op == grammar.OpEquals || expr.Op == grammar.OpNotEquals) {
+		return applyCIDR(column, columnType, expr, q)
+	}
+
+	switch expr.Op {
+	case grammar.OpEquals:
+		if strings.Contains(expr.Value, "*") {
+			return q.Where(fmt.Sprintf("%s LIKE ?", column), strings.ReplaceAll(expr.Value, "*", "%")), nil
+		}
+		return q.Where(fmt.Sprintf("%s = ?", column), expr.Value), nil
+
+	case grammar.OpNotEquals:
+		if strings.Contains(expr.Value, "*") {
+			return q.Where(fmt.Sprintf("%s NOT LIKE ?", column), strings.ReplaceAll(expr.Value, "*", "%")), nil
+		}
+		return q.Where(fmt.Sprintf("%s != ?", column), expr.Value), nil
+
+	case grammar.OpGt, grammar.OpGte, grammar.OpLt, grammar.OpLte:
+		if columnType != ColumnTypeNumber && columnType != ColumnTypeDateTime {
+			return nil, api.Errorf(api.EINVALID, "field %s does not support %s", expr.Field, expr.Op)
+		}
+		value := any(expr.Value)
+		if columnType == ColumnTypeDateTime {
+			var err error
+			if value, err = parseSearchTime(expr.Value); err != nil {
+				return nil, api.Errorf(api.EINVALID, "invalid time value %q for field %s: %v", expr.Value, expr.Field, err)
+			}
+		}
+		return q.Where(fmt.Sprintf("%s %s ?", column, expr.Op), value), nil
+
+	case grammar.OpFuzzy:
+		// Trigram similarity via pg_trgm. `%` uses the GIN trigram
+		// index when one exists; the explicit similarity() guard lets
+		// the threshold be tuned per deployment.
+		threshold := ctx.Properties().Float("query.similarity.threshold", defaultSimilarityThreshold)
+		return q.Where(fmt.Sprintf("%s %% ? AND similarity(%s, ?) >= ?", column, column), expr.Value, expr.Value, threshold), nil
+
+	default:
+		return nil, api.Errorf(api.EINVALID, "unsupported operator %s", expr.Op)
+	}
+}
+
+// parseSearchTime resolves a datetime comparison value. Now-relative
+// offsets like now-1h or now-7d resolve against the current time;
+// anything else passes through verbatim for Postgres to cast (e.g.
+// 2026-01-01 or a full RFC3339 timestamp).
+func parseSearchTime(value string) (any, error) {
+	rest, ok := strings.CutPrefix(value, "now")
+	if !ok {
+		return value, nil
+	}
+	if rest == "" {
+		return time.Now(), nil
+	}
+
+	sign := time.Duration(1)
+	switch rest[0] {
+	case '-':
+		sign = -1
+	case '+':
+	default:
+		return value, nil
+	}
+
+	duration, err := parseSearchDuration(rest[1:])
+	if err != nil {
+		return nil, err
+	}
+	return time.Now().Add(sign * duration), nil
+}
+
+// parseSearchDuration is time.ParseDuration plus the day and week units
+// search queries reach for far more often than Go code does.
+func parseSearchDuration(s string) (time.Duration, error) {
+	if d, err := time.ParseDuration(s); err == nil {
+		return d, nil
+	}
+
+	for suffix, unit := range map[string]time.Duration{"d": 24 * time.Hour, "w": 7 * 24 * time.Hour} {
+		if n, ok := strings.CutSuffix(s, suffix); ok {
+			if count, err := strconv.ParseFloat(n, 64); err == nil {
+				return time.Duration(count * float64(unit)), nil
+			}
+		}
+	}
+	return 0, fmt.Errorf("invalid duration %q", s)
+}
+
+// resolveField maps a grammar field name onto a SQL expression.
+func (qm QueryModel) resolveField(field string) (string, ColumnType, error) {
+	if qf, ok := qm.Columns[field]; ok {
+		if qf.Type == ColumnTypeJSONB {
+			return fmt.Sprintf("%s->>'%s'", qf.Column, field), ColumnTypeText, nil
+		}
+		return qf.Column, qf.Type, nil
+	}
+
+	if len(qm.JSONColumns) > 0 {
+		if len(qm.JSONColumns) == 1 {
+			return fmt.Sprintf("%s->>'%s'", qm.JSONColumns[0], field), ColumnTypeText, nil
+		}
+		// the first column containing the key wins
+		keys := make([]string, len(qm.JSONColumns))
+		for i, column := range qm.JSONColumns {
+			keys[i] = fmt.Sprintf("%s->>'%s'", column, field)
+		}
+		return "COALESCE(" + strings.Join(keys, ", ") + ")", ColumnTypeText, nil
+	}
+
+	known := make([]string, 0, len(qm.Columns))
+	for name := range qm.Columns {
+		known = append(known, name)
+	}
+	sort.Strings(known)
+	if suggestion := grammar.ClosestMatch(field, known); suggestion != "" {
+		return "", 0, api.Errorf(api.EINVALID, "unknown field %q for %s (did you mean %q?)", field, qm.Table, suggestion)
+	}
+	return "", 0, api.Errorf(api.EINVALID, "unknown field %q for %s", field, qm.Table)
+}
@@ -0,0 +1,79 @@
+package query
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseSearchTime(t *testing.T) {
+	t.Run("literals pass through", func(t *testing.T) {
+		for _, value := range []string{"2026-01-01", "2026-01-01T10:00:00Z", "nowhere"} {
+			got, err := parseSearchTime(value)
+			if err != nil {
+				t.Fatalf("parseSearchTime(%q): %v", value, err)
+			}
+			if got != value {
+				t.Errorf("parseSearchTime(%q) = %v, want the literal back", value, got)
+			}
+		}
+	})
+
+	t.Run("now-relative offsets", func(t *testing.T) {
+		tests := []struct {
+			value  string
+			offset time.Duration
+		}{
+			{"now", 0},
+			{"now-1h", -time.Hour},
+			{"now-30m", -30 * time.Minute},
+			{"now-7d", -7 * 24 * time.Hour},
+			{"now-2w", -14 * 24 * time.Hour},
+			{"now+1h", time.Hour},
+		}
+		for _, test := range tests {
+			got, err := parseSearchTime(test.value)
+			if err != nil {
+				t.Fatalf("parseSearchTime(%q): %v", test.value, err)
+			}
+			at, ok := got.(time.Time)
+			if !ok {
+				t.Fatalf("parseSearchTime(%q) = %T, want time.Time", test.value, got)
+			}
+			if drift := time.Until(at) - test.offset; drift < -time.Minute || drift > time.Minute {
+				t.Errorf("parseSearchTime(%q) is off by %s", test.value, drift)
+			}
+		}
+	})
+
+	t.Run("malformed offsets error", func(t *testing.T) {
+		for _, value := range []string{"now-", "now-1x", "now-h"} {
+			if _, err := parseSearchTime(value); err == nil {
+				t.Errorf("parseSearchTime(%q): expected error", value)
+			}
+		}
+	})
+}
+
+func TestResolveFieldJSONColumns(t *testing.T) {
+	qm := QueryModel{
+		Table:       "config_items",
+		Columns:     map[string]QueryField{"name": {Column: "name"}},
+		JSONColumns: []string{"tags", "labels"},
+	}
+
+	column, columnType, err := qm.resolveField("team")
+	if err != nil {
+		t.Fatalf("resolveField: %v", err)
+	}
+	if want := "COALESCE(tags->>'team', labels->>'team')"; column != want {
+		t.Errorf("resolveField = %q, want %q", column, want)
+	}
+	if columnType != ColumnTypeText {
+		t.Errorf("resolveField type = %v, want text", columnType)
+	}
+
+	qm.JSONColumns = []string{"tags"}
+	if column, _, _ := qm.resolveField("team"); column != "tags->>'team'" {
+		t.Errorf("single JSON column = %q, want tags->>'team'", column)
+	}
+}
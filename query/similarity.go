@@ -0,0 +1,36 @@
+package query
+
+import (
+	"fmt"
+
+	"github.com/flanksource/commons-db/context"
+	"github.com/flanksource/commons-db/types"
+)
+
+// SimilarityMatch is one nearest-neighbour result: the row id and its
+// cosine distance to the query embedding (0 = identical).
+type SimilarityMatch struct {
+	ID       string  `json:"id"`
+	Distance float64 `json:"distance"`
+}
+
+// SimilaritySearch returns the k rows of table whose embedding column is
+// nearest to the given embedding by cosine distance, using pgvector's
+// <=> operator so a vector index on the column is used when present.
+func SimilaritySearch(ctx context.Context, table, column string, embedding types.Vector, k int) ([]SimilarityMatch, error) {
+	if len(embedding) == 0 {
+		return nil, fmt.Errorf("empty embedding")
+	}
+	if k <= 0 {
+		k = 10
+	}
+
+	var matches []SimilarityMatch
+	query := fmt.Sprintf(
+		"SELECT id, %s <=> ?::vector AS distance FROM %s WHERE %s IS NOT NULL ORDER BY distance LIMIT ?",
+		column, table, column)
+	if err := ctx.ReadDB().Raw(query, embedding.String(), k).Scan(&matches).Error; err != nil {
+		return nil, fmt.Errorf("similarity search on %s.%s failed (is the vector extension installed?): %w", table, column, err)
+	}
+	return matches, nil
+}
@@ -0,0 +1,56 @@
+package query
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/flanksource/commons-db/context"
+	"github.com/flanksource/commons-db/types"
+)
+
+// StatusTransition is one health or status flip of a config item.
+type StatusTransition struct {
+	ConfigID   string    `json:"config_id"`
+	ConfigName string    `json:"config_name"`
+	ConfigType string    `json:"config_type"`
+	ChangeType string    `json:"change_type"` // HealthChanged or StatusChanged
+	From       string    `json:"from"`
+	To         string    `json:"to"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+// StatusTransitions returns the health/status transition events recorded
+// for configs matching the selector within the trailing window, newest
+// first — the query behind "what went unhealthy in the last hour" views.
+func StatusTransitions(ctx context.Context, selector types.ResourceSelector, window time.Duration) ([]StatusTransition, error) {
+	ids, err := FindIDsByResourceSelector(ctx, ConfigQueryModel, selector)
+	if err != nil {
+		return nil, err
+	}
+	if len(ids) == 0 {
+		return nil, nil
+	}
+
+	var transitions []StatusTransition
+	err = ctx.ReadDB().Raw(`
+		SELECT
+			cc.config_id,
+			ci.name AS config_name,
+			ci.type AS config_type,
+			cc.change_type,
+			cc.details->>'from' AS "from",
+			cc.details->>'to' AS "to",
+			cc.created_at
+		FROM config_changes cc
+		JOIN config_items ci ON ci.id = cc.config_id
+		WHERE cc.config_id IN ?
+		  AND cc.change_type IN ('HealthChanged', 'StatusChanged')
+		  AND cc.created_at >= NOW() - ?::interval
+		ORDER BY cc.created_at DESC`,
+		ids, fmt.Sprintf("%d seconds", int(window.Seconds()))).
+		Scan(&transitions).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to query status transitions: %w", err)
+	}
+	return transitions, nil
+}
@@ -0,0 +1,11 @@
+package types
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+)
+
+func hash(v string) string {
+	h := sha256.Sum256([]byte(v))
+	return hex.EncodeToString(h[:])[0:16]
+}
@@ -0,0 +1,136 @@
+package types
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"sync"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/schema"
+)
+
+// ComponentCheck attaches health checks to a component, either by
+// selecting existing checks or by defining them inline.
+type ComponentCheck struct {
+	Selector ResourceSelector `json:"selector,omitempty" yaml:"selector,omitempty"`
+
+	// Inline is a raw check spec, keyed by check type:
+	//   {"http": [{"url": "..."}], "tcp": [{"endpoint": "..."}]}
+	Inline JSON `json:"inline,omitempty" yaml:"inline,omitempty"`
+}
+
+// CheckValidator validates the inline spec of a single check type.
+// Validators are registered by the packages that own each check type so
+// malformed inline checks are rejected at persist time instead of at
+// execution.
+type CheckValidator func(spec json.RawMessage) error
+
+var (
+	checkValidatorsMu sync.RWMutex
+	checkValidators   = map[string]CheckValidator{}
+)
+
+// RegisterCheckValidator installs a validator for a check type (e.g.
+// "http"). Registering again replaces the previous validator.
+func RegisterCheckValidator(checkType string, validator CheckValidator) {
+	checkValidatorsMu.Lock()
+	defer checkValidatorsMu.Unlock()
+	checkValidators[checkType] = validator
+}
+
+// Validate checks the inline spec against the registered validators.
+// Check types without a registered validator only need to be valid JSON.
+func (c ComponentCheck) Validate() error {
+	if len(c.Inline) == 0 {
+		return nil
+	}
+
+	var inline map[string]json.RawMessage
+	if err := json.Unmarshal(c.Inline, &inline); err != nil {
+		return fmt.Errorf("inline check is not a JSON object: %w", err)
+	}
+
+	checkValidatorsMu.RLock()
+	defer checkValidatorsMu.RUnlock()
+	for checkType, spec := range inline {
+		validator, ok := checkValidators[checkType]
+		if !ok {
+			continue
+		}
+		if err := validator(spec); err != nil {
+			return fmt.Errorf("inline %s check is invalid: %w", checkType, err)
+		}
+	}
+	return nil
+}
+
+func (c *ComponentCheck) UnmarshalJSON(data []byte) error {
+	type alias ComponentCheck
+	var out alias
+	if err := json.Unmarshal(data, &out); err != nil {
+		return err
+	}
+	*c = ComponentCheck(out)
+	return c.Validate()
+}
+
+func (c ComponentCheck) Value() (driver.Value, error) {
+	return json.Marshal(c)
+}
+
+func (c *ComponentCheck) Scan(value any) error {
+	var b []byte
+	switch v := value.(type) {
+	case nil:
+		*c = ComponentCheck{}
+		return nil
+	case []byte:
+		b = v
+	case string:
+		b = []byte(v)
+	default:
+		return fmt.Errorf("failed to scan %T into ComponentCheck", value)
+	}
+	return json.Unmarshal(b, c) // UnmarshalJSON validates
+}
+
+// ComponentChecks is the jsonb column type holding a list of checks.
+type ComponentChecks []ComponentCheck
+
+func (c ComponentChecks) Value() (driver.Value, error) {
+	if c == nil {
+		return nil, nil
+	}
+	return json.Marshal(c)
+}
+
+func (c *ComponentChecks) Scan(value any) error {
+	var b []byte
+	switch v := value.(type) {
+	case nil:
+		*c = nil
+		return nil
+	case []byte:
+		b = v
+	case string:
+		b = []byte(v)
+	default:
+		return fmt.Errorf("failed to scan %T into ComponentChecks", value)
+	}
+	return json.Unmarshal(b, c)
+}
+
+func (ComponentChecks) GormDataType() string {
+	return "componentChecks"
+}
+
+func (ComponentChecks) GormDBDataType(db *gorm.DB, field *schema.Field) string {
+	switch db.Dialector.Name() {
+	case "postgres":
+		return "JSONB"
+	case "sqlite":
+		return "JSON"
+	}
+	return "TEXT"
+}
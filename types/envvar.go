@@ -0,0 +1,169 @@
+package types
+
+import (
+	"fmt"
+	"strings"
+)
+
+// EnvVar is a value that can be specified statically or sourced from a
+// Kubernetes ConfigMap/Secret, a ServiceAccount token, or a Helm
+// release value.
+type EnvVar struct {
+	Name        string        `json:"name,omitempty" yaml:"name,omitempty"`
+	ValueStatic string        `json:"value,omitempty" yaml:"value,omitempty"`
+	ValueFrom   *EnvVarSource `json:"valueFrom,omitempty" yaml:"valueFrom,omitempty"`
+}
+
+func (e EnvVar) IsEmpty() bool {
+	return e.ValueStatic == "" && e.ValueFrom.IsEmpty()
+}
+
+// String returns the static value, or a placeholder describing the
+// source. It never returns resolved secret material.
+func (e EnvVar) String() string {
+	if e.ValueStatic != "" {
+		return e.ValueStatic
+	}
+	if e.ValueFrom != nil {
+		return e.ValueFrom.String()
+	}
+	return ""
+}
+
+// EnvVarSource is a reference to an external value. Exactly one field
+// must be set.
+type EnvVarSource struct {
+	// ServiceAccount refers to the token of the named service account.
+	ServiceAccount *string `json:"serviceAccount,omitempty" yaml:"serviceAccount,omitempty"`
+
+	ConfigMapKeyRef *ConfigMapKeySelector `json:"configMapKeyRef,omitempty" yaml:"configMapKeyRef,omitempty"`
+	SecretKeyRef    *SecretKeySelector    `json:"secretKeyRef,omitempty" yaml:"secretKeyRef,omitempty"`
+	HelmRef         *HelmRefSelector      `json:"helmRef,omitempty" yaml:"helmRef,omitempty"`
+}
+
+func (e *EnvVarSource) IsEmpty() bool {
+	return e == nil || (e.ServiceAccount == nil && e.ConfigMapKeyRef == nil && e.SecretKeyRef == nil && e.HelmRef == nil)
+}
+
+func (e *EnvVarSource) String() string {
+	switch {
+	case e == nil:
+		return ""
+	case e.ServiceAccount != nil:
+		return fmt.Sprintf("serviceAccount://%s", *e.ServiceAccount)
+	case e.ConfigMapKeyRef != nil:
+		return fmt.Sprintf("configmap://%s/%s", e.ConfigMapKeyRef.Name, e.ConfigMapKeyRef.Key)
+	case e.SecretKeyRef != nil:
+		return fmt.Sprintf("secret://%s/%s", e.SecretKeyRef.Name, e.SecretKeyRef.Key)
+	case e.HelmRef != nil:
+		return fmt.Sprintf("helm://%s/%s", e.HelmRef.Name, e.HelmRef.Key)
+	}
+	return ""
+}
+
+type ConfigMapKeySelector struct {
+	Name string `json:"name" yaml:"name"`
+	Key  string `json:"key" yaml:"key"`
+}
+
+type SecretKeySelector struct {
+	Name string `json:"name" yaml:"name"`
+	Key  string `json:"key" yaml:"key"`
+}
+
+// HelmRefSelector reads a value from a Helm release.
+type HelmRefSelector struct {
+	// Name of the Helm release.
+	Name string `json:"name" yaml:"name"`
+
+	// Key is a JSONPath expression into the release values,
+	// e.g. {.global.imageRegistry}.
+	Key string `json:"key" yaml:"key"`
+}
+
+// Validate checks the EnvVar for configuration errors without resolving
+// it, so that CRD admission can reject bad specs instead of failing at
+// first lookup.
+func (e EnvVar) Validate() error {
+	if e.ValueStatic != "" && !e.ValueFrom.IsEmpty() {
+		return fmt.Errorf("env var %s: value and valueFrom are mutually exclusive", e.Name)
+	}
+	if e.ValueFrom != nil {
+		if err := e.ValueFrom.Validate(); err != nil {
+			return fmt.Errorf("env var %s: %w", e.Name, err)
+		}
+	}
+	return nil
+}
+
+// Validate checks that exactly one source is set and that the source
+// itself is well-formed.
+func (e *EnvVarSource) Validate() error {
+	if e == nil {
+		return nil
+	}
+
+	var set []string
+	if e.ServiceAccount != nil {
+		set = append(set, "serviceAccount")
+	}
+	if e.ConfigMapKeyRef != nil {
+		set = append(set, "configMapKeyRef")
+		if e.ConfigMapKeyRef.Name == "" || e.ConfigMapKeyRef.Key == "" {
+			return fmt.Errorf("configMapKeyRef requires both name and key")
+		}
+	}
+	if e.SecretKeyRef != nil {
+		set = append(set, "secretKeyRef")
+		if e.SecretKeyRef.Name == "" || e.SecretKeyRef.Key == "" {
+			return fmt.Errorf("secretKeyRef requires both name and key")
+		}
+	}
+	if e.HelmRef != nil {
+		set = append(set, "helmRef")
+		if e.HelmRef.Name == "" {
+			return fmt.Errorf("helmRef requires a release name")
+		}
+		if err := validateJSONPath(e.HelmRef.Key); err != nil {
+			return fmt.Errorf("helmRef has an invalid jsonpath %q: %w", e.HelmRef.Key, err)
+		}
+	}
+
+	switch len(set) {
+	case 0:
+		return fmt.Errorf("valueFrom requires one of serviceAccount, configMapKeyRef, secretKeyRef or helmRef")
+	case 1:
+		return nil
+	default:
+		return fmt.Errorf("only one source may be set, got: %s", strings.Join(set, ", "))
+	}
+}
+
+// validateJSONPath performs a syntactic check of a JSONPath expression:
+// balanced braces/brackets and a non-empty path.
+func validateJSONPath(expr string) error {
+	if strings.TrimSpace(expr) == "" {
+		return fmt.Errorf("jsonpath is empty")
+	}
+
+	var depth, brackets int
+	for _, r := range expr {
+		switch r {
+		case '{':
+			depth++
+		case '}':
+			depth--
+		case '[':
+			brackets++
+		case ']':
+			brackets--
+		}
+		if depth < 0 || brackets < 0 {
+			return fmt.Errorf("unbalanced braces")
+		}
+	}
+	if depth != 0 || brackets != 0 {
+		return fmt.Errorf("unbalanced braces")
+	}
+	return nil
+}
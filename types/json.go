@@ -0,0 +1,88 @@
+package types
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/schema"
+)
+
+// JSON is a raw JSON value stored in a jsonb column.
+type JSON []byte
+
+// NewJSON marshals v into a JSON column value.
+func NewJSON(v any) (JSON, error) {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal %T to JSON: %w", v, err)
+	}
+	return JSON(b), nil
+}
+
+func (j JSON) Value() (driver.Value, error) {
+	if len(j) == 0 {
+		return nil, nil
+	}
+	return []byte(j), nil
+}
+
+func (j *JSON) Scan(value any) error {
+	switch v := value.(type) {
+	case nil:
+		*j = nil
+	case []byte:
+		*j = append((*j)[0:0], v...)
+	case string:
+		*j = JSON(v)
+	default:
+		return fmt.Errorf("failed to scan %T into JSON", value)
+	}
+	return nil
+}
+
+func (j JSON) MarshalJSON() ([]byte, error) {
+	if len(j) == 0 {
+		return []byte("null"), nil
+	}
+	return j, nil
+}
+
+func (j *JSON) MarshalYAML() (any, error) {
+	if len(*j) == 0 {
+		return nil, nil
+	}
+	var v any
+	if err := json.Unmarshal(*j, &v); err != nil {
+		return nil, err
+	}
+	return v, nil
+}
+
+func (j *JSON) UnmarshalJSON(data []byte) error {
+	*j = append((*j)[0:0], data...)
+	return nil
+}
+
+// Unmarshal decodes the column value into out.
+func (j JSON) Unmarshal(out any) error {
+	if len(j) == 0 {
+		return nil
+	}
+	return json.Unmarshal(j, out)
+}
+
+func (JSON) GormDataType() string {
+	return "json"
+}
+
+func (JSON) GormDBDataType(db *gorm.DB, field *schema.Field) string {
+	switch db.Dialector.Name() {
+	case "postgres":
+		return "JSONB"
+	case "sqlite":
+		return "JSON"
+	}
+	return "TEXT"
+}
@@ -0,0 +1,110 @@
+package types
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/schema"
+)
+
+// JSONStringMap is a map[string]string stored in a jsonb column, used
+// for tags, labels and similar key/value attributes.
+type JSONStringMap map[string]string
+
+func (m JSONStringMap) Value() (driver.Value, error) {
+	if m == nil {
+		return nil, nil
+	}
+	return json.Marshal(m)
+}
+
+func (m *JSONStringMap) Scan(value any) error {
+	if value == nil {
+		*m = nil
+		return nil
+	}
+
+	var b []byte
+	switch v := value.(type) {
+	case []byte:
+		b = v
+	case string:
+		b = []byte(v)
+	default:
+		return fmt.Errorf("failed to scan %T into JSONStringMap", value)
+	}
+	return json.Unmarshal(b, m)
+}
+
+func (JSONStringMap) GormDataType() string {
+	return "jsonstringmap"
+}
+
+func (JSONStringMap) GormDBDataType(db *gorm.DB, field *schema.Field) string {
+	switch db.Dialector.Name() {
+	case "postgres":
+		return "JSONB"
+	case "sqlite":
+		return "JSON"
+	}
+	return "TEXT"
+}
+
+// Merge combines the map with the given overrides, later maps taking
+// precedence over earlier ones and all of them over the receiver
+// (defaults < scraper tags < CRD tags, in typical usage).
+//
+// An empty-string value acts as a deletion sentinel: it removes the key
+// from the result instead of setting it to "". The receiver is not
+// modified.
+func (m JSONStringMap) Merge(overrides ...JSONStringMap) JSONStringMap {
+	merged := make(JSONStringMap, len(m))
+	for k, v := range m {
+		merged[k] = v
+	}
+
+	for _, override := range overrides {
+		for k, v := range override {
+			if v == "" {
+				delete(merged, k)
+				continue
+			}
+			merged[k] = v
+		}
+	}
+	return merged
+}
+
+// SortedKeys returns the keys in lexical order.
+func (m JSONStringMap) SortedKeys() []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// String renders the map as "k1=v1,k2=v2" in stable key order, suitable
+// for hashing and cache keys.
+func (m JSONStringMap) String() string {
+	var b strings.Builder
+	for i, k := range m.SortedKeys() {
+		if i > 0 {
+			b.WriteByte(',')
+		}
+		b.WriteString(k)
+		b.WriteByte('=')
+		b.WriteString(m[k])
+	}
+	return b.String()
+}
+
+// Hash returns a stable hash of the map contents.
+func (m JSONStringMap) Hash() string {
+	return hash(m.String())
+}
@@ -0,0 +1,80 @@
+package types
+
+import "fmt"
+
+// SortOrder is the direction of a sorted listing.
+type SortOrder string
+
+const (
+	SortAsc  SortOrder = "asc"
+	SortDesc SortOrder = "desc"
+)
+
+// ListOptions are the standard pagination parameters shared by every
+// list-like API (query, logs, upstream pull) so all of them paginate the
+// same way.
+type ListOptions struct {
+	// Limit caps the page size; implementations apply their own
+	// default and maximum when 0.
+	Limit int `json:"limit,omitempty" query:"limit"`
+
+	// Cursor is the opaque continuation token from the previous page's
+	// NextCursor; empty for the first page.
+	Cursor string `json:"cursor,omitempty" query:"cursor"`
+
+	SortBy    string    `json:"sortBy,omitempty" query:"sort_by"`
+	SortOrder SortOrder `json:"sortOrder,omitempty" query:"sort_order"`
+}
+
+// WithDefaults clamps the limit into (0, max] using def when unset.
+func (t ListOptions) WithDefaults(def, max int) ListOptions {
+	if t.Limit <= 0 {
+		t.Limit = def
+	}
+	if max > 0 && t.Limit > max {
+		t.Limit = max
+	}
+	if t.SortOrder == "" {
+		t.SortOrder = SortAsc
+	}
+	return t
+}
+
+// Validate rejects malformed options before they reach SQL.
+func (t ListOptions) Validate() error {
+	if t.Limit < 0 {
+		return fmt.Errorf("limit must not be negative")
+	}
+	switch t.SortOrder {
+	case "", SortAsc, SortDesc:
+		return nil
+	default:
+		return fmt.Errorf("invalid sort order %q (want asc or desc)", t.SortOrder)
+	}
+}
+
+// Page is one page of a listing.
+type Page[T any] struct {
+	Items []T `json:"items"`
+
+	// NextCursor is the token for the next page; empty when this is
+	// the last page.
+	NextCursor string `json:"nextCursor,omitempty"`
+
+	// Total is the total number of matching items across all pages,
+	// -1 when counting was skipped for cost reasons.
+	Total int64 `json:"total"`
+}
+
+// NewPage builds a page from a full result slice: items beyond the
+// limit are dropped and nextCursor is produced by cursorFor on the last
+// returned item. Callers typically query limit+1 rows to detect whether
+// a next page exists.
+func NewPage[T any](items []T, limit int, total int64, cursorFor func(T) string) Page[T] {
+	page := Page[T]{Items: items, Total: total}
+	if limit > 0 && len(items) > limit {
+		page.Items = items[:limit]
+		page.NextCursor = cursorFor(page.Items[limit-1])
+	}
+	return page
+}
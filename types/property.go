@@ -0,0 +1,255 @@
+package types
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/schema"
+)
+
+// Property is a single named metric or attribute of a component, e.g.
+// memory, cpu or cost. Numeric properties carry optional Min/Max bounds
+// and a Unit that drives human-readable formatting.
+type Property struct {
+	Name    string `json:"name,omitempty"`
+	Tooltip string `json:"tooltip,omitempty"`
+	Icon    string `json:"icon,omitempty"`
+
+	// Text is a non-numeric value, e.g. a version string.
+	Text string `json:"text,omitempty"`
+
+	// Value is the numeric value in the property's base unit.
+	Value *int64 `json:"value,omitempty"`
+	Min   *int64 `json:"min,omitempty"`
+	Max   *int64 `json:"max,omitempty"`
+
+	// Unit determines formatting: "bytes", "millicores", or a currency
+	// code like "USD". Unknown units are appended verbatim.
+	Unit string `json:"unit,omitempty"`
+
+	Links []Link `json:"links,omitempty"`
+}
+
+// Link is a labelled URL attached to a property.
+type Link struct {
+	Label string `json:"label,omitempty"`
+	URL   string `json:"url,omitempty"`
+}
+
+// Formatted renders the value in its unit: bytes as KiB/MiB/GiB,
+// millicores as cores above 1000m, currency with two decimals.
+func (p Property) Formatted() string {
+	if p.Value == nil {
+		return p.Text
+	}
+	value := *p.Value
+
+	switch p.Unit {
+	case "bytes":
+		return formatBytes(value)
+	case "millicores":
+		if value >= 1000 {
+			return fmt.Sprintf("%.2g cores", float64(value)/1000)
+		}
+		return fmt.Sprintf("%dm", value)
+	case "":
+		return fmt.Sprintf("%d", value)
+	default:
+		if isCurrency(p.Unit) {
+			// currency values are stored in the minor unit (cents)
+			return fmt.Sprintf("%.2f %s", float64(value)/100, p.Unit)
+		}
+		return fmt.Sprintf("%d %s", value, p.Unit)
+	}
+}
+
+func formatBytes(value int64) string {
+	const unit = 1024
+	if value < unit {
+		return fmt.Sprintf("%dB", value)
+	}
+	div, exp := int64(unit), 0
+	for n := value / unit; n >= unit; n /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f%ci", float64(value)/float64(div), "KMGTPE"[exp])
+}
+
+func isCurrency(unit string) bool {
+	if len(unit) != 3 {
+		return false
+	}
+	for i := 0; i < 3; i++ {
+		if unit[i] < 'A' || unit[i] > 'Z' {
+			return false
+		}
+	}
+	return true
+}
+
+// PercentOfMax returns Value as a percentage of Max, or -1 when either
+// is missing or Max is zero.
+func (p Property) PercentOfMax() float64 {
+	if p.Value == nil || p.Max == nil || *p.Max == 0 {
+		return -1
+	}
+	return float64(*p.Value) / float64(*p.Max) * 100
+}
+
+// Merge overlays o onto p: non-zero fields of o win, links are appended
+// and de-duplicated by URL.
+func (p *Property) Merge(o Property) {
+	if o.Tooltip != "" {
+		p.Tooltip = o.Tooltip
+	}
+	if o.Icon != "" {
+		p.Icon = o.Icon
+	}
+	if o.Text != "" {
+		p.Text = o.Text
+	}
+	if o.Value != nil {
+		p.Value = o.Value
+	}
+	if o.Min != nil {
+		p.Min = o.Min
+	}
+	if o.Max != nil {
+		p.Max = o.Max
+	}
+	if o.Unit != "" {
+		p.Unit = o.Unit
+	}
+
+	seen := map[string]bool{}
+	for _, link := range p.Links {
+		seen[link.URL] = true
+	}
+	for _, link := range o.Links {
+		if !seen[link.URL] {
+			p.Links = append(p.Links, link)
+			seen[link.URL] = true
+		}
+	}
+}
+
+// DeepCopy returns a copy sharing no pointers with the original.
+func (p Property) DeepCopy() Property {
+	out := p
+	out.Value = copyInt64(p.Value)
+	out.Min = copyInt64(p.Min)
+	out.Max = copyInt64(p.Max)
+	out.Links = append([]Link(nil), p.Links...)
+	return out
+}
+
+func copyInt64(v *int64) *int64 {
+	if v == nil {
+		return nil
+	}
+	out := *v
+	return &out
+}
+
+// Properties is a jsonb column of properties keyed by name.
+type Properties []Property
+
+// Find returns the property with the given name, or nil.
+func (ps Properties) Find(name string) *Property {
+	for i := range ps {
+		if ps[i].Name == name {
+			return &ps[i]
+		}
+	}
+	return nil
+}
+
+// Merge folds overlays into a copy of ps: properties with matching
+// names are merged field-wise, new names are appended.
+func (ps Properties) Merge(overlays ...Property) Properties {
+	out := make(Properties, 0, len(ps)+len(overlays))
+	for _, p := range ps {
+		out = append(out, p.DeepCopy())
+	}
+	for _, overlay := range overlays {
+		if existing := out.Find(overlay.Name); existing != nil {
+			existing.Merge(overlay)
+		} else {
+			out = append(out, overlay.DeepCopy())
+		}
+	}
+	return out
+}
+
+// Rollup aggregates properties from multiple components: values and
+// maxima with the same name and unit are summed, so a parent shows the
+// total of its children.
+func Rollup(groups ...Properties) Properties {
+	var out Properties
+	for _, group := range groups {
+		for _, p := range group {
+			existing := out.Find(p.Name)
+			if existing == nil || existing.Unit != p.Unit {
+				out = append(out, p.DeepCopy())
+				continue
+			}
+			existing.Value = sumInt64(existing.Value, p.Value)
+			existing.Max = sumInt64(existing.Max, p.Max)
+			existing.Min = sumInt64(existing.Min, p.Min)
+		}
+	}
+	return out
+}
+
+func sumInt64(a, b *int64) *int64 {
+	if a == nil {
+		return copyInt64(b)
+	}
+	if b == nil {
+		return copyInt64(a)
+	}
+	sum := *a + *b
+	return &sum
+}
+
+func (ps Properties) Value() (driver.Value, error) {
+	if ps == nil {
+		return nil, nil
+	}
+	return json.Marshal(ps)
+}
+
+func (ps *Properties) Scan(value any) error {
+	if value == nil {
+		*ps = nil
+		return nil
+	}
+
+	var b []byte
+	switch v := value.(type) {
+	case []byte:
+		b = v
+	case string:
+		b = []byte(v)
+	default:
+		return fmt.Errorf("failed to scan %T into Properties", value)
+	}
+	return json.Unmarshal(b, ps)
+}
+
+func (Properties) GormDataType() string {
+	return "properties"
+}
+
+func (Properties) GormDBDataType(db *gorm.DB, field *schema.Field) string {
+	switch db.Dialector.Name() {
+	case "postgres":
+		return "JSONB"
+	case "sqlite":
+		return "JSON"
+	}
+	return "TEXT"
+}
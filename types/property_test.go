@@ -0,0 +1,38 @@
+package types
+
+import "testing"
+
+func int64p(v int64) *int64 { return &v }
+
+func TestPropertyFormatted(t *testing.T) {
+	tests := []struct {
+		property Property
+		want     string
+	}{
+		{Property{Value: int64p(512), Unit: "bytes"}, "512B"},
+		{Property{Value: int64p(3 * 1024 * 1024), Unit: "bytes"}, "3.0Mi"},
+		{Property{Value: int64p(250), Unit: "millicores"}, "250m"},
+		{Property{Value: int64p(2500), Unit: "millicores"}, "2.5 cores"},
+		{Property{Value: int64p(1999), Unit: "USD"}, "19.99 USD"},
+		{Property{Text: "v1.2.3"}, "v1.2.3"},
+	}
+	for _, test := range tests {
+		if got := test.property.Formatted(); got != test.want {
+			t.Errorf("Formatted(%+v) = %q, want %q", test.property, got, test.want)
+		}
+	}
+}
+
+func TestPropertiesRollup(t *testing.T) {
+	a := Properties{{Name: "memory", Unit: "bytes", Value: int64p(100), Max: int64p(200)}}
+	b := Properties{{Name: "memory", Unit: "bytes", Value: int64p(50), Max: int64p(100)}}
+
+	rolled := Rollup(a, b)
+	memory := rolled.Find("memory")
+	if memory == nil || *memory.Value != 150 || *memory.Max != 300 {
+		t.Errorf("Rollup = %+v", rolled)
+	}
+	if *a[0].Value != 100 {
+		t.Error("Rollup mutated its input")
+	}
+}
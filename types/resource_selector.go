@@ -0,0 +1,127 @@
+package types
+
+import (
+	"fmt"
+	"strings"
+)
+
+// ResourceSelector identifies a set of resources (configs, components,
+// checks, ...) either by a direct reference (ID) or by a combination of
+// attribute filters.
+//
+// All fields are ANDed together. An empty selector matches nothing.
+type ResourceSelector struct {
+	// Agent can be the agent id, or the special values "self" or "all".
+	Agent string `yaml:"agent,omitempty" json:"agent,omitempty"`
+
+	ID        string `yaml:"id,omitempty" json:"id,omitempty"`
+	Name      string `yaml:"name,omitempty" json:"name,omitempty"`
+	Namespace string `yaml:"namespace,omitempty" json:"namespace,omitempty"`
+
+	// Types is a list of resource types, e.g. Kubernetes::Pod.
+	Types Items `yaml:"types,omitempty" json:"types,omitempty"`
+
+	Statuses Items  `yaml:"statuses,omitempty" json:"statuses,omitempty"`
+	Health   string `yaml:"health,omitempty" json:"health,omitempty"`
+
+	// TagSelector is a kubernetes-style selector over resource tags,
+	// e.g. "cluster=prod,env!=dev".
+	TagSelector string `yaml:"tagSelector,omitempty" json:"tagSelector,omitempty"`
+
+	// LabelSelector is a kubernetes-style selector over resource labels.
+	LabelSelector string `yaml:"labelSelector,omitempty" json:"labelSelector,omitempty"`
+
+	// FieldSelector is a kubernetes-style selector over resource
+	// properties or columns, e.g. "memory>=2GB".
+	FieldSelector string `yaml:"fieldSelector,omitempty" json:"fieldSelector,omitempty"`
+
+	// Search is a free-form query using the search grammar,
+	// e.g. "name=api-* type=Kubernetes::Pod".
+	Search string `yaml:"search,omitempty" json:"search,omitempty"`
+
+	// MetadataOnly restricts results to object metadata (name, labels,
+	// annotations, ...), letting backends serve the query with cheaper
+	// metadata-only list calls on huge collections like pods and events.
+	MetadataOnly bool `yaml:"metadataOnly,omitempty" json:"metadataOnly,omitempty"`
+}
+
+// Items is a list of strings with helper semantics for matching.
+type Items []string
+
+func (items Items) Contains(item string) bool {
+	for _, i := range items {
+		if i == item {
+			return true
+		}
+	}
+	return false
+}
+
+func (items Items) Sort() Items {
+	sorted := make(Items, len(items))
+	copy(sorted, items)
+	for i := range sorted {
+		for j := i + 1; j < len(sorted); j++ {
+			if sorted[j] < sorted[i] {
+				sorted[i], sorted[j] = sorted[j], sorted[i]
+			}
+		}
+	}
+	return sorted
+}
+
+func (rs ResourceSelector) IsEmpty() bool {
+	return rs.ID == "" && rs.Name == "" && rs.Namespace == "" && rs.Agent == "" &&
+		len(rs.Types) == 0 && len(rs.Statuses) == 0 && rs.Health == "" &&
+		rs.TagSelector == "" && rs.LabelSelector == "" && rs.FieldSelector == "" &&
+		rs.Search == ""
+}
+
+// IsMetadataOnly reports whether callers only need object metadata, so
+// backends can skip fetching specs and statuses.
+func (rs ResourceSelector) IsMetadataOnly() bool {
+	return rs.MetadataOnly
+}
+
+// Hash returns a stable identifier for the selector, suitable for
+// cache keys.
+func (rs ResourceSelector) Hash() string {
+	parts := []string{
+		rs.ID,
+		rs.Name,
+		rs.Namespace,
+		rs.Agent,
+		strings.Join(rs.Types.Sort(), ","),
+		strings.Join(rs.Statuses.Sort(), ","),
+		rs.Health,
+		rs.TagSelector,
+		rs.LabelSelector,
+		rs.FieldSelector,
+		rs.Search,
+		fmt.Sprintf("%t", rs.MetadataOnly),
+	}
+	return hash(strings.Join(parts, "|"))
+}
+
+func (rs ResourceSelector) String() string {
+	var parts []string
+	add := func(k, v string) {
+		if v != "" {
+			parts = append(parts, fmt.Sprintf("%s=%s", k, v))
+		}
+	}
+	add("id", rs.ID)
+	add("name", rs.Name)
+	add("namespace", rs.Namespace)
+	add("agent", rs.Agent)
+	add("types", strings.Join(rs.Types, ","))
+	add("statuses", strings.Join(rs.Statuses, ","))
+	add("health", rs.Health)
+	add("tagSelector", rs.TagSelector)
+	add("labelSelector", rs.LabelSelector)
+	add("fieldSelector", rs.FieldSelector)
+	if rs.Search != "" {
+		parts = append(parts, rs.Search)
+	}
+	return strings.Join(parts, " ")
+}
@@ -0,0 +1,154 @@
+package types
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// ResourceSelector supports a shorthand string form in YAML/JSON in
+// addition to the structured form:
+//
+//	selector: "type=Kubernetes::Pod namespace=prod name=api-*"
+//
+// is equivalent to:
+//
+//	selector:
+//	  types: [Kubernetes::Pod]
+//	  namespace: prod
+//	  name: api-*
+//
+// Unrecognized tokens are preserved in Search.
+
+func (rs *ResourceSelector) UnmarshalJSON(data []byte) error {
+	if len(data) > 0 && data[0] == '"' {
+		var shorthand string
+		if err := json.Unmarshal(data, &shorthand); err != nil {
+			return err
+		}
+		return rs.fromShorthand(shorthand)
+	}
+
+	type alias ResourceSelector // avoid recursing into this method
+	var out alias
+	if err := json.Unmarshal(data, &out); err != nil {
+		return err
+	}
+	*rs = ResourceSelector(out)
+	return nil
+}
+
+func (rs *ResourceSelector) UnmarshalYAML(node *yaml.Node) error {
+	if node.Kind == yaml.ScalarNode {
+		var shorthand string
+		if err := node.Decode(&shorthand); err != nil {
+			return err
+		}
+		return rs.fromShorthand(shorthand)
+	}
+
+	type alias ResourceSelector
+	var out alias
+	if err := node.Decode(&out); err != nil {
+		return err
+	}
+	*rs = ResourceSelector(out)
+	return nil
+}
+
+// MarshalJSON emits the canonical shorthand string when the selector can
+// be represented losslessly in that form, and the structured object
+// otherwise.
+func (rs ResourceSelector) MarshalJSON() ([]byte, error) {
+	if rs.canShorthand() {
+		return json.Marshal(rs.String())
+	}
+
+	type alias ResourceSelector
+	return json.Marshal(alias(rs))
+}
+
+func (rs ResourceSelector) MarshalYAML() (any, error) {
+	if rs.canShorthand() {
+		return rs.String(), nil
+	}
+
+	type alias ResourceSelector
+	return alias(rs), nil
+}
+
+// canShorthand reports whether the shorthand form round-trips: every set
+// value must survive whitespace tokenization and comma list splitting.
+func (rs ResourceSelector) canShorthand() bool {
+	if rs.MetadataOnly {
+		// no shorthand token carries the flag
+		return false
+	}
+	for _, v := range []string{rs.ID, rs.Name, rs.Namespace, rs.Agent, rs.Health} {
+		if strings.ContainsAny(v, " =\t\"") {
+			return false
+		}
+	}
+	for _, list := range []Items{rs.Types, rs.Statuses} {
+		for _, v := range list {
+			if strings.ContainsAny(v, " =,\t\"") {
+				return false
+			}
+		}
+	}
+	// Selector expressions contain their own operators; only safe when
+	// free of whitespace.
+	for _, v := range []string{rs.TagSelector, rs.LabelSelector, rs.FieldSelector} {
+		if strings.ContainsAny(v, " \t\"") {
+			return false
+		}
+	}
+	return rs.Search == "" || !strings.ContainsAny(rs.Search, "\"")
+}
+
+func (rs *ResourceSelector) fromShorthand(shorthand string) error {
+	var search []string
+	for _, token := range strings.Fields(shorthand) {
+		key, value, found := strings.Cut(token, "=")
+		if !found {
+			search = append(search, token)
+			continue
+		}
+
+		switch key {
+		case "id":
+			rs.ID = value
+		case "name":
+			rs.Name = value
+		case "namespace":
+			rs.Namespace = value
+		case "agent":
+			rs.Agent = value
+		case "type", "types":
+			rs.Types = append(rs.Types, strings.Split(value, ",")...)
+		case "status", "statuses":
+			rs.Statuses = append(rs.Statuses, strings.Split(value, ",")...)
+		case "health":
+			rs.Health = value
+		case "tagSelector":
+			rs.TagSelector = value
+		case "labelSelector":
+			rs.LabelSelector = value
+		case "fieldSelector":
+			rs.FieldSelector = value
+		default:
+			// unknown keys fall through to the search grammar
+			search = append(search, token)
+		}
+	}
+
+	if len(search) > 0 {
+		rs.Search = strings.Join(search, " ")
+	}
+	if rs.IsEmpty() {
+		return fmt.Errorf("invalid resource selector: %q", shorthand)
+	}
+	return nil
+}
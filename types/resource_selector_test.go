@@ -0,0 +1,72 @@
+package types
+
+import (
+	"encoding/json"
+	"testing"
+
+	"gopkg.in/yaml.v3"
+)
+
+func TestResourceSelectorShorthand(t *testing.T) {
+	tests := []struct {
+		shorthand string
+		expected  ResourceSelector
+	}{
+		{
+			shorthand: "type=Kubernetes::Pod namespace=prod name=api-*",
+			expected: ResourceSelector{
+				Types:     Items{"Kubernetes::Pod"},
+				Namespace: "prod",
+				Name:      "api-*",
+			},
+		},
+		{
+			shorthand: "types=Kubernetes::Pod,Kubernetes::Deployment health=unhealthy",
+			expected: ResourceSelector{
+				Types:  Items{"Kubernetes::Pod", "Kubernetes::Deployment"},
+				Health: "unhealthy",
+			},
+		},
+		{
+			shorthand: "labelSelector=app=api memory>2",
+			expected: ResourceSelector{
+				LabelSelector: "app=api",
+				Search:        "memory>2",
+			},
+		},
+	}
+
+	for _, test := range tests {
+		var rs ResourceSelector
+		if err := yaml.Unmarshal([]byte(`"`+test.shorthand+`"`), &rs); err != nil {
+			t.Fatalf("unmarshal %q: %v", test.shorthand, err)
+		}
+		if rs.Hash() != test.expected.Hash() {
+			t.Errorf("unmarshal %q: got %+v, want %+v", test.shorthand, rs, test.expected)
+		}
+	}
+}
+
+func TestResourceSelectorRoundTrip(t *testing.T) {
+	original := ResourceSelector{
+		Types:     Items{"Kubernetes::Pod"},
+		Namespace: "prod",
+		Name:      "api-*",
+	}
+
+	marshaled, err := json.Marshal(original)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if marshaled[0] != '"' {
+		t.Errorf("expected shorthand string form, got %s", marshaled)
+	}
+
+	var decoded ResourceSelector
+	if err := json.Unmarshal(marshaled, &decoded); err != nil {
+		t.Fatal(err)
+	}
+	if decoded.Hash() != original.Hash() {
+		t.Errorf("round-trip mismatch: got %+v, want %+v", decoded, original)
+	}
+}
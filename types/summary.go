@@ -0,0 +1,165 @@
+package types
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/schema"
+)
+
+// Summary aggregates the incident, insight and check counts shown on a
+// topology node: incidents and insights are counted per type per
+// severity, checks per health.
+type Summary struct {
+	// Incidents maps incident type -> severity -> count.
+	Incidents map[string]map[string]int `json:"incidents,omitempty"`
+
+	// Insights maps analysis type -> severity -> count.
+	Insights map[string]map[string]int `json:"insights,omitempty"`
+
+	// Checks maps health (healthy, unhealthy, warning) -> count.
+	Checks map[string]int `json:"checks,omitempty"`
+}
+
+// severityRank orders severities from most to least urgent; unknown
+// severities sort last.
+var severityRank = map[string]int{
+	"critical": 0,
+	"blocker":  1,
+	"high":     2,
+	"medium":   3,
+	"low":      4,
+	"info":     5,
+}
+
+// SeverityLess reports whether severity a is more urgent than b, for
+// sorting severity keys consistently across consumers.
+func SeverityLess(a, b string) bool {
+	rankOf := func(s string) int {
+		if rank, ok := severityRank[s]; ok {
+			return rank
+		}
+		return len(severityRank)
+	}
+	return rankOf(a) < rankOf(b)
+}
+
+// Add merges the counts of other into a copy of s; neither receiver nor
+// argument is modified.
+func (s Summary) Add(other Summary) Summary {
+	out := s.DeepCopy()
+	out.Incidents = addNested(out.Incidents, other.Incidents)
+	out.Insights = addNested(out.Insights, other.Insights)
+
+	if len(other.Checks) > 0 && out.Checks == nil {
+		out.Checks = map[string]int{}
+	}
+	for health, count := range other.Checks {
+		out.Checks[health] += count
+	}
+	return out
+}
+
+// SummaryRollup sums the summaries of a node's children, giving the
+// parent's aggregate view.
+func SummaryRollup(children ...Summary) Summary {
+	var out Summary
+	for _, child := range children {
+		out = out.Add(child)
+	}
+	return out
+}
+
+// IsEmpty reports whether the summary holds no counts at all.
+func (s Summary) IsEmpty() bool {
+	return len(s.Incidents) == 0 && len(s.Insights) == 0 && len(s.Checks) == 0
+}
+
+// DeepCopy returns a copy sharing no maps with the original.
+func (s Summary) DeepCopy() Summary {
+	out := Summary{
+		Incidents: copyNested(s.Incidents),
+		Insights:  copyNested(s.Insights),
+	}
+	if s.Checks != nil {
+		out.Checks = make(map[string]int, len(s.Checks))
+		for k, v := range s.Checks {
+			out.Checks[k] = v
+		}
+	}
+	return out
+}
+
+func copyNested(m map[string]map[string]int) map[string]map[string]int {
+	if m == nil {
+		return nil
+	}
+	out := make(map[string]map[string]int, len(m))
+	for typ, severities := range m {
+		inner := make(map[string]int, len(severities))
+		for severity, count := range severities {
+			inner[severity] = count
+		}
+		out[typ] = inner
+	}
+	return out
+}
+
+func addNested(into, from map[string]map[string]int) map[string]map[string]int {
+	if len(from) == 0 {
+		return into
+	}
+	if into == nil {
+		into = map[string]map[string]int{}
+	}
+	for typ, severities := range from {
+		if into[typ] == nil {
+			into[typ] = make(map[string]int, len(severities))
+		}
+		for severity, count := range severities {
+			into[typ][severity] += count
+		}
+	}
+	return into
+}
+
+func (s Summary) Value() (driver.Value, error) {
+	if s.IsEmpty() {
+		return nil, nil
+	}
+	return json.Marshal(s)
+}
+
+func (s *Summary) Scan(value any) error {
+	if value == nil {
+		*s = Summary{}
+		return nil
+	}
+
+	var b []byte
+	switch v := value.(type) {
+	case []byte:
+		b = v
+	case string:
+		b = []byte(v)
+	default:
+		return fmt.Errorf("failed to scan %T into Summary", value)
+	}
+	return json.Unmarshal(b, s)
+}
+
+func (Summary) GormDataType() string {
+	return "summary"
+}
+
+func (Summary) GormDBDataType(db *gorm.DB, field *schema.Field) string {
+	switch db.Dialector.Name() {
+	case "postgres":
+		return "JSONB"
+	case "sqlite":
+		return "JSON"
+	}
+	return "TEXT"
+}
@@ -0,0 +1,154 @@
+package types
+
+import (
+	"fmt"
+	"path"
+	"regexp"
+	"sync"
+)
+
+// TagRule derives one tag for resources it matches, either from a
+// parent's tags or from a pattern over the resource's own name or
+// namespace. Rules let deployments express conventions like "every
+// resource inherits the cluster tag from its scraper" or "namespaces
+// named team-* carry a team tag" once, instead of each scraper
+// reimplementing them.
+type TagRule struct {
+	// Tag is the key the rule sets.
+	Tag string `json:"tag" yaml:"tag"`
+
+	// Value is the tag value; when NameRegex or NamespaceRegex capture
+	// groups, $1-style references are expanded from the match (e.g.
+	// NamespaceRegex "team-(.*)" with Value "$1"). Mutually exclusive
+	// with FromParent.
+	Value string `json:"value,omitempty" yaml:"value,omitempty"`
+
+	// FromParent inherits the value of this tag key from the parent's
+	// tags; the rule is a no-op for resources without that parent tag.
+	FromParent string `json:"fromParent,omitempty" yaml:"fromParent,omitempty"`
+
+	// Type restricts the rule to resources of this type (glob, e.g.
+	// "Kubernetes::*"); empty matches all types.
+	Type string `json:"type,omitempty" yaml:"type,omitempty"`
+
+	// NameRegex and NamespaceRegex restrict the rule to resources whose
+	// name/namespace match; both must match when both are set.
+	NameRegex      string `json:"nameRegex,omitempty" yaml:"nameRegex,omitempty"`
+	NamespaceRegex string `json:"namespaceRegex,omitempty" yaml:"namespaceRegex,omitempty"`
+}
+
+// TagRules is an ordered list of rules; earlier rules win when several
+// derive the same tag.
+type TagRules []TagRule
+
+// TagInput is the resource shape the rules evaluate against.
+type TagInput struct {
+	Type      string
+	Name      string
+	Namespace string
+
+	// Tags are the resource's explicit tags; they always take
+	// precedence over derived ones.
+	Tags JSONStringMap
+
+	// ParentTags are the tags of the resource's parent (or scraper),
+	// the source for FromParent rules.
+	ParentTags JSONStringMap
+}
+
+// Validate checks every rule compiles and has exactly one value source.
+func (rules TagRules) Validate() error {
+	for i, rule := range rules {
+		if rule.Tag == "" {
+			return fmt.Errorf("rule %d: tag is required", i)
+		}
+		if (rule.Value == "") == (rule.FromParent == "") {
+			return fmt.Errorf("rule %d (%s): exactly one of value or fromParent must be set", i, rule.Tag)
+		}
+		for _, pattern := range []string{rule.NameRegex, rule.NamespaceRegex} {
+			if pattern == "" {
+				continue
+			}
+			if _, err := compiledRegex(pattern); err != nil {
+				return fmt.Errorf("rule %d (%s): invalid regex %q: %w", i, rule.Tag, pattern, err)
+			}
+		}
+	}
+	return nil
+}
+
+// Apply returns the resource's effective tags: explicit tags merged
+// over the rule-derived ones. The input is not mutated; scrapers call
+// this once per resource just before persistence.
+func (rules TagRules) Apply(input TagInput) JSONStringMap {
+	derived := JSONStringMap{}
+	for _, rule := range rules {
+		if _, exists := derived[rule.Tag]; exists {
+			continue // an earlier rule already set it
+		}
+		if value, ok := rule.evaluate(input); ok {
+			derived[rule.Tag] = value
+		}
+	}
+
+	for key, value := range input.Tags {
+		derived[key] = value
+	}
+	return derived
+}
+
+// evaluate returns the rule's derived value for the input, or false
+// when the rule does not apply.
+func (rule TagRule) evaluate(input TagInput) (string, bool) {
+	if rule.Type != "" {
+		if matched, err := path.Match(rule.Type, input.Type); err != nil || !matched {
+			return "", false
+		}
+	}
+
+	value := rule.Value
+	expanded := false
+	for _, matcher := range []struct{ pattern, subject string }{
+		{rule.NameRegex, input.Name},
+		{rule.NamespaceRegex, input.Namespace},
+	} {
+		if matcher.pattern == "" {
+			continue
+		}
+		re, err := compiledRegex(matcher.pattern)
+		if err != nil {
+			return "", false
+		}
+		match := re.FindStringSubmatchIndex(matcher.subject)
+		if match == nil {
+			return "", false
+		}
+		// $1-style references resolve against the first capturing
+		// pattern; a second pattern then only gates the match
+		if !expanded && re.NumSubexp() > 0 {
+			value = string(re.ExpandString(nil, value, matcher.subject, match))
+			expanded = true
+		}
+	}
+
+	if rule.FromParent != "" {
+		parent, ok := input.ParentTags[rule.FromParent]
+		return parent, ok
+	}
+	return value, value != ""
+}
+
+// regexCache avoids recompiling rule patterns in scraper hot paths.
+var regexCache sync.Map // pattern -> *regexp.Regexp
+
+func compiledRegex(pattern string) (*regexp.Regexp, error) {
+	if cached, ok := regexCache.Load(pattern); ok {
+		return cached.(*regexp.Regexp), nil
+	}
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return nil, err
+	}
+	regexCache.Store(pattern, re)
+	return re, nil
+}
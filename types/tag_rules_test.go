@@ -0,0 +1,49 @@
+package types
+
+import "testing"
+
+func TestTagRulesApply(t *testing.T) {
+	rules := TagRules{
+		{Tag: "cluster", FromParent: "cluster"},
+		{Tag: "team", NamespaceRegex: `^team-(.*)$`, Value: "$1"},
+		{Tag: "env", Type: "Kubernetes::*", Value: "prod"},
+		{Tag: "env", Value: "fallback"},
+	}
+	if err := rules.Validate(); err != nil {
+		t.Fatalf("Validate() = %v", err)
+	}
+
+	tags := rules.Apply(TagInput{
+		Type:       "Kubernetes::Pod",
+		Name:       "api",
+		Namespace:  "team-platform",
+		Tags:       JSONStringMap{"owner": "sre"},
+		ParentTags: JSONStringMap{"cluster": "prod-eu"},
+	})
+
+	for key, want := range map[string]string{
+		"cluster": "prod-eu",  // inherited from parent
+		"team":    "platform", // captured from namespace
+		"env":     "prod",     // first matching rule wins
+		"owner":   "sre",      // explicit tags pass through
+	} {
+		if tags[key] != want {
+			t.Errorf("tags[%q] = %q, want %q", key, tags[key], want)
+		}
+	}
+
+	// explicit tags beat derived ones
+	tags = rules.Apply(TagInput{Namespace: "team-x", Tags: JSONStringMap{"team": "override"}})
+	if tags["team"] != "override" {
+		t.Errorf("explicit tag overridden: %q", tags["team"])
+	}
+}
+
+func TestTagRulesValidate(t *testing.T) {
+	if err := (TagRules{{Tag: "a", Value: "x", FromParent: "y"}}).Validate(); err == nil {
+		t.Error("expected error for both value and fromParent")
+	}
+	if err := (TagRules{{Tag: "a", Value: "x", NameRegex: "("}}).Validate(); err == nil {
+		t.Error("expected error for invalid regex")
+	}
+}
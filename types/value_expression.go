@@ -0,0 +1,151 @@
+package types
+
+import (
+	"fmt"
+	"sync"
+
+	"github.com/google/cel-go/cel"
+)
+
+// ValueExpression is a CEL expression evaluated against a resource
+// environment, used by selectors and notification filters, e.g.
+//
+//	health == 'unhealthy' && tags['env'] != 'dev'
+type ValueExpression string
+
+func (ve ValueExpression) IsEmpty() bool {
+	return ve == ""
+}
+
+// Validate checks that the expression compiles against the resource
+// environment, without evaluating it.
+func (ve ValueExpression) Validate() error {
+	_, err := compiledExpression(string(ve))
+	return err
+}
+
+// ExpressionEnv is the resource environment an expression evaluates
+// against; unset fields appear as empty values.
+type ExpressionEnv struct {
+	ID        string        `json:"id,omitempty"`
+	Name      string        `json:"name,omitempty"`
+	Namespace string        `json:"namespace,omitempty"`
+	Type      string        `json:"type,omitempty"`
+	Status    string        `json:"status,omitempty"`
+	Health    string        `json:"health,omitempty"`
+	Tags      JSONStringMap `json:"tags,omitempty"`
+	Labels    JSONStringMap `json:"labels,omitempty"`
+}
+
+func (e ExpressionEnv) vars() map[string]any {
+	tags := e.Tags
+	if tags == nil {
+		tags = JSONStringMap{}
+	}
+	labels := e.Labels
+	if labels == nil {
+		labels = JSONStringMap{}
+	}
+	fields := map[string]any{
+		"id":        e.ID,
+		"name":      e.Name,
+		"namespace": e.Namespace,
+		"type":      e.Type,
+		"status":    e.Status,
+		"health":    e.Health,
+		"tags":      map[string]string(tags),
+		"labels":    map[string]string(labels),
+	}
+
+	vars := map[string]any{"resource": fields}
+	for name, value := range fields {
+		if name == "namespace" || name == "type" {
+			continue
+		}
+		vars[name] = value
+	}
+	return vars
+}
+
+// Eval evaluates the expression and returns its native value.
+func (ve ValueExpression) Eval(env ExpressionEnv) (any, error) {
+	program, err := compiledExpression(string(ve))
+	if err != nil {
+		return nil, err
+	}
+
+	out, _, err := program.Eval(env.vars())
+	if err != nil {
+		return nil, fmt.Errorf("failed to evaluate %q: %w", ve, err)
+	}
+	return out.Value(), nil
+}
+
+// EvalBool evaluates a filter expression; non-boolean results are an
+// error rather than truthiness guesses.
+func (ve ValueExpression) EvalBool(env ExpressionEnv) (bool, error) {
+	out, err := ve.Eval(env)
+	if err != nil {
+		return false, err
+	}
+	result, ok := out.(bool)
+	if !ok {
+		return false, fmt.Errorf("expression %q must evaluate to bool, got %T", ve, out)
+	}
+	return result, nil
+}
+
+// expressionEnv declares the variables of ExpressionEnv; environments
+// are immutable, so it is built once.
+//
+// namespace and type cannot be top-level variables — CEL reserves the
+// former and pre-declares the latter — but they are legal as field
+// selections, so every field also hangs off a resource root:
+// resource.namespace, resource.type.
+var expressionEnv = sync.OnceValues(func() (*cel.Env, error) {
+	return cel.NewEnv(
+		cel.Variable("id", cel.StringType),
+		cel.Variable("name", cel.StringType),
+		cel.Variable("status", cel.StringType),
+		cel.Variable("health", cel.StringType),
+		cel.Variable("tags", cel.MapType(cel.StringType, cel.StringType)),
+		cel.Variable("labels", cel.MapType(cel.StringType, cel.StringType)),
+		cel.Variable("resource", cel.MapType(cel.StringType, cel.DynType)),
+	)
+})
+
+// expressionCache avoids recompiling expressions in selector and
+// notification hot paths; compilation failures are cached too.
+var expressionCache sync.Map // expression -> compiledProgram
+
+type compiledProgram struct {
+	program cel.Program
+	err     error
+}
+
+func compiledExpression(expression string) (cel.Program, error) {
+	if cached, ok := expressionCache.Load(expression); ok {
+		compiled := cached.(compiledProgram)
+		return compiled.program, compiled.err
+	}
+
+	program, err := compileExpression(expression)
+	if err != nil {
+		err = fmt.Errorf("invalid expression %q: %w", expression, err)
+	}
+	expressionCache.Store(expression, compiledProgram{program: program, err: err})
+	return program, err
+}
+
+func compileExpression(expression string) (cel.Program, error) {
+	env, err := expressionEnv()
+	if err != nil {
+		return nil, err
+	}
+
+	ast, issues := env.Compile(expression)
+	if issues != nil && issues.Err() != nil {
+		return nil, issues.Err()
+	}
+	return env.Program(ast)
+}
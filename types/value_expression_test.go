@@ -0,0 +1,49 @@
+package types
+
+import "testing"
+
+func TestValueExpressionEval(t *testing.T) {
+	env := ExpressionEnv{
+		Name:      "api",
+		Namespace: "prod",
+		Health:    "unhealthy",
+		Tags:      JSONStringMap{"env": "prod", "team": "payments"},
+	}
+
+	tests := []struct {
+		expression string
+		want       bool
+	}{
+		{`health == 'unhealthy'`, true},
+		{`tags['env'] != 'dev'`, true},
+		{`resource.namespace == 'prod' && tags['team'] == 'payments'`, true},
+		{`resource.type == ''`, true},
+		{`name.startsWith('db')`, false},
+		{`'missing' in tags`, false},
+	}
+	for _, test := range tests {
+		got, err := ValueExpression(test.expression).EvalBool(env)
+		if err != nil {
+			t.Fatalf("EvalBool(%q): %v", test.expression, err)
+		}
+		if got != test.want {
+			t.Errorf("EvalBool(%q) = %v, want %v", test.expression, got, test.want)
+		}
+	}
+
+	if _, err := ValueExpression(`name == 'api'`).Eval(ExpressionEnv{}); err != nil {
+		t.Errorf("Eval with empty env: %v", err)
+	}
+}
+
+func TestValueExpressionValidate(t *testing.T) {
+	if err := ValueExpression(`health == 'healthy'`).Validate(); err != nil {
+		t.Errorf("valid expression rejected: %v", err)
+	}
+	if err := ValueExpression(`nosuchvar == 1`).Validate(); err == nil {
+		t.Error("expected error for unknown variable")
+	}
+	if _, err := ValueExpression(`name`).EvalBool(ExpressionEnv{Name: "x"}); err == nil {
+		t.Error("expected error for non-boolean filter result")
+	}
+}
@@ -0,0 +1,88 @@
+package types
+
+import (
+	"database/sql/driver"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/schema"
+)
+
+// Vector is an embedding stored in a pgvector column. The pgvector wire
+// format is a bracketed comma-separated list: "[0.1,0.2,0.3]".
+type Vector []float32
+
+func (v Vector) Value() (driver.Value, error) {
+	if v == nil {
+		return nil, nil
+	}
+	return v.String(), nil
+}
+
+func (v Vector) String() string {
+	var b strings.Builder
+	b.WriteByte('[')
+	for i, f := range v {
+		if i > 0 {
+			b.WriteByte(',')
+		}
+		b.WriteString(strconv.FormatFloat(float64(f), 'f', -1, 32))
+	}
+	b.WriteByte(']')
+	return b.String()
+}
+
+func (v *Vector) Scan(value any) error {
+	if value == nil {
+		*v = nil
+		return nil
+	}
+
+	var s string
+	switch val := value.(type) {
+	case []byte:
+		s = string(val)
+	case string:
+		s = val
+	default:
+		return fmt.Errorf("failed to scan %T into Vector", value)
+	}
+
+	s = strings.TrimSpace(s)
+	if !strings.HasPrefix(s, "[") || !strings.HasSuffix(s, "]") {
+		return fmt.Errorf("malformed vector %q", s)
+	}
+	s = s[1 : len(s)-1]
+	if s == "" {
+		*v = Vector{}
+		return nil
+	}
+
+	parts := strings.Split(s, ",")
+	out := make(Vector, 0, len(parts))
+	for _, part := range parts {
+		f, err := strconv.ParseFloat(strings.TrimSpace(part), 32)
+		if err != nil {
+			return fmt.Errorf("malformed vector element %q: %w", part, err)
+		}
+		out = append(out, float32(f))
+	}
+	*v = out
+	return nil
+}
+
+func (Vector) GormDataType() string {
+	return "vector"
+}
+
+func (v Vector) GormDBDataType(db *gorm.DB, field *schema.Field) string {
+	if db.Dialector.Name() == "postgres" {
+		if field.Size > 0 {
+			return fmt.Sprintf("vector(%d)", field.Size)
+		}
+		return "vector"
+	}
+	return "TEXT"
+}
@@ -0,0 +1,49 @@
+package upstream
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+)
+
+// PushFKError is returned by the upstream when some items in a batch
+// reference parent rows the upstream has not seen yet.
+type PushFKError struct {
+	// IDs of the items that failed the foreign key check.
+	IDs []string `json:"ids,omitempty"`
+}
+
+func (t *PushFKError) Error() string {
+	return fmt.Sprintf("foreign key error for %d items", len(t.IDs))
+}
+
+// PushError is a non-2xx response from the upstream push endpoint.
+type PushError struct {
+	StatusCode int
+	Message    string
+	FKError    *PushFKError
+}
+
+func (t *PushError) Error() string {
+	return fmt.Sprintf("upstream returned %d: %s", t.StatusCode, t.Message)
+}
+
+func (t *PushError) Unwrap() error {
+	if t.FKError != nil {
+		return t.FKError
+	}
+	return nil
+}
+
+func parsePushError(resp *http.Response) error {
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, 4096))
+
+	pushError := &PushError{StatusCode: resp.StatusCode, Message: string(body)}
+
+	var fkError PushFKError
+	if err := json.Unmarshal(body, &fkError); err == nil && len(fkError.IDs) > 0 {
+		pushError.FKError = &fkError
+	}
+	return pushError
+}
@@ -0,0 +1,62 @@
+package upstream
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/flanksource/commons-db/context"
+	"github.com/flanksource/commons-db/types"
+)
+
+// ReconcileRun is a persisted record of a single ReconcileAll run.
+type ReconcileRun struct {
+	ID        string        `json:"id" gorm:"default:gen_random_uuid()"`
+	AgentName string        `json:"agent_name"`
+	StartedAt time.Time     `json:"started_at"`
+	Duration  time.Duration `json:"duration"`
+	Success   int           `json:"success"`
+	FKFailed  int           `json:"fk_failed"`
+	Errors    int           `json:"errors"`
+
+	// Summary holds the full per-table breakdown.
+	Summary types.JSON `json:"summary"`
+}
+
+func (ReconcileRun) TableName() string {
+	return "reconcile_history"
+}
+
+func persistSummary(ctx context.Context, agentName string, started time.Time, summary ReconcileSummary) error {
+	run := ReconcileRun{
+		AgentName: agentName,
+		StartedAt: started,
+		Duration:  time.Since(started),
+	}
+	for _, table := range summary {
+		run.Success += table.Success
+		run.FKFailed += table.FKFailed
+		if table.Error != "" {
+			run.Errors++
+		}
+	}
+
+	var err error
+	if run.Summary, err = types.NewJSON(summary); err != nil {
+		return err
+	}
+	return ctx.DB().Create(&run).Error
+}
+
+// ReconcileHistory returns reconcile runs since the given time, newest
+// first, so operators can see when sync degraded and which tables were
+// repeatedly failing.
+func ReconcileHistory(ctx context.Context, since time.Time) ([]ReconcileRun, error) {
+	var runs []ReconcileRun
+	if err := ctx.DB().
+		Where("started_at >= ?", since).
+		Order("started_at DESC").
+		Find(&runs).Error; err != nil {
+		return nil, fmt.Errorf("failed to query reconcile history: %w", err)
+	}
+	return runs, nil
+}
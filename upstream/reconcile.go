@@ -0,0 +1,177 @@
+package upstream
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/flanksource/commons-db/context"
+	"github.com/flanksource/commons-db/db"
+)
+
+// ReconciledTables are pushed to the upstream in order: parent tables
+// first so that foreign keys resolve.
+var ReconciledTables = []string{
+	"config_items",
+	"config_changes",
+	"config_analysis",
+	"check_statuses",
+	"job_history",
+}
+
+const reconcileBatchSize = 500
+
+// ReconcileTableSummary records the outcome of reconciling one table.
+type ReconcileTableSummary struct {
+	Success  int    `json:"success,omitempty"`
+	FKFailed int    `json:"fk_failed,omitempty"`
+	Repaired int    `json:"repaired,omitempty"`
+	Error    string `json:"error,omitempty"`
+	Skipped  bool   `json:"skipped,omitempty"`
+}
+
+// ReconcileSummary is the per-table outcome of a full reconciliation run.
+type ReconcileSummary map[string]ReconcileTableSummary
+
+func (t ReconcileSummary) Error() error {
+	var errs []error
+	for table, summary := range t {
+		if summary.Error != "" {
+			errs = append(errs, fmt.Errorf("%s: %s", table, summary.Error))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// ReconcileAll pushes all unpushed rows of every reconciled table to the
+// upstream, returning a per-table summary. The summary is persisted for
+// later inspection via ReconcileHistory.
+func ReconcileAll(ctx context.Context, config UpstreamConfig) (ReconcileSummary, error) {
+	client := NewClient(config)
+	summary := ReconcileSummary{}
+	started := time.Now()
+
+	// Tables can be disabled at runtime (e.g. to shed load during an
+	// incident) via upstream.reconcile.disable=table1,table2. The
+	// property is re-read on every run, so changes take effect on the
+	// next cycle without a restart.
+	disabled := ctx.Properties().List("upstream.reconcile.disable", nil)
+
+	for _, table := range ReconciledTables {
+		if contains(disabled, table) {
+			ctx.Debugf("upstream reconcile of %s is disabled by property", table)
+			summary[table] = ReconcileTableSummary{Skipped: true}
+			continue
+		}
+		summary[table] = reconcileTable(ctx, client, table)
+	}
+
+	if err := persistSummary(ctx, config.AgentName, started, summary); err != nil {
+		ctx.Warnf("failed to persist reconcile summary: %v", err)
+	}
+	return summary, summary.Error()
+}
+
+func reconcileTable(ctx context.Context, client *Client, table string) ReconcileTableSummary {
+	var summary ReconcileTableSummary
+
+	for {
+		var items []map[string]any
+		if err := ctx.DB().Table(table).
+			Where("is_pushed IS FALSE").
+			Limit(reconcileBatchSize).
+			Find(&items).Error; err != nil {
+			summary.Error = fmt.Sprintf("failed to fetch unpushed rows: %v", err)
+			return summary
+		}
+		if len(items) == 0 {
+			return summary
+		}
+		fetched := len(items)
+
+		// self-referencing tables must push parents before children or
+		// the whole descendant chain churns through FK errors
+		if ref, ok := parentRefs[table]; ok && ref.Table == table {
+			items = sortParentsFirst(items, ref.Column)
+		}
+
+		if err := client.Push(PushData{Table: table, Items: items}); err != nil {
+			var fkError *PushFKError
+			if errors.As(err, &fkError) {
+				summary.FKFailed += len(fkError.IDs)
+				items = dropFailed(items, fkError.IDs)
+
+				// push the missing parents and retry the rejected rows
+				// now, instead of leaving them for the next full run
+				repaired, repairErr := repairFKFailures(ctx, client, table, fkError.IDs)
+				if repairErr != nil {
+					ctx.Warnf("failed to repair %d fk-rejected rows in %s: %v", len(fkError.IDs), table, repairErr)
+				}
+				summary.Repaired += len(repaired)
+				items = append(items, repaired...)
+			} else {
+				summary.Error = err.Error()
+				return summary
+			}
+		}
+
+		if len(items) > 0 {
+			ids := itemIDs(items)
+			// marking rows as pushed races with scrapers updating the
+			// same rows; retry on deadlocks instead of failing the run
+			err := db.RetryOnSerialization(ctx, func() error {
+				return ctx.DB().Table(table).
+					Where("id IN ?", ids).
+					Update("is_pushed", true).Error
+			})
+			if err != nil {
+				summary.Error = fmt.Sprintf("failed to mark rows as pushed: %v", err)
+				return summary
+			}
+			summary.Success += len(ids)
+		} else {
+			// the whole batch failed the FK check; stop so we don't
+			// refetch the same rows in a tight loop
+			return summary
+		}
+
+		if fetched < reconcileBatchSize {
+			return summary
+		}
+	}
+}
+
+func contains(items []string, item string) bool {
+	for _, i := range items {
+		if i == item {
+			return true
+		}
+	}
+	return false
+}
+
+func itemIDs(items []map[string]any) []any {
+	ids := make([]any, 0, len(items))
+	for _, item := range items {
+		ids = append(ids, item["id"])
+	}
+	return ids
+}
+
+func dropFailed(items []map[string]any, failedIDs []string) []map[string]any {
+	failed := make(map[string]struct{}, len(failedIDs))
+	for _, id := range failedIDs {
+		failed[id] = struct{}{}
+	}
+
+	kept := items[:0]
+	for _, item := range items {
+		if id, ok := item["id"].(string); ok {
+			if _, isFailed := failed[id]; isFailed {
+				continue
+			}
+		}
+		kept = append(kept, item)
+	}
+	return kept
+}
@@ -0,0 +1,134 @@
+package upstream
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/flanksource/commons-db/context"
+	"github.com/flanksource/commons-db/db"
+)
+
+// defaultRepairDepth bounds how far up the parent chain a repair may
+// walk; override with the upstream.reconcile.repair_depth property
+// (0 disables repair).
+const defaultRepairDepth = 3
+
+// parentRef names the column of a table that references its parent
+// rows, and the table those parents live in.
+type parentRef struct {
+	Column string
+	Table  string
+}
+
+// parentRefs drives FK repair: when the upstream rejects rows of a
+// table for missing parents, the referenced rows of the parent table
+// are pushed on demand. Tables without an entry (job_history) can't
+// fail the FK check on a parent we own.
+var parentRefs = map[string]parentRef{
+	"config_items":    {Column: "parent_id", Table: "config_items"},
+	"config_changes":  {Column: "config_id", Table: "config_items"},
+	"config_analysis": {Column: "config_id", Table: "config_items"},
+}
+
+// repairFKFailures pushes the missing parents of rows the upstream
+// rejected and then retries the rejected rows, all within the current
+// reconcile cycle — after a restore, convergence doesn't have to wait
+// for the parent table's next full run. The parent chain is walked to a
+// bounded depth; rows whose retry succeeds are returned so the caller
+// can mark them pushed.
+func repairFKFailures(ctx context.Context, client *Client, table string, failedIDs []string) ([]map[string]any, error) {
+	depth := ctx.Properties().Int("upstream.reconcile.repair_depth", defaultRepairDepth)
+	if depth <= 0 {
+		return nil, nil
+	}
+
+	ref, ok := parentRefs[table]
+	if !ok {
+		return nil, nil
+	}
+
+	parentIDs, err := referencedParents(ctx, table, ref.Column, failedIDs)
+	if err != nil {
+		return nil, err
+	}
+	if err := pushParents(ctx, client, ref.Table, parentIDs, depth); err != nil {
+		return nil, err
+	}
+
+	// parents are in place; retry the rejected rows once
+	var items []map[string]any
+	if err := ctx.DB().Table(table).Where("id IN ?", failedIDs).Find(&items).Error; err != nil {
+		return nil, fmt.Errorf("failed to refetch rejected rows: %w", err)
+	}
+	if err := client.Push(PushData{Table: table, Items: items}); err != nil {
+		return nil, fmt.Errorf("retry after parent repair failed: %w", err)
+	}
+	return items, nil
+}
+
+// pushParents pushes the given parent rows (regardless of their
+// is_pushed state — the upstream may have lost them in a restore),
+// recursing when those parents are themselves missing parents.
+func pushParents(ctx context.Context, client *Client, table string, ids []string, depth int) error {
+	if len(ids) == 0 {
+		return nil
+	}
+	if depth <= 0 {
+		return fmt.Errorf("repair depth exhausted with %d parents of %s still missing", len(ids), table)
+	}
+
+	var items []map[string]any
+	if err := ctx.DB().Table(table).Where("id IN ?", ids).Find(&items).Error; err != nil {
+		return fmt.Errorf("failed to fetch parent rows from %s: %w", table, err)
+	}
+	if len(items) == 0 {
+		// the parents don't exist locally either; nothing to repair
+		return fmt.Errorf("%d parents referenced by rejected rows are missing locally in %s", len(ids), table)
+	}
+
+	ctx.Debugf("pushing %d missing parents from %s (depth %d)", len(items), table, depth)
+	if err := client.Push(PushData{Table: table, Items: items}); err != nil {
+		var fkError *PushFKError
+		if !errors.As(err, &fkError) {
+			return err
+		}
+
+		// the parents are missing their own parents: push the
+		// grandparents, then retry this level once
+		ref, ok := parentRefs[table]
+		if !ok {
+			return err
+		}
+		grandparentIDs, refErr := referencedParents(ctx, table, ref.Column, fkError.IDs)
+		if refErr != nil {
+			return refErr
+		}
+		if err := pushParents(ctx, client, ref.Table, grandparentIDs, depth-1); err != nil {
+			return err
+		}
+		if err := client.Push(PushData{Table: table, Items: items}); err != nil {
+			return err
+		}
+	}
+
+	return db.RetryOnSerialization(ctx, func() error {
+		return ctx.DB().Table(table).
+			Where("id IN ?", ids).
+			Update("is_pushed", true).Error
+	})
+}
+
+// referencedParents returns the distinct non-null parent ids referenced
+// by the given rows.
+func referencedParents(ctx context.Context, table, column string, ids []string) ([]string, error) {
+	var parentIDs []string
+	q := ctx.DB().Table(table).
+		Distinct(column).
+		Where("id IN ?", ids).
+		Where(fmt.Sprintf("%s IS NOT NULL", column)).
+		Pluck(column, &parentIDs)
+	if q.Error != nil {
+		return nil, fmt.Errorf("failed to resolve parents of rejected rows in %s: %w", table, q.Error)
+	}
+	return parentIDs, nil
+}
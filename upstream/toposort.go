@@ -0,0 +1,53 @@
+package upstream
+
+// sortParentsFirst orders a batch of rows that reference each other
+// through column (config_items.parent_id) so every row comes after its
+// parent when the parent is in the same batch. Rows whose parent is
+// absent — already pushed, or left for repair — keep their fetch order,
+// as do any rows in a reference cycle, which are appended at the end.
+func sortParentsFirst(items []map[string]any, column string) []map[string]any {
+	if len(items) < 2 {
+		return items
+	}
+
+	indexByID := make(map[string]int, len(items))
+	for i, item := range items {
+		if id, ok := item["id"].(string); ok {
+			indexByID[id] = i
+		}
+	}
+
+	children := make(map[int][]int)
+	var roots []int
+	for i, item := range items {
+		if parent, ok := item[column].(string); ok {
+			if parentIndex, inBatch := indexByID[parent]; inBatch && parentIndex != i {
+				children[parentIndex] = append(children[parentIndex], i)
+				continue
+			}
+		}
+		roots = append(roots, i)
+	}
+
+	sorted := make([]map[string]any, 0, len(items))
+	visited := make([]bool, len(items))
+	for queue := roots; len(queue) > 0; {
+		i := queue[0]
+		queue = queue[1:]
+		if visited[i] {
+			continue
+		}
+		visited[i] = true
+		sorted = append(sorted, items[i])
+		queue = append(queue, children[i]...)
+	}
+
+	// cycles never reach a root; push them as fetched and let the FK
+	// path sort it out
+	for i, item := range items {
+		if !visited[i] {
+			sorted = append(sorted, item)
+		}
+	}
+	return sorted
+}
@@ -0,0 +1,114 @@
+package upstream
+
+import "testing"
+
+func item(id, parent string) map[string]any {
+	row := map[string]any{"id": id}
+	if parent != "" {
+		row["parent_id"] = parent
+	}
+	return row
+}
+
+func ids(items []map[string]any) []string {
+	out := make([]string, len(items))
+	for i, row := range items {
+		out[i], _ = row["id"].(string)
+	}
+	return out
+}
+
+func indexOf(ids []string, id string) int {
+	for i, candidate := range ids {
+		if candidate == id {
+			return i
+		}
+	}
+	return -1
+}
+
+func TestSortParentsFirst(t *testing.T) {
+	tests := []struct {
+		name  string
+		items []map[string]any
+		// orderings that must hold in the output: parent before child
+		before [][2]string
+	}{
+		{
+			name:   "child fetched before its parent",
+			items:  []map[string]any{item("child", "parent"), item("parent", "")},
+			before: [][2]string{{"parent", "child"}},
+		},
+		{
+			name: "grandparent chain in reverse order",
+			items: []map[string]any{
+				item("grandchild", "child"),
+				item("child", "parent"),
+				item("parent", ""),
+			},
+			before: [][2]string{{"parent", "child"}, {"child", "grandchild"}},
+		},
+		{
+			name: "siblings under one parent",
+			items: []map[string]any{
+				item("a", "parent"),
+				item("b", "parent"),
+				item("parent", ""),
+			},
+			before: [][2]string{{"parent", "a"}, {"parent", "b"}},
+		},
+		{
+			name:  "absent parent keeps fetch order",
+			items: []map[string]any{item("a", "already-pushed"), item("b", "")},
+			before: [][2]string{
+				{"a", "b"},
+			},
+		},
+		{
+			name: "cycle rows come after the acyclic ones",
+			items: []map[string]any{
+				item("x", "y"),
+				item("y", "x"),
+				item("root", ""),
+			},
+			before: [][2]string{{"root", "x"}, {"root", "y"}, {"x", "y"}},
+		},
+		{
+			name:   "self reference is treated as a root",
+			items:  []map[string]any{item("self", "self")},
+			before: nil,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			sorted := sortParentsFirst(test.items, "parent_id")
+			if len(sorted) != len(test.items) {
+				t.Fatalf("got %d items, want %d", len(sorted), len(test.items))
+			}
+
+			got := ids(sorted)
+			for _, pair := range test.before {
+				first, second := indexOf(got, pair[0]), indexOf(got, pair[1])
+				if first == -1 || second == -1 {
+					t.Fatalf("output %v is missing %q or %q", got, pair[0], pair[1])
+				}
+				if first > second {
+					t.Errorf("output %v: %q must come before %q", got, pair[0], pair[1])
+				}
+			}
+		})
+	}
+}
+
+func TestDropFailed(t *testing.T) {
+	items := []map[string]any{item("a", ""), item("b", ""), item("c", "")}
+	kept := dropFailed(items, []string{"b"})
+	if got := ids(kept); len(got) != 2 || got[0] != "a" || got[1] != "c" {
+		t.Errorf("dropFailed = %v, want [a c]", got)
+	}
+
+	if kept := dropFailed([]map[string]any{item("a", "")}, nil); len(kept) != 1 {
+		t.Errorf("dropFailed with no failures dropped rows: %v", ids(kept))
+	}
+}
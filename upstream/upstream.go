@@ -0,0 +1,95 @@
+// Package upstream implements agent → upstream reconciliation: rows
+// created locally on an agent are periodically pushed to a central
+// upstream instance over HTTP.
+package upstream
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sync"
+	"time"
+)
+
+// UpstreamConfig is the connection configuration for an upstream server.
+type UpstreamConfig struct {
+	Host      string `json:"host,omitempty"`
+	AgentName string `json:"agentName,omitempty"`
+	Username  string `json:"username,omitempty"`
+	Password  string `json:"password,omitempty"`
+}
+
+func (t UpstreamConfig) Valid() bool {
+	return t.Host != "" && t.AgentName != ""
+}
+
+// PushData is the payload pushed to the upstream in a single request.
+type PushData struct {
+	// Version is the protocol version of this payload (see ProtocolV*);
+	// zero on payloads from pre-versioning agents.
+	Version int `json:"version,omitempty"`
+
+	AgentName string           `json:"agent_name"`
+	Table     string           `json:"table"`
+	Items     []map[string]any `json:"items"`
+}
+
+// Client pushes batches of rows to an upstream server.
+type Client struct {
+	config     UpstreamConfig
+	httpClient *http.Client
+
+	// protocol negotiation happens once per client, on the first push
+	negotiateOnce     sync.Once
+	negotiatedVersion int
+	negotiateErr      error
+}
+
+func NewClient(config UpstreamConfig) *Client {
+	return &Client{
+		config:     config,
+		httpClient: &http.Client{Timeout: 2 * time.Minute},
+	}
+}
+
+// Push sends a batch to the upstream push endpoint, downgrading the
+// payload to the negotiated protocol version when the upstream is older.
+func (c *Client) Push(data PushData) error {
+	data.AgentName = c.config.AgentName
+
+	version, err := c.negotiate()
+	if err != nil {
+		return err
+	}
+	data = shimForVersion(data, version)
+
+	body, err := json.Marshal(data)
+	if err != nil {
+		return fmt.Errorf("failed to marshal push data: %w", err)
+	}
+
+	endpoint, err := url.JoinPath(c.config.Host, "upstream", "push")
+	if err != nil {
+		return fmt.Errorf("invalid upstream host %s: %w", c.config.Host, err)
+	}
+
+	req, err := http.NewRequest(http.MethodPost, endpoint, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.SetBasicAuth(c.config.Username, c.config.Password)
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to push to upstream: %w", err)
+	}
+	defer resp.Body.Close() //nolint:errcheck
+
+	if resp.StatusCode != http.StatusOK {
+		return parsePushError(resp)
+	}
+	return nil
+}
@@ -0,0 +1,153 @@
+// Package upstreamtest provides an in-memory upstream server for
+// exercising agent reconciliation logic without a second database.
+package upstreamtest
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/flanksource/commons-db/upstream"
+)
+
+// Server is an in-memory implementation of the upstream push/pull
+// endpoints with configurable failure injection.
+type Server struct {
+	*httptest.Server
+
+	mu     sync.Mutex
+	tables map[string][]map[string]any
+
+	// failure injection state
+	fkErrorIDs   map[string][]string // table -> ids that fail the FK check
+	throttleNext int                 // respond 429 to the next n pushes
+	failNext     int                 // respond 500 to the next n pushes
+	pushCount    int
+}
+
+// NewServer starts an in-memory upstream; it is shut down automatically
+// when the test finishes.
+func NewServer(t *testing.T) *Server {
+	t.Helper()
+
+	s := &Server{
+		tables:     map[string][]map[string]any{},
+		fkErrorIDs: map[string][]string{},
+	}
+	s.Server = httptest.NewServer(http.HandlerFunc(s.handle))
+	t.Cleanup(s.Close)
+	return s
+}
+
+// Config returns an UpstreamConfig pointing at this server.
+func (s *Server) Config(agentName string) upstream.UpstreamConfig {
+	return upstream.UpstreamConfig{Host: s.URL, AgentName: agentName}
+}
+
+// InjectFKError makes pushes of the given ids on the table fail the
+// foreign key check until cleared.
+func (s *Server) InjectFKError(table string, ids ...string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.fkErrorIDs[table] = append(s.fkErrorIDs[table], ids...)
+}
+
+// ClearFKErrors removes all injected FK failures.
+func (s *Server) ClearFKErrors() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.fkErrorIDs = map[string][]string{}
+}
+
+// Throttle responds 429 (with Retry-After) to the next n pushes.
+func (s *Server) Throttle(n int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.throttleNext = n
+}
+
+// FailNext responds 500 to the next n pushes.
+func (s *Server) FailNext(n int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.failNext = n
+}
+
+// Items returns a copy of everything pushed to a table.
+func (s *Server) Items(table string) []map[string]any {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	items := make([]map[string]any, len(s.tables[table]))
+	copy(items, s.tables[table])
+	return items
+}
+
+// PushCount returns how many push requests were received, including
+// failed ones.
+func (s *Server) PushCount() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.pushCount
+}
+
+func (s *Server) handle(w http.ResponseWriter, r *http.Request) {
+	if r.URL.Path == "/upstream/version" && r.Method == http.MethodGet {
+		_ = json.NewEncoder(w).Encode(upstream.Handshake{
+			MinVersion: upstream.ProtocolV1,
+			MaxVersion: upstream.CurrentProtocolVersion,
+		})
+		return
+	}
+
+	if r.URL.Path != "/upstream/push" || r.Method != http.MethodPost {
+		http.NotFound(w, r)
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.pushCount++
+
+	if s.failNext > 0 {
+		s.failNext--
+		http.Error(w, "injected failure", http.StatusInternalServerError)
+		return
+	}
+	if s.throttleNext > 0 {
+		s.throttleNext--
+		w.Header().Set("Retry-After", "1")
+		http.Error(w, "injected throttle", http.StatusTooManyRequests)
+		return
+	}
+
+	var data upstream.PushData
+	if err := json.NewDecoder(r.Body).Decode(&data); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	// partition the batch into accepted items and injected FK failures
+	failed := map[string]struct{}{}
+	for _, id := range s.fkErrorIDs[data.Table] {
+		failed[id] = struct{}{}
+	}
+
+	var failedIDs []string
+	for _, item := range data.Items {
+		id, _ := item["id"].(string)
+		if _, isFailed := failed[id]; isFailed {
+			failedIDs = append(failedIDs, id)
+			continue
+		}
+		s.tables[data.Table] = append(s.tables[data.Table], item)
+	}
+
+	if len(failedIDs) > 0 {
+		w.WriteHeader(http.StatusConflict)
+		_ = json.NewEncoder(w).Encode(upstream.PushFKError{IDs: failedIDs})
+		return
+	}
+	w.WriteHeader(http.StatusOK)
+}
@@ -0,0 +1,104 @@
+package upstream
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+)
+
+// Protocol versions of the push payload. Bump CurrentProtocolVersion
+// when PushData changes shape and add a shim in shimForVersion so newer
+// agents keep working against older upstreams during fleet upgrades.
+const (
+	// ProtocolV1 is the original payload: agent_name, table, items.
+	ProtocolV1 = 1
+
+	// ProtocolV2 adds the version field itself plus per-item source
+	// timestamps.
+	ProtocolV2 = 2
+
+	CurrentProtocolVersion = ProtocolV2
+)
+
+// Handshake is the upstream's answer to a version probe.
+type Handshake struct {
+	// MinVersion..MaxVersion is the inclusive range of protocol versions
+	// the upstream accepts.
+	MinVersion int `json:"min_version"`
+	MaxVersion int `json:"max_version"`
+}
+
+// negotiate probes the upstream's supported protocol range and picks the
+// highest version both sides speak. Upstreams that predate the handshake
+// endpoint (404) are assumed to speak only ProtocolV1.
+func (c *Client) negotiate() (int, error) {
+	c.negotiateOnce.Do(func() {
+		c.negotiatedVersion, c.negotiateErr = c.probeVersion()
+	})
+	return c.negotiatedVersion, c.negotiateErr
+}
+
+func (c *Client) probeVersion() (int, error) {
+	endpoint, err := url.JoinPath(c.config.Host, "upstream", "version")
+	if err != nil {
+		return 0, fmt.Errorf("invalid upstream host %s: %w", c.config.Host, err)
+	}
+
+	req, err := http.NewRequest(http.MethodGet, endpoint, nil)
+	if err != nil {
+		return 0, err
+	}
+	req.SetBasicAuth(c.config.Username, c.config.Password)
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return 0, fmt.Errorf("upstream version handshake failed: %w", err)
+	}
+	defer resp.Body.Close() //nolint:errcheck
+
+	if resp.StatusCode == http.StatusNotFound {
+		return ProtocolV1, nil
+	}
+	if resp.StatusCode != http.StatusOK {
+		return 0, fmt.Errorf("upstream version handshake returned %d", resp.StatusCode)
+	}
+
+	var handshake Handshake
+	if err := json.NewDecoder(resp.Body).Decode(&handshake); err != nil {
+		return 0, fmt.Errorf("malformed handshake response: %w", err)
+	}
+
+	if handshake.MinVersion > CurrentProtocolVersion {
+		return 0, fmt.Errorf("upstream requires protocol >= %d but this agent speaks <= %d; upgrade the agent",
+			handshake.MinVersion, CurrentProtocolVersion)
+	}
+	return min(handshake.MaxVersion, CurrentProtocolVersion), nil
+}
+
+// shimForVersion downgrades a current-version payload for an older
+// upstream. Shims are field-level: they strip or rename what the older
+// schema doesn't know about, never dropping whole items.
+func shimForVersion(data PushData, version int) PushData {
+	if version >= ProtocolV2 {
+		data.Version = CurrentProtocolVersion
+		return data
+	}
+
+	// V1 upstreams reject unknown fields: drop the version marker and
+	// the per-item source timestamps introduced in V2
+	data.Version = 0
+	items := make([]map[string]any, 0, len(data.Items))
+	for _, item := range data.Items {
+		copied := make(map[string]any, len(item))
+		for k, v := range item {
+			if k == "source_updated_at" {
+				continue
+			}
+			copied[k] = v
+		}
+		items = append(items, copied)
+	}
+	data.Items = items
+	return data
+}
@@ -0,0 +1,50 @@
+package upstream
+
+import "testing"
+
+func TestShimForVersion(t *testing.T) {
+	payload := func() PushData {
+		return PushData{
+			AgentName: "agent",
+			Table:     "config_items",
+			Items: []map[string]any{
+				{"id": "a", "name": "api", "source_updated_at": "2026-01-01T00:00:00Z"},
+				{"id": "b", "name": "db"},
+			},
+		}
+	}
+
+	t.Run("current version passes through with the version marker", func(t *testing.T) {
+		data := shimForVersion(payload(), ProtocolV2)
+		if data.Version != CurrentProtocolVersion {
+			t.Errorf("Version = %d, want %d", data.Version, CurrentProtocolVersion)
+		}
+		if _, ok := data.Items[0]["source_updated_at"]; !ok {
+			t.Error("V2 payload lost source_updated_at")
+		}
+	})
+
+	t.Run("v1 strips the version marker and v2 fields", func(t *testing.T) {
+		data := shimForVersion(payload(), ProtocolV1)
+		if data.Version != 0 {
+			t.Errorf("Version = %d, want 0", data.Version)
+		}
+		if len(data.Items) != 2 {
+			t.Fatalf("shim dropped items: got %d, want 2", len(data.Items))
+		}
+		if _, ok := data.Items[0]["source_updated_at"]; ok {
+			t.Error("source_updated_at not stripped for a V1 upstream")
+		}
+		if data.Items[0]["id"] != "a" || data.Items[0]["name"] != "api" {
+			t.Errorf("V1 shim mangled item fields: %v", data.Items[0])
+		}
+	})
+
+	t.Run("shim does not mutate the original items", func(t *testing.T) {
+		original := payload()
+		_ = shimForVersion(original, ProtocolV1)
+		if _, ok := original.Items[0]["source_updated_at"]; !ok {
+			t.Error("shim mutated the caller's items")
+		}
+	})
+}